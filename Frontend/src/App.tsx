@@ -28,7 +28,8 @@ function Router() {
   return (
     <Switch>
       <Route path="/" component={LandingPage} />
-      <Route path="/auth">
+      {/* Also /auth/oidc/<provider>, where login providers redirect back to */}
+      <Route path="/auth/*?">
         <PublicRoute>
           <AuthPage />
         </PublicRoute>
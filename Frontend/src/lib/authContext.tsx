@@ -8,9 +8,53 @@ import { useLocation } from 'wouter';
  * - Store JWT token in localStorage
  * - Auto-attach JWT to API requests
  * - Check token expiry
- * - Provide login/logout functions
+ * - Refresh the short-lived access token with the refresh token
+ * - Provide login/logout functions (logout revokes the session on the backend)
  */
 
+const API_URL = 'http://localhost:8080';
+
+// getStoredToken returns a token from whichever storage "remember me" chose
+function getStoredToken(key: string) {
+  return localStorage.getItem(key) || sessionStorage.getItem(key);
+}
+
+function clearStoredTokens() {
+  for (const key of ['authToken', 'refreshToken']) {
+    localStorage.removeItem(key);
+    sessionStorage.removeItem(key);
+  }
+}
+
+// refreshAccessToken exchanges the stored refresh token for a new token pair
+// Returns the new access token, or null when the session is over
+async function refreshAccessToken(): Promise<string | null> {
+  const refreshToken = getStoredToken('refreshToken');
+  if (!refreshToken) {
+    return null;
+  }
+
+  const storage = localStorage.getItem('refreshToken') ? localStorage : sessionStorage;
+  try {
+    const response = await fetch(`${API_URL}/api/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    });
+    if (!response.ok) {
+      return null;
+    }
+
+    const data = await response.json();
+    storage.setItem('authToken', data.data.token);
+    storage.setItem('refreshToken', data.data.refresh_token);
+    return data.data.token;
+  } catch (error) {
+    console.error('Token refresh failed:', error);
+    return null;
+  }
+}
+
 interface User {
   id: string;
   email: string;
@@ -21,7 +65,7 @@ interface User {
 interface AuthContextType {
   user: User | null;
   token: string | null;
-  login: (token: string, user: User, rememberMe?: boolean) => void;
+  login: (token: string, user: User, rememberMe?: boolean, refreshToken?: string) => void;
   logout: () => void;
   isAuthenticated: boolean;
   isLoading: boolean;
@@ -39,30 +83,40 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   useEffect(() => {
     const initAuth = async () => {
       // Check both localStorage and sessionStorage for token
-      const storedToken = localStorage.getItem('authToken') || sessionStorage.getItem('authToken');
+      let storedToken = getStoredToken('authToken');
       
       if (storedToken) {
         // Verify token with backend
         try {
-          const response = await fetch('http://localhost:8080/api/auth/me', {
+          let response = await fetch(`${API_URL}/api/auth/me`, {
             headers: {
               'Authorization': `Bearer ${storedToken}`,
             },
           });
 
-          if (response.ok) {
+          // Access token expired - try the refresh token once
+          if (response.status === 401) {
+            storedToken = await refreshAccessToken();
+            if (storedToken) {
+              response = await fetch(`${API_URL}/api/auth/me`, {
+                headers: {
+                  'Authorization': `Bearer ${storedToken}`,
+                },
+              });
+            }
+          }
+
+          if (storedToken && response.ok) {
             const data = await response.json();
             setToken(storedToken);
             setUser(data.data);
           } else {
-            // Token invalid or expired
-            localStorage.removeItem('authToken');
-            sessionStorage.removeItem('authToken');
+            // Session expired or revoked
+            clearStoredTokens();
           }
         } catch (error) {
           console.error('Token verification failed:', error);
-          localStorage.removeItem('authToken');
-          sessionStorage.removeItem('authToken');
+          clearStoredTokens();
         }
       }
       
@@ -72,15 +126,14 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     initAuth();
   }, []);
 
-  const login = (newToken: string, newUser: User, rememberMe: boolean = false) => {
-    if (rememberMe) {
-      // Store in localStorage (persists after browser close)
-      localStorage.setItem('authToken', newToken);
-      sessionStorage.removeItem('authToken');
-    } else {
-      // Store in sessionStorage (clears when browser closes)
-      sessionStorage.setItem('authToken', newToken);
-      localStorage.removeItem('authToken');
+  const login = (newToken: string, newUser: User, rememberMe: boolean = false, refreshToken?: string) => {
+    clearStoredTokens();
+
+    // localStorage persists after browser close, sessionStorage clears when it closes
+    const storage = rememberMe ? localStorage : sessionStorage;
+    storage.setItem('authToken', newToken);
+    if (refreshToken) {
+      storage.setItem('refreshToken', refreshToken);
     }
     
     setToken(newToken);
@@ -88,12 +141,21 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   };
 
   const logout = () => {
-    // Clear JWT token from both storages
-    localStorage.removeItem('authToken');
-    sessionStorage.removeItem('authToken');
+    // Revoke the session on the backend (best effort - the tokens are dropped either way)
+    const currentToken = getStoredToken('authToken');
+    if (currentToken) {
+      fetch(`${API_URL}/api/auth/logout`, {
+        method: 'POST',
+        headers: { 'Authorization': `Bearer ${currentToken}` },
+      }).catch((error) => console.error('Logout request failed:', error));
+    }
+
+    // Clear JWT access and refresh tokens from both storages
+    clearStoredTokens();
     
     // Also clear legacy userProfile to ensure full logout
     localStorage.removeItem('userProfile');
+    setActiveProject(null);
     
     setToken(null);
     setUser(null);
@@ -120,24 +182,45 @@ export function useAuth() {
   return context;
 }
 
+// Active project sent with every API request; none means the personal project
+export function getActiveProject(): string | null {
+  return localStorage.getItem('activeProjectId');
+}
+
+export function setActiveProject(projectId: string | null) {
+  if (projectId) {
+    localStorage.setItem('activeProjectId', projectId);
+  } else {
+    localStorage.removeItem('activeProjectId');
+  }
+}
+
 // Helper function to make authenticated API requests
+// An expired access token is refreshed once and the request retried
 export async function fetchWithAuth(url: string, options: RequestInit = {}) {
-  const token = localStorage.getItem('authToken');
-  
-  const headers = {
-    'Content-Type': 'application/json',
-    ...(token && { 'Authorization': `Bearer ${token}` }),
-    ...options.headers,
-  };
-
-  const response = await fetch(url, {
+  const projectId = getActiveProject();
+  const send = (token: string | null) => fetch(url, {
     ...options,
-    headers,
+    headers: {
+      'Content-Type': 'application/json',
+      ...(token && { 'Authorization': `Bearer ${token}` }),
+      ...(projectId && { 'X-Project-ID': projectId }),
+      ...options.headers,
+    },
   });
 
-  // If unauthorized, clear token and redirect to login
+  let response = await send(getStoredToken('authToken'));
+
+  if (response.status === 401) {
+    const newToken = await refreshAccessToken();
+    if (newToken) {
+      response = await send(newToken);
+    }
+  }
+
+  // If still unauthorized, clear tokens and redirect to login
   if (response.status === 401) {
-    localStorage.removeItem('authToken');
+    clearStoredTokens();
     window.location.href = '/auth';
   }
 
@@ -1,4 +1,4 @@
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useLocation, Link } from "wouter";
 import { Shield, Mail, Lock, Eye, EyeOff, X, ArrowRight, CheckCircle } from "lucide-react";
 import { Button } from "@/components/ui/button";
@@ -8,6 +8,16 @@ import { useUser } from "@/lib/userContext";
 import { useAuth } from "@/lib/authContext";
 import { GoogleLogin, CredentialResponse } from "@react-oauth/google";
 
+// Message for a 429 from a throttled login; Retry-After is in seconds
+function lockedOutMessage(response: Response): string {
+  const seconds = Number(response.headers.get("Retry-After"));
+  if (!seconds) {
+    return "Too many failed attempts. Please try again later.";
+  }
+  const wait = seconds < 60 ? `${seconds} seconds` : `${Math.ceil(seconds / 60)} minutes`;
+  return `Too many failed attempts. Please try again in ${wait}.`;
+}
+
 export default function AuthPage() {
   const [, setLocation] = useLocation();
   const { login } = useUser();
@@ -26,7 +36,7 @@ export default function AuthPage() {
 
   // Google OAuth password setup flow
   const [showGooglePasswordSetup, setShowGooglePasswordSetup] = useState(false);
-  const [googleUserData, setGoogleUserData] = useState<{name: string, email: string, role: string, token: string, id: string} | null>(null);
+  const [googleUserData, setGoogleUserData] = useState<{name: string, email: string, role: string, token: string, refreshToken?: string, id: string} | null>(null);
   const [googlePassword, setGooglePassword] = useState("");
   const [googleConfirmPassword, setGoogleConfirmPassword] = useState("");
   const [showGooglePassword, setShowGooglePassword] = useState(false);
@@ -42,6 +52,141 @@ export default function AuthPage() {
   const [acceptTerms, setAcceptTerms] = useState(false);
 
   const [resetEmail, setResetEmail] = useState("");
+  const [resetToken, setResetToken] = useState("");
+  const [resetNewPassword, setResetNewPassword] = useState("");
+  const [resetMessage, setResetMessage] = useState("");
+  const [authNotice, setAuthNotice] = useState("");
+
+  // Two-factor authentication step (after the password or Google check)
+  const [mfaToken, setMfaToken] = useState("");
+  const [mfaCode, setMfaCode] = useState("");
+  const [mfaRemember, setMfaRemember] = useState(false);
+  const [mfaError, setMfaError] = useState("");
+
+  // OpenID Connect providers other than Google (which has its own button)
+  const [oidcProviders, setOidcProviders] = useState<{name: string, display_name: string}[]>([]);
+
+  /**
+   * Finish a login response: store the JWT and redirect, or ask for the
+   * second factor if the user has two-factor authentication
+   */
+  const finishLogin = (data: any, remember: boolean) => {
+    if (data.success && data.data.mfa_required) {
+      setMfaToken(data.data.mfa_token);
+      setMfaRemember(remember);
+      return;
+    }
+    if (!data.success || !data.data.token) {
+      setLoginError(data.message || "Login failed. Please try again.");
+      return;
+    }
+
+    authLogin(data.data.token, {
+      id: data.data.user.id,
+      email: data.data.user.email,
+      username: data.data.user.username,
+      role: data.data.user.role,
+    }, remember, data.data.refresh_token);
+
+    // Also update legacy context (for compatibility)
+    login(data.data.user.username, data.data.user.email, data.data.user.role === "admin" ? "Admin" : "Tester");
+
+    // Redirect based on role
+    if (data.data.user.role === "admin") {
+      setLocation("/admin");
+    } else {
+      setLocation("/dashboard");
+    }
+  };
+
+  /**
+   * Load the OpenID Connect providers, and finish a provider login when the
+   * provider redirected back to /auth/oidc/<provider>?code=...&state=...
+   */
+  useEffect(() => {
+    fetch("http://localhost:8080/api/auth/oidc/providers")
+      .then((response) => response.json())
+      .then((data) => {
+        if (data.success && data.data) {
+          setOidcProviders(data.data.filter((provider: {name: string}) => provider.name !== "google"));
+        }
+      })
+      .catch(() => {});
+
+    const match = window.location.pathname.match(/^\/auth\/oidc\/([^/]+)$/);
+    const params = new URLSearchParams(window.location.search);
+    if (!match) {
+      return;
+    }
+    window.history.replaceState(null, "", "/auth");
+
+    if (params.get("error")) {
+      setLoginError(params.get("error_description") || "Login with the provider failed");
+      return;
+    }
+
+    fetch(`http://localhost:8080/api/auth/oidc/${match[1]}/callback`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ code: params.get("code"), state: params.get("state") }),
+    })
+      .then((response) => response.json())
+      .then((data) => finishLogin(data, true))
+      .catch(() => setLoginError("Failed to connect to server. Please try again."));
+  }, []);
+
+  // Send the user to an OpenID Connect provider
+  const handleOidcLogin = async (provider: string) => {
+    setLoginError("");
+    try {
+      const response = await fetch(`http://localhost:8080/api/auth/oidc/${provider}/authorize`);
+      const data = await response.json();
+      if (data.success) {
+        window.location.href = data.data.url;
+      } else {
+        setLoginError(data.message || "Login with the provider failed");
+      }
+    } catch (error) {
+      console.error("OIDC login error:", error);
+      setLoginError("Failed to connect to server. Please try again.");
+    }
+  };
+
+  /**
+   * Handle links from verification and password reset emails
+   * /auth?verify_token=... verifies the address right away;
+   * /auth?reset_token=... opens the reset form to choose a new password
+   */
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    const verifyToken = params.get("verify_token");
+    const linkResetToken = params.get("reset_token");
+    if (!verifyToken && !linkResetToken) {
+      return;
+    }
+    window.history.replaceState(null, "", window.location.pathname);
+
+    if (linkResetToken) {
+      setResetToken(linkResetToken);
+      setIsForgotPassword(true);
+      return;
+    }
+
+    fetch("http://localhost:8080/api/auth/verify-email", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ token: verifyToken }),
+    })
+      .then((response) => response.json())
+      .then((data) => {
+        if (data.success) {
+          setAuthNotice("Email verified - you can log in now.");
+        } else {
+          setLoginError(data.message || "Failed to verify email");
+        }
+      })
+      .catch(() => setLoginError("Failed to connect to server. Please try again."));
+  }, []);
 
   /**
    * Handle user login with JWT
@@ -73,7 +218,7 @@ export default function AuthPage() {
           email: data.data.user.email,
           username: data.data.user.username,
           role: data.data.user.role,
-        }, rememberMe);
+        }, rememberMe, data.data.refresh_token);
 
         // Also update legacy context (for compatibility)
         login(data.data.user.username, data.data.user.email, data.data.user.role === "admin" ? "Admin" : "Tester");
@@ -84,6 +229,12 @@ export default function AuthPage() {
         } else {
           setLocation("/dashboard");
         }
+      } else if (data.success && data.data.mfa_required) {
+        // Password was right; the second factor is asked for next
+        setMfaToken(data.data.mfa_token);
+        setMfaRemember(rememberMe);
+      } else if (response.status === 429) {
+        setLoginError(lockedOutMessage(response));
       } else {
         setLoginError(data.message || "Invalid email or password. Please try again.");
       }
@@ -175,9 +326,42 @@ export default function AuthPage() {
     }
   };
 
-  const handleResetPassword = (e: React.FormEvent) => {
+  /**
+   * Handle the reset form
+   * Without a token this emails a reset link; with the token from the link
+   * it sets the new password
+   */
+  const handleResetPassword = async (e: React.FormEvent) => {
     e.preventDefault();
-    setIsForgotPassword(false);
+    setResetMessage("");
+
+    try {
+      const response = resetToken
+        ? await fetch("http://localhost:8080/api/auth/reset-password", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ token: resetToken, password: resetNewPassword }),
+          })
+        : await fetch("http://localhost:8080/api/auth/forgot-password", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ email: resetEmail }),
+          });
+
+      const data = await response.json();
+
+      if (data.success && resetToken) {
+        setResetToken("");
+        setResetNewPassword("");
+        setIsForgotPassword(false);
+        setAuthNotice(data.message);
+      } else {
+        setResetMessage(data.message || "Failed to reset password");
+      }
+    } catch (error) {
+      console.error("Reset password error:", error);
+      setResetMessage("Failed to connect to server. Please try again.");
+    }
   };
 
   // Unified Google OAuth handler - works for both new and existing users
@@ -208,7 +392,7 @@ export default function AuthPage() {
           email: data.data.user.email,
           username: data.data.user.username,
           role: data.data.user.role,
-        }, true);  // Always remember for Google OAuth
+        }, true, data.data.refresh_token);  // Always remember for Google OAuth
 
         // Also update legacy context (for compatibility)
         login(
@@ -223,6 +407,9 @@ export default function AuthPage() {
         } else {
           setLocation("/dashboard");
         }
+      } else if (data.success && data.data.mfa_required) {
+        setMfaToken(data.data.mfa_token);
+        setMfaRemember(true);
       } else {
         const errorMsg = data.message || "Google authentication failed";
         setSignupError(errorMsg);
@@ -239,6 +426,41 @@ export default function AuthPage() {
     }
   };
 
+  /**
+   * Handle the two-factor step of a login
+   * Exchanges the MFA pending token and a TOTP or recovery code for the JWT
+   */
+  const handleMfaVerify = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setMfaError("");
+
+    try {
+      const response = await fetch("http://localhost:8080/api/auth/mfa/verify", {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({
+          mfa_token: mfaToken,
+          code: mfaCode,
+        }),
+      });
+
+      const data = await response.json();
+
+      if (data.success && data.data.token) {
+        finishLogin(data, mfaRemember);
+      } else if (response.status === 429) {
+        setMfaError(lockedOutMessage(response));
+      } else {
+        setMfaError(data.message || "Invalid authentication code");
+      }
+    } catch (error) {
+      console.error("MFA verify error:", error);
+      setMfaError("Failed to connect to server. Please try again.");
+    }
+  };
+
   const handleGoogleError = () => {
     if (isLogin) {
       setLoginError("Google login failed. Please try again.");
@@ -283,9 +505,9 @@ export default function AuthPage() {
           method: "POST",
           headers: {
             "Content-Type": "application/json",
+            "Authorization": `Bearer ${googleUserData.token}`,
           },
           body: JSON.stringify({
-            email: googleUserData.email,
             password: googlePassword,
           }),
         });
@@ -299,7 +521,7 @@ export default function AuthPage() {
             email: data.data.user.email,
             username: data.data.user.username,
             role: data.data.user.role,
-          });
+          }, false, data.data.refresh_token);
 
           // Also update legacy context (for compatibility)
           login(data.data.user.username, data.data.user.email, data.data.user.role === "admin" ? "Admin" : "Tester");
@@ -319,9 +541,9 @@ export default function AuthPage() {
           method: "POST",
           headers: {
             "Content-Type": "application/json",
+            "Authorization": `Bearer ${googleUserData.token}`,
           },
           body: JSON.stringify({
-            email: googleUserData.email,
             password: googlePassword,
           }),
         });
@@ -335,7 +557,7 @@ export default function AuthPage() {
             email: googleUserData.email,
             username: googleUserData.name,
             role: googleUserData.role,
-          });
+          }, false, googleUserData.refreshToken);
 
           // Also update legacy context (for compatibility)
           login(googleUserData.name, googleUserData.email, googleUserData.role === "admin" ? "Admin" : "Tester");
@@ -551,7 +773,57 @@ export default function AuthPage() {
 
         <div className="bg-white dark:bg-slate-900 rounded-2xl shadow-xl p-8">
           
-          {isForgotPassword ? (
+          {mfaToken ? (
+            <div className="space-y-6">
+              <div>
+                <h2 className="text-2xl font-bold text-slate-900 dark:text-white mb-2">Two-Factor Authentication</h2>
+                <p className="text-slate-600 dark:text-slate-400 text-sm">Enter the code from your authenticator app, or one of your recovery codes</p>
+              </div>
+
+              {mfaError && (
+                <div className="p-3 bg-red-50 dark:bg-red-500/10 border border-red-200 dark:border-red-500/20 rounded-lg">
+                  <p className="text-sm text-red-600 dark:text-red-400">{mfaError}</p>
+                </div>
+              )}
+
+              <form onSubmit={handleMfaVerify} className="space-y-4">
+                <div>
+                  <label className="block text-sm font-medium text-slate-900 dark:text-white mb-2">Authentication Code</label>
+                  <div className="relative">
+                    <Shield className="absolute left-3 top-3.5 w-5 h-5 text-slate-400 dark:text-slate-500" />
+                    <input
+                      type="text"
+                      inputMode="text"
+                      autoComplete="one-time-code"
+                      value={mfaCode}
+                      onChange={(e) => setMfaCode(e.target.value)}
+                      placeholder="123456"
+                      className="w-full pl-10 pr-4 py-2.5 border border-slate-200 dark:border-slate-700 rounded-lg focus:outline-none focus:border-blue-500 dark:focus:border-blue-500 focus:ring-1 focus:ring-blue-500 text-slate-900 dark:text-white placeholder:text-slate-400 dark:placeholder:text-slate-500 bg-white dark:bg-slate-800"
+                      required
+                    />
+                  </div>
+                </div>
+
+                <button
+                  type="submit"
+                  className="w-full py-2.5 bg-blue-600 hover:bg-blue-700 text-white font-semibold rounded-lg transition-colors flex items-center justify-center gap-2"
+                >
+                  VERIFY <ArrowRight className="w-4 h-4" />
+                </button>
+              </form>
+
+              <button
+                onClick={() => {
+                  setMfaToken("");
+                  setMfaCode("");
+                  setMfaError("");
+                }}
+                className="w-full text-center text-blue-600 dark:text-blue-400 hover:text-blue-700 dark:hover:text-blue-300 text-sm font-medium"
+              >
+                Back to Login
+              </button>
+            </div>
+          ) : isForgotPassword ? (
             <>
               <div className="flex gap-2 mb-8">
                 <button 
@@ -570,30 +842,56 @@ export default function AuthPage() {
               <div className="space-y-6">
                 <div>
                   <h2 className="text-2xl font-bold text-slate-900 dark:text-white mb-2">Reset Password</h2>
-                  <p className="text-slate-600 dark:text-slate-400 text-sm">Enter your email address and we'll send you a reset link</p>
+                  <p className="text-slate-600 dark:text-slate-400 text-sm">
+                    {resetToken ? "Choose a new password for your account" : "Enter your email address and we'll send you a reset link"}
+                  </p>
                 </div>
 
+                {resetMessage && (
+                  <div className="p-3 bg-blue-50 dark:bg-blue-500/10 border border-blue-200 dark:border-blue-500/20 rounded-lg">
+                    <p className="text-sm text-blue-700 dark:text-blue-300">{resetMessage}</p>
+                  </div>
+                )}
+
                 <form onSubmit={handleResetPassword} className="space-y-4">
-                  <div>
-                    <label className="block text-sm font-medium text-slate-900 dark:text-white mb-2">Email Address</label>
-                    <div className="relative">
-                      <Mail className="absolute left-3 top-3.5 w-5 h-5 text-slate-400 dark:text-slate-500" />
-                      <input
-                        type="email"
-                        value={resetEmail}
-                        onChange={(e) => setResetEmail(e.target.value)}
-                        placeholder="user@thex.com"
-                        className="w-full pl-10 pr-4 py-2.5 border border-slate-200 dark:border-slate-700 rounded-lg focus:outline-none focus:border-blue-500 dark:focus:border-blue-500 focus:ring-1 focus:ring-blue-500 text-slate-900 dark:text-white placeholder:text-slate-400 dark:placeholder:text-slate-500 bg-white dark:bg-slate-800"
-                        required
-                      />
+                  {resetToken ? (
+                    <div>
+                      <label className="block text-sm font-medium text-slate-900 dark:text-white mb-2">New Password</label>
+                      <div className="relative">
+                        <Lock className="absolute left-3 top-3.5 w-5 h-5 text-slate-400 dark:text-slate-500" />
+                        <input
+                          type="password"
+                          value={resetNewPassword}
+                          onChange={(e) => setResetNewPassword(e.target.value)}
+                          placeholder="At least 6 characters"
+                          minLength={6}
+                          className="w-full pl-10 pr-4 py-2.5 border border-slate-200 dark:border-slate-700 rounded-lg focus:outline-none focus:border-blue-500 dark:focus:border-blue-500 focus:ring-1 focus:ring-blue-500 text-slate-900 dark:text-white placeholder:text-slate-400 dark:placeholder:text-slate-500 bg-white dark:bg-slate-800"
+                          required
+                        />
+                      </div>
                     </div>
-                  </div>
+                  ) : (
+                    <div>
+                      <label className="block text-sm font-medium text-slate-900 dark:text-white mb-2">Email Address</label>
+                      <div className="relative">
+                        <Mail className="absolute left-3 top-3.5 w-5 h-5 text-slate-400 dark:text-slate-500" />
+                        <input
+                          type="email"
+                          value={resetEmail}
+                          onChange={(e) => setResetEmail(e.target.value)}
+                          placeholder="user@thex.com"
+                          className="w-full pl-10 pr-4 py-2.5 border border-slate-200 dark:border-slate-700 rounded-lg focus:outline-none focus:border-blue-500 dark:focus:border-blue-500 focus:ring-1 focus:ring-blue-500 text-slate-900 dark:text-white placeholder:text-slate-400 dark:placeholder:text-slate-500 bg-white dark:bg-slate-800"
+                          required
+                        />
+                      </div>
+                    </div>
+                  )}
 
                   <button
                     type="submit"
                     className="w-full py-2.5 bg-blue-600 hover:bg-blue-700 text-white font-semibold rounded-lg transition-colors flex items-center justify-center gap-2"
                   >
-                    SEND RESET LINK <ArrowRight className="w-4 h-4" />
+                    {resetToken ? "SET NEW PASSWORD" : "SEND RESET LINK"} <ArrowRight className="w-4 h-4" />
                   </button>
                 </form>
 
@@ -632,6 +930,12 @@ export default function AuthPage() {
 
               {isLogin ? (
                 <form onSubmit={handleLogin} className="space-y-4">
+                  {authNotice && (
+                    <div className="p-3 bg-green-50 dark:bg-green-500/10 border border-green-200 dark:border-green-500/20 rounded-lg">
+                      <p className="text-sm text-green-700 dark:text-green-400">{authNotice}</p>
+                    </div>
+                  )}
+
                   {loginError && (
                     <div className="p-3 bg-red-50 dark:bg-red-500/10 border border-red-200 dark:border-red-500/20 rounded-lg">
                       <p className="text-sm text-red-600 dark:text-red-400">{loginError}</p>
@@ -851,6 +1155,17 @@ export default function AuthPage() {
                   text="continue_with"
                 />
               </div>
+
+              {oidcProviders.map((provider) => (
+                <button
+                  key={provider.name}
+                  type="button"
+                  onClick={() => handleOidcLogin(provider.name)}
+                  className="w-full mt-3 py-2.5 border border-slate-200 dark:border-slate-700 rounded-lg text-sm font-medium text-slate-700 dark:text-slate-300 hover:bg-slate-50 dark:hover:bg-slate-800 transition-colors"
+                >
+                  Continue with {provider.display_name}
+                </button>
+              ))}
             </>
           )}
         </div>
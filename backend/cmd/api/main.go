@@ -4,10 +4,11 @@ package main
  * Backend API Entry Point
  * 
  * Purpose: Main entry point for the TestOps backend API
- * This handles all HTTP requests and routes them to appropriate handlers
+ * Loads the configuration, connects to MongoDB and the other backing
+ * services, wires repositories, services, handlers and middleware together
+ * and registers every HTTP route under /api
  * 
- * For now: Only user signup functionality is implemented
- * No JWT authentication yet - keeping it simple for basic database connection
+ * Routes are listed in the startup log; see README.md for the endpoints
  */
 
 import (
@@ -16,6 +17,7 @@ import (
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata" // schedule timezones must resolve in minimal containers
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -23,15 +25,26 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/handlers"
+	"backend/internal/mail"
 	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"backend/internal/queue"
 	"backend/internal/repository"
 	"backend/internal/services"
+	"backend/internal/storage"
+	"backend/internal/utils"
 )
 
 func main() {
 	// ==================================================
 	// ENVIRONMENT CONFIGURATION
 	// ==================================================
+	cfg := utils.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -45,6 +58,7 @@ func main() {
 	log.Println("=== Starting TestOps Backend API ===")
 	log.Printf("Port: %s", port)
 	log.Printf("MongoDB URL: %s", mongoURL)
+	log.Printf("Queue backend: %s", cfg.QueueBackend)
 
 	// ==================================================
 	// DATABASE CONNECTION
@@ -71,28 +85,163 @@ func main() {
 	// Get database instance
 	database := client.Database("testops")
 
+	// ==================================================
+	// JOB QUEUE CONNECTION
+	// ==================================================
+	log.Printf("Opening %s job queues...", cfg.QueueBackend)
+
+	// One queue per project, opened on first use
+	jobQueues, err := queue.OpenPool(ctx, cfg, database)
+	if err != nil {
+		log.Fatal("Failed to open job queues:", err)
+	}
+	defer jobQueues.Close(context.Background())
+
+	log.Printf("✓ Job queues ready (%s)", cfg.QueueBackend)
+
+	// ==================================================
+	// ARTIFACT STORE
+	// ==================================================
+	artifactStore, err := storage.OpenArtifactStore(ctx, cfg)
+	if err != nil {
+		log.Fatal("Failed to open artifact store:", err)
+	}
+
+	log.Printf("✓ Artifact store ready (%s)", cfg.ArtifactStore)
+
+	// ==================================================
+	// JWT SIGNING KEYS
+	// ==================================================
+	jwtKeys, err := services.LoadKeySet(cfg)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+
+	log.Printf("✓ JWT signing keys loaded (%s)", cfg.JWTAlgorithm)
+
+	// ==================================================
+	// MAILER
+	// ==================================================
+	mailer, err := mail.Open(cfg)
+	if err != nil {
+		log.Fatal("Failed to open mailer:", err)
+	}
+
+	log.Printf("✓ Mailer ready (%s)", cfg.Mailer)
+
+	// ==================================================
+	// OPENID CONNECT PROVIDERS
+	// ==================================================
+	oidcProviders, err := oidc.Open(cfg)
+	if err != nil {
+		log.Fatal("Failed to configure OIDC providers:", err)
+	}
+
+	log.Printf("✓ OIDC providers configured (%d)", len(oidcProviders.List()))
+
 	// ==================================================
 	// INITIALIZE LAYERS (Repository -> Service -> Handler -> Middleware)
 	// ==================================================
 	
 	// Repository Layer - Direct database operations
 	userRepo := repository.NewUserRepository(database)
+	testRepo := repository.NewTestRepository(database)
+	runRepo := repository.NewTestRunRepository(database)
+	workerRepo := repository.NewWorkerRepository(database)
+	resultRepo := repository.NewResultRepository(database)
+	runLogRepo := repository.NewRunLogRepository(database)
+	suiteRepo := repository.NewSuiteRepository(database)
+	suiteRunRepo := repository.NewSuiteRunRepository(database)
+	scheduleRepo := repository.NewScheduleRepository(database)
+	tokenRepo := repository.NewTokenRepository(database)
+	sessionRepo := repository.NewSessionRepository(database)
+	patRepo := repository.NewPersonalAccessTokenRepository(database)
+	orgRepo := repository.NewOrganizationRepository(database)
+	projectRepo := repository.NewProjectRepository(database)
+	emailTokenRepo := repository.NewEmailTokenRepository(database)
+	oidcLoginRepo := repository.NewOIDCLoginRepository(database)
+	loginFailureRepo := repository.NewLoginFailureRepository(database)
+	auditRepo := repository.NewAuditEventRepository(database)
+	workerRegTokenRepo := repository.NewWorkerRegistrationTokenRepository(database)
 	
 	// Service Layer - Business logic
-	userService := services.NewUserService(userRepo)
-	jwtService := services.NewJWTService()
+	auditService := services.NewAuditService(auditRepo)
+	userService := services.NewUserService(userRepo, auditService)
+	jwtService := services.NewJWTService(jwtKeys, cfg.AccessTokenTTL)
+	tokenService := services.NewTokenService(tokenRepo, sessionRepo, userRepo, jwtService, cfg.RefreshTokenTTL, cfg.RoleCacheTTL)
+	accountService := services.NewAccountService(userRepo, emailTokenRepo, tokenService, mailer, auditService, services.AccountConfig{
+		BaseURL:         cfg.AppBaseURL,
+		Secret:          cfg.EmailTokenSecret,
+		VerificationTTL: cfg.EmailVerificationTTL,
+		ResetTTL:        cfg.PasswordResetTTL,
+	})
+	mfaService, err := services.NewMFAService(userRepo, tokenService, jwtService, services.MFAConfig{
+		Issuer:        cfg.MFAIssuer,
+		EncryptionKey: cfg.MFAEncryptionKey,
+		PendingTTL:    cfg.MFAPendingTTL,
+	})
+	if err != nil {
+		log.Fatal("Failed to set up two-factor authentication:", err)
+	}
+	loginThrottle := services.NewLoginThrottleService(loginFailureRepo, userRepo, services.LoginThrottleConfig{
+		MaxFailures:   cfg.LoginMaxFailures,
+		IPMaxFailures: cfg.LoginIPMaxFailures,
+		Backoff:       cfg.LoginBackoff,
+		Lockout:       cfg.LoginLockout,
+		Window:        cfg.LoginFailureWindow,
+	})
+	oidcService := services.NewOIDCService(oidcProviders, oidcLoginRepo, userRepo, userService, mfaService, auditService)
+	roleService := services.NewRoleService(userRepo, auditService, cfg.RoleCacheTTL)
+	projectService := services.NewProjectService(projectRepo, orgRepo, userRepo, cfg.RoleCacheTTL)
+	userAdminService := services.NewUserAdminService(userRepo, patRepo, tokenService, accountService, projectService, auditService, cfg.RoleCacheTTL)
+	patService := services.NewPersonalAccessTokenService(patRepo, userRepo, roleService, projectService)
+	sessionService := services.NewSessionService(tokenService, patService, auditService)
+	testService := services.NewTestService(testRepo, auditService)
+	workerService := services.NewWorkerService(jobQueues, workerRepo, runRepo)
+	workerAuthService := services.NewWorkerAuthService(workerRegTokenRepo, workerRepo, projectService, userAdminService, auditService)
+	runService := services.NewRunService(runRepo, testService, workerService)
+	suiteService := services.NewSuiteService(suiteRepo, suiteRunRepo, runRepo, testService, runService)
+	scheduleService := services.NewScheduleService(scheduleRepo, testService, runService, suiteService, projectService, userAdminService)
+	logService := services.NewLogService(runLogRepo, runRepo, runService)
+	resultService := services.NewResultService(resultRepo, runService, artifactStore, cfg.ArtifactURLTTL)
+
+	// Background jobs
+	workerReaper := services.NewWorkerReaper(workerRepo, runService, cfg.WorkerOfflineAfter)
+	workerReaper.Start(context.Background())
+	scheduler := services.NewScheduler(scheduleService, cfg.SchedulerInterval)
+	scheduler.Start(context.Background())
 	
 	// Middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, tokenService, patService, userAdminService)
+	permissions := middleware.NewPermissionMiddleware(roleService, projectService)
+	workerAuth := middleware.NewWorkerAuthMiddleware(workerAuthService)
+	clientIP := middleware.NewClientIPMiddleware(cfg.TrustProxyHeaders)
 	
 	// Handler Layer - HTTP request handling
-	userHandler := handlers.NewUserHandler(userService, jwtService)
-	googleAuthHandler := handlers.NewGoogleAuthHandler(userService, jwtService)
+	userHandler := handlers.NewUserHandler(userService, tokenService, accountService, mfaService, loginThrottle, auditService, cfg.RequireEmailVerification)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, mfaService, auditService)
+	mfaHandler := handlers.NewMFAHandler(mfaService, loginThrottle, auditService)
+	testsHandler := handlers.NewTestsHandler(testService)
+	runsHandler := handlers.NewRunsHandler(runService)
+	workersHandler := handlers.NewWorkersHandler(workerService, runService, workerAuthService)
+	resultsHandler := handlers.NewResultsHandler(resultService, cfg.MaxUploadSize)
+	logsHandler := handlers.NewLogsHandler(logService)
+	suitesHandler := handlers.NewSuitesHandler(suiteService)
+	schedulesHandler := handlers.NewSchedulesHandler(scheduleService)
+	rolesHandler := handlers.NewRolesHandler(roleService)
+	patHandler := handlers.NewPersonalAccessTokensHandler(patService, auditService)
+	projectsHandler := handlers.NewProjectsHandler(projectService)
+	lockoutsHandler := handlers.NewLockoutsHandler(loginThrottle, auditService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	usersHandler := handlers.NewUsersHandler(userAdminService)
+	sessionsHandler := handlers.NewSessionsHandler(sessionService)
+	identitiesHandler := handlers.NewIdentitiesHandler(oidcService)
 
 	// ==================================================
 	// ROUTER SETUP
 	// ==================================================
 	router := mux.NewRouter()
+	router.Use(clientIP.Handler)
 
 	// Health check endpoint
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -105,14 +254,143 @@ func main() {
 	
 	// Public routes (no authentication required)
 	api.HandleFunc("/users/signup", userHandler.Signup).Methods("POST", "OPTIONS")
-	api.HandleFunc("/users/set-password", userHandler.SetPassword).Methods("POST", "OPTIONS")
 	api.HandleFunc("/auth/login", userHandler.Login).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/refresh", userHandler.Refresh).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/verify-email", userHandler.VerifyEmail).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/forgot-password", userHandler.ForgotPassword).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/reset-password", userHandler.ResetPassword).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/mfa/verify", mfaHandler.Verify).Methods("POST", "OPTIONS")
 	
-	// Unified Google OAuth route - handles both signup and login automatically
-	api.HandleFunc("/auth/google", googleAuthHandler.GoogleAuth).Methods("POST", "OPTIONS")
+	// OpenID Connect providers - first logins create the user
+	api.HandleFunc("/auth/oidc/providers", oidcHandler.GetProviders).Methods("GET")
+	api.HandleFunc("/auth/oidc/{provider}/token", oidcHandler.IDTokenLogin).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/oidc/{provider}/authorize", oidcHandler.Authorize).Methods("GET")
+	api.HandleFunc("/auth/oidc/{provider}/callback", oidcHandler.Callback).Methods("POST", "OPTIONS")
+	api.HandleFunc("/auth/google", oidcHandler.GoogleAuth).Methods("POST", "OPTIONS")
 	
 	// Protected routes (authentication required)
 	api.HandleFunc("/auth/me", authMiddleware.Authenticate(userHandler.GetCurrentUser)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/auth/logout", authMiddleware.Authenticate(userHandler.Logout)).Methods("POST")
+	api.HandleFunc("/auth/resend-verification", authMiddleware.Authenticate(userHandler.ResendVerification)).Methods("POST")
+	api.HandleFunc("/users/set-password", authMiddleware.Authenticate(userHandler.SetPassword)).Methods("POST")
+
+	// Two-factor authentication (TOTP)
+	api.HandleFunc("/auth/mfa/totp/enroll", authMiddleware.Authenticate(mfaHandler.Enroll)).Methods("POST")
+	api.HandleFunc("/auth/mfa/totp/confirm", authMiddleware.Authenticate(mfaHandler.Confirm)).Methods("POST")
+	api.HandleFunc("/auth/mfa/totp/disable", authMiddleware.Authenticate(mfaHandler.Disable)).Methods("POST")
+	api.HandleFunc("/auth/mfa/recovery-codes", authMiddleware.Authenticate(mfaHandler.RegenerateRecoveryCodes)).Methods("POST")
+
+	// Personal access tokens - for CI pipelines; accepted wherever a JWT is
+	api.HandleFunc("/tokens", authMiddleware.Authenticate(patHandler.GetTokens)).Methods("GET")
+	api.HandleFunc("/tokens", authMiddleware.Authenticate(patHandler.CreateToken)).Methods("POST")
+	api.HandleFunc("/tokens/{id}", authMiddleware.Authenticate(patHandler.RevokeToken)).Methods("DELETE")
+
+	// Sessions - where the caller is logged in, including personal access tokens
+	api.HandleFunc("/sessions", authMiddleware.Authenticate(sessionsHandler.GetSessions)).Methods("GET")
+	api.HandleFunc("/sessions/{id}", authMiddleware.Authenticate(sessionsHandler.RevokeSession)).Methods("DELETE")
+
+	// Linked identities - login provider accounts of the caller
+	api.HandleFunc("/identities", authMiddleware.Authenticate(identitiesHandler.GetIdentities)).Methods("GET")
+	api.HandleFunc("/identities/{provider}/token", authMiddleware.Authenticate(identitiesHandler.LinkWithIDToken)).Methods("POST")
+	api.HandleFunc("/identities/{provider}/authorize", authMiddleware.Authenticate(identitiesHandler.Authorize)).Methods("GET")
+	api.HandleFunc("/identities/{provider}/callback", authMiddleware.Authenticate(identitiesHandler.Callback)).Methods("POST")
+	api.HandleFunc("/identities/{provider}", authMiddleware.Authenticate(identitiesHandler.Unlink)).Methods("DELETE")
+
+	// Roles - users:admin is checked against the platform role, every other
+	// permission against the caller's role in the project named by X-Project-ID
+	api.HandleFunc("/roles", authMiddleware.Authenticate(rolesHandler.GetRoles)).Methods("GET")
+	api.HandleFunc("/users/{id}/role", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, rolesHandler.SetUserRole))).Methods("PUT")
+
+	// User administration - disabled users are rejected by the auth middleware (users:admin)
+	api.HandleFunc("/users", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.GetUsers))).Methods("GET")
+	api.HandleFunc("/users/{id}", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.GetUser))).Methods("GET")
+	api.HandleFunc("/users/{id}", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.DeleteUser))).Methods("DELETE")
+	api.HandleFunc("/users/{id}/disable", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.DisableUser))).Methods("POST")
+	api.HandleFunc("/users/{id}/enable", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.EnableUser))).Methods("POST")
+	api.HandleFunc("/users/{id}/reset-password", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, usersHandler.ForcePasswordReset))).Methods("POST")
+
+	// Lockouts - too many failed logins lock the account for a while (users:admin)
+	api.HandleFunc("/users/{id}/lockout", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, lockoutsHandler.GetLockout))).Methods("GET")
+	api.HandleFunc("/users/{id}/lockout", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, lockoutsHandler.Unlock))).Methods("DELETE")
+
+	// Audit log - security-relevant and destructive actions (users:admin)
+	api.HandleFunc("/audit-events", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, auditHandler.GetEvents))).Methods("GET")
+	api.HandleFunc("/audit-events/export", authMiddleware.Authenticate(permissions.Require(models.PermUsersAdmin, auditHandler.ExportEvents))).Methods("GET")
+
+	// Organizations and projects - organization changes are checked by the service (owners only)
+	api.HandleFunc("/organizations", authMiddleware.Authenticate(projectsHandler.GetOrganizations)).Methods("GET")
+	api.HandleFunc("/organizations", authMiddleware.Authenticate(projectsHandler.CreateOrganization)).Methods("POST")
+	api.HandleFunc("/organizations/{id}/members", authMiddleware.Authenticate(projectsHandler.GetOrganizationMembers)).Methods("GET")
+	api.HandleFunc("/organizations/{id}/members", authMiddleware.Authenticate(projectsHandler.SetOrganizationMember)).Methods("PUT")
+	api.HandleFunc("/organizations/{id}/members/{userId}", authMiddleware.Authenticate(projectsHandler.RemoveOrganizationMember)).Methods("DELETE")
+	api.HandleFunc("/organizations/{id}/projects", authMiddleware.Authenticate(projectsHandler.CreateProject)).Methods("POST")
+	api.HandleFunc("/projects", authMiddleware.Authenticate(projectsHandler.GetProjects)).Methods("GET")
+	api.HandleFunc("/project", authMiddleware.Authenticate(permissions.ResolveProject(projectsHandler.GetActiveProject))).Methods("GET")
+	api.HandleFunc("/project/members", authMiddleware.Authenticate(permissions.ResolveProject(projectsHandler.GetProjectMembers))).Methods("GET")
+	api.HandleFunc("/project/members", authMiddleware.Authenticate(permissions.Require(models.PermMembersManage, projectsHandler.SetProjectMember))).Methods("PUT")
+	api.HandleFunc("/project/members/{userId}", authMiddleware.Authenticate(permissions.Require(models.PermMembersManage, projectsHandler.RemoveProjectMember))).Methods("DELETE")
+
+	// Test scripts - always scoped to the active project
+	api.HandleFunc("/tests", authMiddleware.Authenticate(permissions.Require(models.PermTestsRead, testsHandler.GetTests))).Methods("GET")
+	api.HandleFunc("/tests", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, testsHandler.CreateTest))).Methods("POST")
+	api.HandleFunc("/tests/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsRead, testsHandler.GetTestByID))).Methods("GET")
+	api.HandleFunc("/tests/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, testsHandler.UpdateTest))).Methods("PUT")
+	api.HandleFunc("/tests/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, testsHandler.DeleteTest))).Methods("DELETE")
+
+	// Test runs - each execution of a test is a separate run
+	api.HandleFunc("/tests/{id}/runs", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, runsHandler.GetRuns))).Methods("GET")
+	api.HandleFunc("/tests/{id}/runs", authMiddleware.Authenticate(permissions.Require(models.PermRunsCreate, runsHandler.CreateRun))).Methods("POST")
+	api.HandleFunc("/runs/{id}", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, runsHandler.GetRunByID))).Methods("GET")
+	api.HandleFunc("/runs/{id}/cancel", authMiddleware.Authenticate(permissions.Require(models.PermRunsCancel, runsHandler.CancelRun))).Methods("POST")
+
+	// Suites - ordered groups of tests, run together as a suite run
+	api.HandleFunc("/suites", authMiddleware.Authenticate(permissions.Require(models.PermTestsRead, suitesHandler.GetSuites))).Methods("GET")
+	api.HandleFunc("/suites", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, suitesHandler.CreateSuite))).Methods("POST")
+	api.HandleFunc("/suites/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsRead, suitesHandler.GetSuiteByID))).Methods("GET")
+	api.HandleFunc("/suites/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, suitesHandler.UpdateSuite))).Methods("PUT")
+	api.HandleFunc("/suites/{id}", authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, suitesHandler.DeleteSuite))).Methods("DELETE")
+	api.HandleFunc("/suites/{id}/runs", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, suitesHandler.GetSuiteRuns))).Methods("GET")
+	api.HandleFunc("/suites/{id}/runs", authMiddleware.Authenticate(permissions.Require(models.PermRunsCreate, suitesHandler.RunSuite))).Methods("POST")
+	api.HandleFunc("/suite-runs/{id}", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, suitesHandler.GetSuiteRun))).Methods("GET")
+	api.HandleFunc("/suite-runs/{id}/cancel", authMiddleware.Authenticate(permissions.Require(models.PermRunsCancel, suitesHandler.CancelSuiteRun))).Methods("POST")
+
+	// Schedules - runs of a test or suite started on a cron schedule
+	api.HandleFunc("/schedules", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, schedulesHandler.GetSchedules))).Methods("GET")
+	api.HandleFunc("/schedules", authMiddleware.Authenticate(permissions.Require(models.PermSchedulesWrite, schedulesHandler.CreateSchedule))).Methods("POST")
+	api.HandleFunc("/schedules/{id}", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, schedulesHandler.GetScheduleByID))).Methods("GET")
+	api.HandleFunc("/schedules/{id}", authMiddleware.Authenticate(permissions.Require(models.PermSchedulesWrite, schedulesHandler.UpdateSchedule))).Methods("PUT")
+	api.HandleFunc("/schedules/{id}", authMiddleware.Authenticate(permissions.Require(models.PermSchedulesWrite, schedulesHandler.DeleteSchedule))).Methods("DELETE")
+
+	// Results - uploaded by the runner executing the run
+	api.HandleFunc("/runs/{id}/results", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, resultsHandler.GetResults))).Methods("GET")
+	api.HandleFunc("/runs/{id}/results", workerAuth.Authenticate(resultsHandler.UploadResult)).Methods("POST")
+	api.HandleFunc("/results/{id}", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, resultsHandler.GetResultByID))).Methods("GET")
+
+	// Run logs - appended by the runner, followed live over Server-Sent Events
+	api.HandleFunc("/runs/{id}/logs", authMiddleware.Authenticate(permissions.Require(models.PermRunsRead, logsHandler.GetLogs))).Methods("GET")
+	api.HandleFunc("/runs/{id}/logs", workerAuth.Authenticate(logsHandler.AppendLogs)).Methods("POST")
+	api.HandleFunc("/runs/{id}/logs/stream", authMiddleware.AuthenticateStream(permissions.Require(models.PermRunsRead, logsHandler.StreamLogs))).Methods("GET")
+
+	// Artifact downloads (public - presigned links, local store only)
+	if localStore, ok := artifactStore.(*storage.LocalStore); ok {
+		artifactsHandler := handlers.NewArtifactsHandler(localStore)
+		api.HandleFunc("/artifacts/{key:.+}", artifactsHandler.Download).Methods("GET")
+	}
+
+	// Workers - registration tokens and credentials for users (before /workers/{id})
+	api.HandleFunc("/workers", authMiddleware.Authenticate(permissions.Require(models.PermWorkersRead, workersHandler.GetWorkers))).Methods("GET")
+	api.HandleFunc("/workers/registration-tokens", authMiddleware.Authenticate(permissions.Require(models.PermWorkersManage, workersHandler.GetRegistrationTokens))).Methods("GET")
+	api.HandleFunc("/workers/registration-tokens", authMiddleware.Authenticate(permissions.Require(models.PermWorkersManage, workersHandler.CreateRegistrationToken))).Methods("POST")
+	api.HandleFunc("/workers/registration-tokens/{id}", authMiddleware.Authenticate(permissions.Require(models.PermWorkersManage, workersHandler.RevokeRegistrationToken))).Methods("DELETE")
+	api.HandleFunc("/workers/{id}", authMiddleware.Authenticate(permissions.Require(models.PermWorkersRead, workersHandler.GetWorkerStatus))).Methods("GET")
+	api.HandleFunc("/workers/{id}/credential", authMiddleware.Authenticate(permissions.Require(models.PermWorkersManage, workersHandler.RevokeCredential))).Methods("DELETE")
+
+	// Workers - registration, heartbeats and job leasing for the runners (worker credentials only)
+	api.HandleFunc("/workers/register", workersHandler.Register).Methods("POST")
+	api.HandleFunc("/workers/{id}/heartbeat", workerAuth.Authenticate(workersHandler.Heartbeat)).Methods("POST")
+	api.HandleFunc("/workers/{id}/status", workerAuth.Authenticate(workersHandler.UpdateStatus)).Methods("PUT")
+	api.HandleFunc("/workers/{id}/jobs/lease", workerAuth.Authenticate(workersHandler.LeaseJob)).Methods("POST")
+	api.HandleFunc("/workers/{id}/jobs/{jobId}/nack", workerAuth.Authenticate(workersHandler.NackJob)).Methods("POST")
 
 	// ==================================================
 	// CORS CONFIGURATION
@@ -121,7 +399,8 @@ func main() {
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:3000", "http://localhost:3456", "http://localhost:3457"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Project-ID", "Last-Event-ID"},
+		ExposedHeaders:   []string{"Retry-After"},
 		AllowCredentials: true,
 	})
 
@@ -135,9 +414,50 @@ func main() {
 	log.Println("  GET  /health")
 	log.Println("  POST /api/users/signup (returns JWT)")
 	log.Println("  POST /api/auth/login (returns JWT)")
+	log.Println("  POST /api/auth/refresh (rotates the refresh token)")
 	log.Println("  POST /api/auth/google (unified - auto-detects new/existing user)")
-	log.Println("  POST /api/users/set-password")
+	log.Println("  GET  /api/auth/oidc/providers, POST /api/auth/oidc/{provider}/token")
+	log.Println("  GET  /api/auth/oidc/{provider}/authorize, POST /api/auth/oidc/{provider}/callback")
+	log.Println("  POST /api/auth/verify-email, POST /api/auth/forgot-password, POST /api/auth/reset-password")
 	log.Println("  GET  /api/auth/me (protected)")
+	log.Println("  POST /api/auth/logout (protected)")
+	log.Println("  POST /api/auth/resend-verification, POST /api/users/set-password (protected)")
+	log.Println("  POST /api/auth/mfa/verify (second login step with a TOTP or recovery code)")
+	log.Println("  POST /api/auth/mfa/totp/{enroll,confirm,disable}, POST /api/auth/mfa/recovery-codes (protected)")
+	log.Println("  GET|POST /api/tokens, DELETE /api/tokens/{id} (protected, personal access tokens)")
+	log.Println("  GET  /api/sessions, DELETE /api/sessions/{id} (protected)")
+	log.Println("  GET  /api/identities, POST /api/identities/{provider}/{token,callback} (protected)")
+	log.Println("  GET  /api/identities/{provider}/authorize, DELETE /api/identities/{provider} (protected)")
+	log.Println("  GET  /api/roles (protected), PUT /api/users/{id}/role (users:admin)")
+	log.Println("  GET  /api/users, GET|DELETE /api/users/{id} (users:admin)")
+	log.Println("  POST /api/users/{id}/{disable,enable,reset-password} (users:admin)")
+	log.Println("  GET|DELETE /api/users/{id}/lockout (users:admin)")
+	log.Println("  GET  /api/audit-events, GET /api/audit-events/export (users:admin, JSON Lines)")
+	log.Println("  GET|POST /api/organizations, GET|PUT /api/organizations/{id}/members (protected)")
+	log.Println("  DELETE /api/organizations/{id}/members/{userId}, POST /api/organizations/{id}/projects (protected)")
+	log.Println("  GET  /api/projects, GET /api/project (protected)")
+	log.Println("  GET|PUT /api/project/members, DELETE /api/project/members/{userId} (protected)")
+	log.Println("  GET|POST /api/tests (protected)")
+	log.Println("  GET|PUT|DELETE /api/tests/{id} (protected)")
+	log.Println("  GET|POST /api/tests/{id}/runs (protected)")
+	log.Println("  GET  /api/runs/{id} (protected)")
+	log.Println("  POST /api/runs/{id}/cancel (protected)")
+	log.Println("  GET|POST /api/suites, GET|PUT|DELETE /api/suites/{id} (protected)")
+	log.Println("  GET|POST /api/suites/{id}/runs (protected)")
+	log.Println("  GET  /api/suite-runs/{id}, POST /api/suite-runs/{id}/cancel (protected)")
+	log.Println("  GET|POST /api/schedules, GET|PUT|DELETE /api/schedules/{id} (protected)")
+	log.Println("  GET|POST /api/runs/{id}/results (protected, POST is multipart and takes a worker credential)")
+	log.Println("  GET  /api/results/{id} (protected)")
+	log.Println("  GET|POST /api/runs/{id}/logs (protected, POST takes a worker credential)")
+	log.Println("  GET  /api/runs/{id}/logs/stream (protected, Server-Sent Events; access_token query accepted)")
+	log.Println("  GET  /api/artifacts/{key} (presigned link, local artifact store only)")
+	log.Println("  GET  /api/workers, GET /api/workers/{id} (protected)")
+	log.Println("  GET|POST /api/workers/registration-tokens, DELETE /api/workers/registration-tokens/{id} (protected)")
+	log.Println("  DELETE /api/workers/{id}/credential (protected)")
+	log.Println("  POST /api/workers/register (registration token)")
+	log.Println("  POST /api/workers/{id}/heartbeat, PUT /api/workers/{id}/status (worker credential)")
+	log.Println("  POST /api/workers/{id}/jobs/lease (worker credential)")
+	log.Println("  POST /api/workers/{id}/jobs/{jobId}/nack (worker credential)")
 	
 	if err := http.ListenAndServe(":"+port, handler); err != nil {
 		log.Fatal("Server failed to start:", err)
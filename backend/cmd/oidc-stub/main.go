@@ -0,0 +1,53 @@
+package main
+
+/**
+ * OIDC Stub
+ *
+ * Purpose: A local stand-in OpenID Connect provider for developing and
+ * testing OIDC logins without Google, GitLab or Keycloak
+ *
+ * It serves an oidctest.Provider: discovery, a JWKS, an authorization
+ * endpoint that signs in a fixed user without asking, and a token endpoint
+ * that checks PKCE. ID tokens are signed with an RSA key generated at startup.
+ *
+ * Usage:
+ *   go run ./cmd/oidc-stub -addr :9000 -email dev@example.com
+ *
+ *   OIDC_PROVIDERS=stub
+ *   OIDC_STUB_ISSUER=http://localhost:9000
+ *   OIDC_STUB_CLIENT_ID=testops
+ *
+ * GET /id-token mints an ID token directly, for the ID token flow:
+ *   curl -s localhost:9000/id-token | jq -r .id_token
+ *
+ * For development only: anyone who can reach it can log in as the user.
+ */
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"backend/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL, as the backend reaches it")
+	clientID := flag.String("client-id", "testops", "client ID tokens are issued for")
+	email := flag.String("email", "dev@example.com", "email of the signed-in user")
+	name := flag.String("name", "Dev User", "name of the signed-in user")
+	emailVerified := flag.Bool("email-verified", true, "email_verified claim")
+	flag.Parse()
+
+	provider, err := oidctest.NewProvider(*issuer, *clientID)
+	if err != nil {
+		log.Fatal("Failed to generate signing key:", err)
+	}
+	provider.Email = *email
+	provider.Name = *name
+	provider.EmailVerified = *emailVerified
+
+	log.Printf("OIDC stub for %s (client %s) at %s, listening on %s", provider.Email, provider.ClientID, provider.Issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
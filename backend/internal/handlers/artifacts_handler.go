@@ -0,0 +1,66 @@
+package handlers
+
+/**
+ * Artifacts Handler
+ *
+ * Purpose: Serve artifacts from the local artifact store
+ *
+ * Endpoints (public - authorised by the link's signature instead of a JWT):
+ * - GET /api/artifacts/{key}?expires=&signature=: Download an artifact
+ *
+ * Links are handed out presigned in result responses, so a <video> or <img>
+ * tag can load them directly. Only registered when ARTIFACT_STORE=local;
+ * the S3 driver presigns links that point straight at the bucket.
+ */
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/storage"
+)
+
+type ArtifactsHandler struct {
+	store *storage.LocalStore
+}
+
+func NewArtifactsHandler(store *storage.LocalStore) *ArtifactsHandler {
+	return &ArtifactsHandler{store: store}
+}
+
+// Download serves an artifact to the holder of a valid presigned link
+// Supports Range requests so videos can be seeked
+// Endpoint: GET /api/artifacts/{key}
+func (h *ArtifactsHandler) Download(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	query := r.URL.Query()
+
+	if err := h.store.VerifySignature(key, query.Get("expires"), query.Get("signature")); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	content, info, err := h.store.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrArtifactNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		writeError(w, http.StatusNotFound, storage.ErrArtifactNotFound.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to read artifact")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.LastModified, seeker)
+		return
+	}
+
+	io.Copy(w, content)
+}
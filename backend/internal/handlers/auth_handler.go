@@ -8,31 +8,56 @@ package handlers
  * 
  * Endpoints:
  * - POST /api/users/signup: Create new user account
- * - POST /api/auth/login: Login with email/password
+ * - POST /api/auth/login: Login with email/password (see mfa_handler.go for two-factor logins)
+ * - POST /api/auth/refresh: Exchange a refresh token for a new token pair
+ * - POST /api/auth/logout: Revoke the current session (Protected)
  * - GET  /api/auth/me: Get current user info
- * - POST /api/users/set-password: Set password for OAuth users
+ * - POST /api/users/set-password: Set or change the caller's password (Protected)
+ * - POST /api/auth/verify-email: Verify an email address with an emailed token
+ * - POST /api/auth/resend-verification: Email a new verification link (Protected)
+ * - POST /api/auth/forgot-password: Email a password reset link
+ * - POST /api/auth/reset-password: Set a new password with an emailed token
+ * 
+ * Login and set-password attempts are throttled per client IP and account
+ * (see lockouts_handler.go).
  * 
  * Note: Returns JSON responses with proper status codes
  */
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
 	"backend/internal/middleware"
+	"backend/internal/models"
 	"backend/internal/services"
 )
 
 type UserHandler struct {
-	userService *services.UserService
-	jwtService  *services.JWTService
+	userService    *services.UserService
+	tokenService   *services.TokenService
+	accountService *services.AccountService
+	mfaService     *services.MFAService
+	throttle       *services.LoginThrottleService
+	audit          *services.AuditService
+
+	// requireVerification withholds tokens from users whose email is not verified
+	requireVerification bool
 }
 
 // NewUserHandler creates a new user handler instance
-func NewUserHandler(userService *services.UserService, jwtService *services.JWTService) *UserHandler {
+func NewUserHandler(userService *services.UserService, tokenService *services.TokenService, accountService *services.AccountService, mfaService *services.MFAService, throttle *services.LoginThrottleService, audit *services.AuditService, requireVerification bool) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		jwtService:  jwtService,
+		userService:         userService,
+		tokenService:        tokenService,
+		accountService:      accountService,
+		mfaService:          mfaService,
+		throttle:            throttle,
+		audit:               audit,
+		requireVerification: requireVerification,
 	}
 }
 
@@ -98,11 +123,37 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ==================================================
+	// SEND VERIFICATION EMAIL
+	// ==================================================
+	
+	// The account exists either way; the user can ask for another link
+	if err := h.accountService.SendVerification(r.Context(), user); err != nil {
+		log.Printf("Signup of user %s: %v", user.ID, err)
+	}
+
+	if h.requireVerification {
+		writeJSON(w, http.StatusCreated, Response{
+			Success: true,
+			Message: "User created - check your email to verify your address before logging in",
+			Data: map[string]interface{}{
+				"verification_required": true,
+				"user": map[string]string{
+					"id":       user.ID,
+					"email":    user.Email,
+					"username": user.Username,
+					"role":     user.Role,
+				},
+			},
+		})
+		return
+	}
+
 	// ==================================================
 	// GENERATE JWT TOKEN
 	// ==================================================
 	
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Username, user.Role)
+	tokens, err := h.tokenService.IssueTokens(r.Context(), user, models.SessionMethodPassword)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(Response{
@@ -121,7 +172,9 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Message: "User created successfully",
 		Data: map[string]interface{}{
-			"token": token,
+			"token":         tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+			"expires_in":    tokens.ExpiresIn,
 			"user": map[string]string{
 				"id":       user.ID,
 				"email":    user.Email,
@@ -132,58 +185,147 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// SetPasswordRequest represents the request to set password for Google OAuth users
-type SetPasswordRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+// SetPassword sets or changes the caller's own password
+// Google OAuth users without a password can set one directly; everyone else
+// has to send their current password too.
+// Request body: {"current_password": "...", "password": "..."}
+// Endpoint: POST /api/users/set-password (Protected - requires a JWT login session)
+func (h *UserHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	attempt := services.LoginAttempt{IP: middleware.GetClientIP(r.Context()), Email: claims.Email, UserID: claims.UserID}
+	if !checkLoginThrottle(w, r, h.throttle, attempt) {
+		return
+	}
+
+	if err := h.userService.SetUserPassword(r.Context(), claims.UserID, req); err != nil {
+		if !errors.Is(err, services.ErrIncorrectPassword) {
+			cancelLoginAttempt(r, h.throttle, attempt)
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	recordLoginSuccess(r, h.throttle, attempt)
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Password set successfully",
+	})
 }
 
-// SetPassword allows Google OAuth users to set a password for email/password login
-// Endpoint: POST /api/users/set-password
-func (h *UserHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
-	// Parse request body
-	var req SetPasswordRequest
+// EmailTokenRequest represents a request carrying a token from an emailed link
+type EmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail marks the address a verification link was sent to as verified
+// Endpoint: POST /api/auth/verify-email
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req EmailTokenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	// Validate input
-	if req.Email == "" || req.Password == "" {
-		json.NewEncoder(w).Encode(Response{
-			Success: false,
-			Message: "Email and password are required",
-		})
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		writeError(w, accountErrorStatus(err), err.Error())
 		return
 	}
 
-	if len(req.Password) < 6 {
-		json.NewEncoder(w).Encode(Response{
-			Success: false,
-			Message: "Password must be at least 6 characters",
-		})
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerification emails the caller a new verification link
+// Endpoint: POST /api/auth/resend-verification (Protected - requires a JWT login session)
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
 		return
 	}
 
-	// Update user password via service
-	err := h.userService.SetUserPassword(r.Context(), req.Email, req.Password)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(Response{
-			Success: false,
-			Message: err.Error(),
-		})
+	if err := h.accountService.ResendVerification(r.Context(), claims.UserID); err != nil {
+		writeError(w, accountErrorStatus(err), err.Error())
 		return
 	}
 
-	// Success response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{
+	writeJSON(w, http.StatusOK, Response{
 		Success: true,
-		Message: "Password set successfully",
+		Message: "Verification email sent",
+	})
+}
+
+// ForgotPasswordRequest represents a request for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPassword emails a password reset link
+// The response is the same whether or not the address has an account.
+// Endpoint: POST /api/auth/forgot-password
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		writeError(w, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "If an account exists for this email, a password reset link has been sent",
 	})
 }
 
+// ResetPassword sets a new password with a token from a reset link
+// Every session of the user is ended.
+// Request body: {"token": "...", "password": "..."}
+// Endpoint: POST /api/auth/reset-password
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req services.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req); err != nil {
+		writeError(w, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Password reset successfully - log in with your new password",
+	})
+}
+
+// accountErrorStatus maps account service errors to HTTP status codes
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrEmailAlreadyVerified):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // LoginRequest represents the login request
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -200,9 +342,20 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse clients and accounts with too many recent failures
+	attempt := services.LoginAttempt{IP: middleware.GetClientIP(r.Context()), Email: req.Email}
+	if !checkLoginThrottle(w, r, h.throttle, attempt) {
+		return
+	}
+
 	// Validate credentials via service
 	user, err := h.userService.LoginUser(r.Context(), req.Email, req.Password)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			recordLoginFailed(r, h.audit, req.Email, "password", err)
+		} else {
+			cancelLoginAttempt(r, h.throttle, attempt)
+		}
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(Response{
 			Success: false,
@@ -210,32 +363,82 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	recordLoginSuccess(r, h.throttle, attempt)
 
-	// Generate JWT token
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Username, user.Role)
+	if h.requireVerification && !user.EmailVerified {
+		writeError(w, http.StatusForbidden, "Email address not verified - check your email for the verification link")
+		return
+	}
+
+	// Generate JWT token, or an MFA pending token for users with two-factor authentication
+	result, err := h.mfaService.Login(r.Context(), user, models.SessionMethodPassword)
+	if errors.Is(err, services.ErrAccountDisabled) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(Response{
-			Success: false,
-			Message: "Failed to generate token",
-		})
+		writeError(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
+	recordLogin(r, h.audit, user, result, "password")
 
-	// Success response with JWT token
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{
+	writeLoginResult(w, "Login successful", result, user, h.mfaService)
+}
+
+// RefreshRequest represents the token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+// The presented refresh token is used up; presenting it again ends the session.
+// Endpoint: POST /api/auth/refresh
+func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(r.Context(), req.RefreshToken)
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrAccountDisabled) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
 		Success: true,
-		Message: "Login successful",
-		Data: map[string]interface{}{
-			"token": token,
-			"user": map[string]string{
-				"id":       user.ID,
-				"email":    user.Email,
-				"username": user.Username,
-				"role":     user.Role,
-			},
-		},
+		Message: "Token refreshed successfully",
+		Data:    tokens,
+	})
+}
+
+// Logout revokes the caller's session: its refresh tokens and access tokens stop working
+// Endpoint: POST /api/auth/logout (Protected - requires JWT)
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.tokenService.Logout(r.Context(), claims); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	recordUserEvent(r, h.audit, models.AuditLogout, claims.UserID, nil, nil)
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Logged out successfully",
 	})
 }
 
@@ -0,0 +1,155 @@
+package handlers
+
+/**
+ * Identities Handler
+ *
+ * Purpose: Handle HTTP requests for the login provider accounts linked to the caller
+ *
+ * Endpoints (all protected - require a JWT login session):
+ * - GET    /api/identities: List the caller's linked provider accounts
+ * - POST   /api/identities/{provider}/token: Link the account of an ID token from the provider
+ * - GET    /api/identities/{provider}/authorize: Get the provider URL to send the caller to
+ * - POST   /api/identities/{provider}/callback: Finish linking with the code and state the provider sent back
+ * - DELETE /api/identities/{provider}: Unlink the caller's account of a provider
+ *
+ * The provider redirects back to the same URL as for logins; the client
+ * remembers that it started a link and posts the code here instead of to
+ * /api/auth/oidc/{provider}/callback.
+ */
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/services"
+)
+
+type IdentitiesHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewIdentitiesHandler creates a new identities handler instance
+func NewIdentitiesHandler(oidcService *services.OIDCService) *IdentitiesHandler {
+	return &IdentitiesHandler{
+		oidcService: oidcService,
+	}
+}
+
+// GetIdentities lists the caller's linked provider accounts
+// Endpoint: GET /api/identities
+func (h *IdentitiesHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	identities, err := h.oidcService.Identities(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Identities retrieved successfully",
+		Data:    identities,
+	})
+}
+
+// LinkWithIDToken links the provider account of an ID token to the caller
+// Request body: {"id_token": "..."}
+// Endpoint: POST /api/identities/{provider}/token
+func (h *IdentitiesHandler) LinkWithIDToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.OIDCIDTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	identity, err := h.oidcService.LinkWithIDToken(r.Context(), claims.UserID, mux.Vars(r)["provider"], req)
+	if err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Provider account linked successfully",
+		Data:    identity,
+	})
+}
+
+// Authorize starts linking a provider account with the authorization code flow
+// Endpoint: GET /api/identities/{provider}/authorize
+func (h *IdentitiesHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	url, err := h.oidcService.StartLink(r.Context(), claims.UserID, mux.Vars(r)["provider"])
+	if err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Redirect to the provider",
+		Data:    map[string]string{"url": url},
+	})
+}
+
+// Callback finishes linking a provider account
+// Request body: {"code": "...", "state": "..."}
+// Endpoint: POST /api/identities/{provider}/callback
+func (h *IdentitiesHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	identity, err := h.oidcService.FinishLink(r.Context(), claims.UserID, mux.Vars(r)["provider"], req)
+	if err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Provider account linked successfully",
+		Data:    identity,
+	})
+}
+
+// Unlink removes the caller's account of a provider
+// Endpoint: DELETE /api/identities/{provider}
+func (h *IdentitiesHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.oidcService.Unlink(r.Context(), claims.UserID, mux.Vars(r)["provider"]); err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Provider account unlinked successfully",
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+/**
+ * Lockouts Handler
+ *
+ * Purpose: Handle HTTP requests for brute-force lockouts
+ *
+ * Endpoints (all protected - require users:admin):
+ * - GET    /api/users/{id}/lockout: Show a user's failed logins and lock
+ * - DELETE /api/users/{id}/lockout: Unlock a user
+ *
+ * Also has the helpers the login, set-password and MFA handlers use to
+ * throttle their attempts (see services.LoginThrottleService). Locked
+ * attempts are answered with 429 and a Retry-After header.
+ */
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+type LockoutsHandler struct {
+	throttle *services.LoginThrottleService
+	audit    *services.AuditService
+}
+
+// NewLockoutsHandler creates a new lockouts handler instance
+func NewLockoutsHandler(throttle *services.LoginThrottleService, audit *services.AuditService) *LockoutsHandler {
+	return &LockoutsHandler{
+		throttle: throttle,
+		audit:    audit,
+	}
+}
+
+// GetLockout shows a user's recent failed logins and whether they are locked
+// Endpoint: GET /api/users/{id}/lockout
+func (h *LockoutsHandler) GetLockout(w http.ResponseWriter, r *http.Request) {
+	status, err := h.throttle.Status(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, lockoutErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Lockout status retrieved successfully",
+		Data:    status,
+	})
+}
+
+// Unlock lifts a user's lock and forgets their failed logins
+// Endpoint: DELETE /api/users/{id}/lockout
+func (h *LockoutsHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+	if err := h.throttle.Unlock(r.Context(), userID); err != nil {
+		writeError(w, lockoutErrorStatus(err), err.Error())
+		return
+	}
+	recordUserEvent(r, h.audit, models.AuditUserUnlocked, userID, nil, nil)
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "User unlocked successfully",
+	})
+}
+
+// lockoutErrorStatus maps login throttle service errors to HTTP status codes
+func lockoutErrorStatus(err error) int {
+	if errors.Is(err, services.ErrUserNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// ==================================================
+// THROTTLING HELPERS
+// ==================================================
+
+// checkLoginThrottle answers 429 and returns false while the attempt is locked out
+// Otherwise the attempt already counts as failed; a caller whose request fails
+// for another reason than a wrong credential must call cancelLoginAttempt.
+func checkLoginThrottle(w http.ResponseWriter, r *http.Request, throttle *services.LoginThrottleService, attempt services.LoginAttempt) bool {
+	err := throttle.Check(r.Context(), attempt)
+
+	var throttled *services.ThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// cancelLoginAttempt takes back an attempt that failed before its credential was checked
+// The request has already failed, so errors are only logged.
+func cancelLoginAttempt(r *http.Request, throttle *services.LoginThrottleService, attempt services.LoginAttempt) {
+	if err := throttle.Cancel(r.Context(), attempt); err != nil {
+		log.Printf("Failed to take back login attempt from %s: %v", attempt.IP, err)
+	}
+}
+
+// recordLoginSuccess forgets the failed attempts of the account
+func recordLoginSuccess(r *http.Request, throttle *services.LoginThrottleService, attempt services.LoginAttempt) {
+	if err := throttle.Succeed(r.Context(), attempt); err != nil {
+		log.Printf("Failed to reset failed logins: %v", err)
+	}
+}
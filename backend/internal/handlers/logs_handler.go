@@ -0,0 +1,170 @@
+package handlers
+
+/**
+ * Logs Handler
+ *
+ * Purpose: Handle HTTP requests for the live output of test runs
+ *
+ * Endpoints (protected - appending requires a worker credential, the rest a JWT):
+ * - POST /api/runs/{id}/logs: Runner appends a batch of log lines
+ * - GET  /api/runs/{id}/logs?after=N: Lines after seq N (one page)
+ * - GET  /api/runs/{id}/logs/stream?offset=N: Follow the lines after seq N as Server-Sent Events
+ *
+ * The stream sends one "log" event per line with the line's seq as the
+ * event ID, so a reconnecting EventSource resumes via Last-Event-ID. When
+ * the run finishes an "end" event carries the final run and the stream closes.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+type LogsHandler struct {
+	logService *services.LogService
+}
+
+func NewLogsHandler(logService *services.LogService) *LogsHandler {
+	return &LogsHandler{logService: logService}
+}
+
+// AppendLogs appends log lines to the run the calling runner is executing
+// Endpoint: POST /api/runs/{id}/logs
+func (h *LogsHandler) AppendLogs(w http.ResponseWriter, r *http.Request) {
+	worker, ok := requestWorker(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.AppendLogsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	last, err := h.logService.AppendLogs(r.Context(), worker.ProjectID, mux.Vars(r)["id"], worker.ID, req)
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Logs appended",
+		Data:    map[string]int64{"last_seq": last},
+	})
+}
+
+// GetLogs returns the lines of one of the project's runs after ?after=
+// Endpoint: GET /api/runs/{id}/logs
+func (h *LogsHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+
+	lines, err := h.logService.GetLogs(r.Context(), member.ProjectID, mux.Vars(r)["id"], after)
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Logs retrieved successfully",
+		Data:    lines,
+	})
+}
+
+// StreamLogs follows one of the project's runs as Server-Sent Events
+// Starts after ?offset=, or after the Last-Event-ID header when reconnecting
+// Endpoint: GET /api/runs/{id}/logs/stream
+func (h *LogsHandler) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	offset := r.URL.Query().Get("offset")
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		offset = lastEventID
+	}
+	after, _ := strconv.ParseInt(offset, 10, 64)
+
+	// Headers are only sent once the run is known to exist and be in the project
+	started := false
+	send := func(lines []models.RunLogLine) error {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		if len(lines) == 0 {
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		for _, line := range lines {
+			if err := writeEvent(w, strconv.FormatInt(line.Seq, 10), "log", line); err != nil {
+				return err
+			}
+		}
+
+		flusher.Flush()
+		return nil
+	}
+
+	run, err := h.logService.FollowLogs(r.Context(), member.ProjectID, mux.Vars(r)["id"], after, send)
+	if r.Context().Err() != nil {
+		// The client went away
+		return
+	}
+	if err != nil {
+		if !started {
+			writeError(w, resultErrorStatus(err), err.Error())
+			return
+		}
+		writeEvent(w, "", "error", map[string]string{"message": err.Error()})
+		flusher.Flush()
+		return
+	}
+
+	writeEvent(w, "", "end", run)
+	flusher.Flush()
+}
+
+// writeEvent writes one Server-Sent Event with a JSON data field
+func writeEvent(w http.ResponseWriter, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,248 @@
+package handlers
+
+/**
+ * MFA Handler
+ *
+ * Purpose: Handle HTTP requests for TOTP two-factor authentication
+ *
+ * Endpoints:
+ * - POST /api/auth/mfa/verify: Exchange an MFA pending token and a code for a token pair
+ * - POST /api/auth/mfa/totp/enroll: Create a TOTP secret (Protected)
+ * - POST /api/auth/mfa/totp/confirm: Enable TOTP with a first code; returns recovery codes (Protected)
+ * - POST /api/auth/mfa/totp/disable: Disable TOTP (Protected, needs a code)
+ * - POST /api/auth/mfa/recovery-codes: Replace the recovery codes (Protected, needs a code)
+ *
+ * Logins of users with TOTP enabled answer with {"mfa_required": true,
+ * "mfa_token": "..."} instead of a token pair (see writeLoginResult).
+ * Failed codes count towards the login lockout (see lockouts_handler.go).
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+type MFAHandler struct {
+	mfaService *services.MFAService
+	throttle   *services.LoginThrottleService
+	audit      *services.AuditService
+}
+
+// NewMFAHandler creates a new MFA handler instance
+func NewMFAHandler(mfaService *services.MFAService, throttle *services.LoginThrottleService, audit *services.AuditService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		throttle:   throttle,
+		audit:      audit,
+	}
+}
+
+// Verify finishes a login with a TOTP or recovery code
+// Request body: {"mfa_token": "...", "code": "123456"}
+// Endpoint: POST /api/auth/mfa/verify
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req services.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Codes are throttled like passwords, per client IP and user
+	attempt := services.LoginAttempt{IP: middleware.GetClientIP(r.Context()), UserID: h.mfaService.PendingUserID(req.MFAToken)}
+	if !checkLoginThrottle(w, r, h.throttle, attempt) {
+		return
+	}
+
+	tokens, user, err := h.mfaService.Verify(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) || errors.Is(err, services.ErrInvalidMFAToken) {
+			h.audit.Record(r.Context(), models.AuditEvent{
+				ActorID:    attempt.UserID,
+				Action:     models.AuditMFAFailed,
+				TargetType: models.AuditTargetUser,
+				TargetID:   attempt.UserID,
+				Details:    map[string]interface{}{"reason": err.Error()},
+			})
+		} else {
+			cancelLoginAttempt(r, h.throttle, attempt)
+		}
+		writeError(w, mfaErrorStatus(err), err.Error())
+		return
+	}
+	recordLoginSuccess(r, h.throttle, attempt)
+	recordLogin(r, h.audit, user, &services.LoginResult{Tokens: tokens}, "totp")
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Login successful",
+		Data:    tokenPairData(tokens, user),
+	})
+}
+
+// Enroll creates a new TOTP secret for the caller
+// Endpoint: POST /api/auth/mfa/totp/enroll
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.Enroll(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, mfaErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Add the secret to your authenticator app, then confirm with a code",
+		Data:    enrollment,
+	})
+}
+
+// Confirm enables TOTP for the caller
+// Request body: {"code": "123456"}
+// Endpoint: POST /api/auth/mfa/totp/confirm
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(r.Context(), claims.UserID, req.Code)
+	if err != nil {
+		writeError(w, mfaErrorStatus(err), err.Error())
+		return
+	}
+	recordUserEvent(r, h.audit, models.AuditMFAEnabled, claims.UserID,
+		map[string]interface{}{"totp_enabled": false}, map[string]interface{}{"totp_enabled": true})
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Two-factor authentication enabled - store the recovery codes, they will not be shown again",
+		Data:    map[string]interface{}{"recovery_codes": codes},
+	})
+}
+
+// Disable turns off TOTP for the caller
+// Request body: {"code": "123456"}
+// Endpoint: POST /api/auth/mfa/totp/disable
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), claims.UserID, req.Code); err != nil {
+		writeError(w, mfaErrorStatus(err), err.Error())
+		return
+	}
+	recordUserEvent(r, h.audit, models.AuditMFADisabled, claims.UserID,
+		map[string]interface{}{"totp_enabled": true}, map[string]interface{}{"totp_enabled": false})
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes
+// Request body: {"code": "123456"}
+// Endpoint: POST /api/auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), claims.UserID, req.Code)
+	if err != nil {
+		writeError(w, mfaErrorStatus(err), err.Error())
+		return
+	}
+	recordUserEvent(r, h.audit, models.AuditRecoveryCodes, claims.UserID, nil, nil)
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "New recovery codes generated - the old ones no longer work",
+		Data:    map[string]interface{}{"recovery_codes": codes},
+	})
+}
+
+// writeLoginResult answers a successful first login factor
+// Users with TOTP get an MFA pending token to send to /api/auth/mfa/verify.
+func writeLoginResult(w http.ResponseWriter, message string, result *services.LoginResult, user *models.User, mfaService *services.MFAService) {
+	if result.MFAToken != "" {
+		writeJSON(w, http.StatusOK, Response{
+			Success: true,
+			Message: "Two-factor authentication required",
+			Data: map[string]interface{}{
+				"mfa_required": true,
+				"mfa_token":    result.MFAToken,
+				"expires_in":   int(mfaService.PendingTTL().Seconds()),
+			},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    tokenPairData(result.Tokens, user),
+	})
+}
+
+// tokenPairData is the response data of a login
+func tokenPairData(tokens *services.TokenPair, user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user": map[string]string{
+			"id":       user.ID,
+			"email":    user.Email,
+			"username": user.Username,
+			"role":     user.Role,
+		},
+	}
+}
+
+// mfaErrorStatus maps MFA service errors to HTTP status codes
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFAToken), errors.Is(err, services.ErrInvalidMFACode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAccountDisabled):
+		return http.StatusForbidden
+	case strings.HasPrefix(err.Error(), "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
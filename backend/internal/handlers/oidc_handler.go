@@ -0,0 +1,156 @@
+package handlers
+
+/**
+ * OIDC Handler
+ *
+ * Purpose: Handle HTTP requests for OpenID Connect logins
+ *
+ * Endpoints:
+ * - GET  /api/auth/oidc/providers: List the login providers
+ * - POST /api/auth/oidc/{provider}/token: Log in with an ID token from the provider
+ * - GET  /api/auth/oidc/{provider}/authorize: Get the provider URL to send the user to
+ * - POST /api/auth/oidc/{provider}/callback: Finish the login with the code and state the provider sent back
+ * - POST /api/auth/google: The Google sign-in button's ID token login ({"credential": "..."})
+ *
+ * Logins answer like POST /api/auth/login, including the two-factor step.
+ * A provider account logs in the user it is linked to; see
+ * identities_handler.go for linking accounts explicitly.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/oidc"
+	"backend/internal/services"
+)
+
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+	mfaService  *services.MFAService
+	audit       *services.AuditService
+}
+
+// NewOIDCHandler creates a new OIDC handler instance
+func NewOIDCHandler(oidcService *services.OIDCService, mfaService *services.MFAService, audit *services.AuditService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		mfaService:  mfaService,
+		audit:       audit,
+	}
+}
+
+// GetProviders lists the configured login providers
+// Endpoint: GET /api/auth/oidc/providers
+func (h *OIDCHandler) GetProviders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Providers retrieved successfully",
+		Data:    h.oidcService.Providers(),
+	})
+}
+
+// IDTokenLogin logs in with an ID token the client got from the provider
+// Request body: {"id_token": "..."}
+// Endpoint: POST /api/auth/oidc/{provider}/token
+func (h *OIDCHandler) IDTokenLogin(w http.ResponseWriter, r *http.Request) {
+	h.idTokenLogin(w, r, mux.Vars(r)["provider"])
+}
+
+// GoogleAuth logs in with the Google sign-in button's credential
+// Endpoint: POST /api/auth/google
+func (h *OIDCHandler) GoogleAuth(w http.ResponseWriter, r *http.Request) {
+	h.idTokenLogin(w, r, "google")
+}
+
+func (h *OIDCHandler) idTokenLogin(w http.ResponseWriter, r *http.Request, provider string) {
+	var req services.OIDCIDTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, user, err := h.oidcService.LoginWithIDToken(r.Context(), provider, req)
+	if err != nil {
+		h.recordFailure(r, provider, err)
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+	recordLogin(r, h.audit, user, result, "oidc:"+provider)
+
+	writeLoginResult(w, "Login successful", result, user, h.mfaService)
+}
+
+// Authorize starts the authorization code flow
+// The client sends the user to the returned URL; the provider redirects them
+// back to the provider's redirect URL with a code and state.
+// Endpoint: GET /api/auth/oidc/{provider}/authorize
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	url, err := h.oidcService.StartLogin(r.Context(), mux.Vars(r)["provider"])
+	if err != nil {
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Redirect to the provider",
+		Data:    map[string]string{"url": url},
+	})
+}
+
+// Callback finishes the authorization code flow
+// Request body: {"code": "...", "state": "..."}
+// Endpoint: POST /api/auth/oidc/{provider}/callback
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req services.OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	provider := mux.Vars(r)["provider"]
+	result, user, err := h.oidcService.FinishLogin(r.Context(), provider, req)
+	if err != nil {
+		h.recordFailure(r, provider, err)
+		writeError(w, oidcErrorStatus(err), err.Error())
+		return
+	}
+	recordLogin(r, h.audit, user, result, "oidc:"+provider)
+
+	writeLoginResult(w, "Login successful", result, user, h.mfaService)
+}
+
+// recordFailure audits a rejected login; unknown providers and provider outages are not attempts
+func (h *OIDCHandler) recordFailure(r *http.Request, provider string, err error) {
+	if oidcErrorStatus(err) == http.StatusUnauthorized {
+		recordLoginFailed(r, h.audit, "", "oidc:"+provider, err)
+	}
+}
+
+// oidcErrorStatus maps OIDC errors to HTTP status codes
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, services.ErrInvalidOIDCLogin), errors.Is(err, services.ErrEmailNotVerified):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrAccountDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrIdentityNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAccountNotLinked), errors.Is(err, services.ErrIdentityInUse),
+		errors.Is(err, services.ErrIdentityExists), errors.Is(err, services.ErrLastLoginMethod):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "failed to"):
+		return http.StatusInternalServerError
+	case strings.Contains(err.Error(), "discovery of"), strings.Contains(err.Error(), "code exchange failed"):
+		return http.StatusBadGateway
+	default:
+		return http.StatusBadRequest
+	}
+}
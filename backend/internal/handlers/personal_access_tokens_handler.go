@@ -0,0 +1,146 @@
+package handlers
+
+/**
+ * Personal Access Tokens Handler
+ *
+ * Purpose: Handle HTTP requests for personal access tokens
+ *
+ * Endpoints (all protected - require a JWT login session):
+ * - POST   /api/tokens: Create a token; the response is the only time its value is shown
+ * - GET    /api/tokens: List the caller's tokens
+ * - DELETE /api/tokens/{id}: Revoke one of the caller's tokens
+ *
+ * Tokens are used like JWTs: "Authorization: Bearer tops_...". They cannot
+ * be used to manage tokens themselves.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+type PersonalAccessTokensHandler struct {
+	patService *services.PersonalAccessTokenService
+	audit      *services.AuditService
+}
+
+// NewPersonalAccessTokensHandler creates a new personal access tokens handler instance
+func NewPersonalAccessTokensHandler(patService *services.PersonalAccessTokenService, audit *services.AuditService) *PersonalAccessTokensHandler {
+	return &PersonalAccessTokensHandler{
+		patService: patService,
+		audit:      audit,
+	}
+}
+
+// CreateToken mints a new token for the caller
+// Request body: {"name": "ci", "scopes": ["runs:create", "runs:read"], "expires_in_days": 90}
+// Endpoint: POST /api/tokens
+func (h *PersonalAccessTokensHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.PersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	token, plaintext, err := h.patService.CreateToken(r.Context(), claims.UserID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.audit.Record(r.Context(), models.AuditEvent{
+		Action:     models.AuditTokenCreated,
+		TargetType: models.AuditTargetToken,
+		TargetID:   token.ID,
+		ProjectID:  token.ProjectID,
+		After:      services.AuditSnapshot(token),
+	})
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Personal access token created - copy it now, it will not be shown again",
+		Data: map[string]interface{}{
+			"token":                 plaintext,
+			"personal_access_token": token,
+		},
+	})
+}
+
+// GetTokens lists the caller's tokens without their values
+// Endpoint: GET /api/tokens
+func (h *PersonalAccessTokensHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.patService.ListTokens(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Personal access tokens retrieved successfully",
+		Data:    tokens,
+	})
+}
+
+// RevokeToken revokes one of the caller's tokens
+// Endpoint: DELETE /api/tokens/{id}
+func (h *PersonalAccessTokensHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	tokenID := mux.Vars(r)["id"]
+	err := h.patService.RevokeToken(r.Context(), claims.UserID, tokenID)
+	if errors.Is(err, services.ErrPersonalAccessTokenNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.audit.Record(r.Context(), models.AuditEvent{
+		Action:     models.AuditTokenRevoked,
+		TargetType: models.AuditTargetToken,
+		TargetID:   tokenID,
+	})
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Personal access token revoked successfully",
+	})
+}
+
+// sessionClaims returns the caller's claims if they authenticated with a JWT login session
+// A leaked personal access token must not be able to mint or revoke tokens
+// or take over the account (password, organizations).
+func sessionClaims(w http.ResponseWriter, r *http.Request) (*services.TokenClaims, bool) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return nil, false
+	}
+	if claims.PersonalAccessTokenID != "" {
+		writeError(w, http.StatusForbidden, "Personal access tokens cannot be used for this; log in instead")
+		return nil, false
+	}
+
+	return claims, true
+}
@@ -0,0 +1,317 @@
+package handlers
+
+/**
+ * Projects Handler
+ *
+ * Purpose: Handle HTTP requests for organizations, projects and memberships
+ *
+ * Endpoints (all protected - require JWT):
+ * - POST   /api/organizations: Create an organization (the caller becomes owner)
+ * - GET    /api/organizations: List the caller's organizations
+ * - GET    /api/organizations/{id}/members: List an organization's members
+ * - PUT    /api/organizations/{id}/members: Add a member or change their role (owners only)
+ * - DELETE /api/organizations/{id}/members/{userId}: Remove a member (owners only)
+ * - POST   /api/organizations/{id}/projects: Create a project (owners only)
+ * - GET    /api/projects: List the caller's projects with their role in each
+ * - GET    /api/project: Get the active project and the caller's role in it
+ * - GET    /api/project/members: List the active project's members
+ * - PUT    /api/project/members: Add a member or change their role (requires members:manage)
+ * - DELETE /api/project/members/{userId}: Remove a member (requires members:manage)
+ *
+ * The active project is selected with the X-Project-ID header. Organizations
+ * can only be changed with a login session, not a personal access token.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
+)
+
+type ProjectsHandler struct {
+	projectService *services.ProjectService
+}
+
+// NewProjectsHandler creates a new projects handler instance
+func NewProjectsHandler(projectService *services.ProjectService) *ProjectsHandler {
+	return &ProjectsHandler{
+		projectService: projectService,
+	}
+}
+
+// CreateOrganization creates an organization owned by the caller
+// Endpoint: POST /api/organizations
+func (h *ProjectsHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	org, err := h.projectService.CreateOrganization(r.Context(), claims.UserID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Organization created successfully",
+		Data:    org,
+	})
+}
+
+// GetOrganizations lists the organizations the caller belongs to
+// Endpoint: GET /api/organizations
+func (h *ProjectsHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	orgs, err := h.projectService.ListOrganizations(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Organizations retrieved successfully",
+		Data:    orgs,
+	})
+}
+
+// GetOrganizationMembers lists the members of one of the caller's organizations
+// Endpoint: GET /api/organizations/{id}/members
+func (h *ProjectsHandler) GetOrganizationMembers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	members, err := h.projectService.ListOrganizationMembers(r.Context(), claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Organization members retrieved successfully",
+		Data:    members,
+	})
+}
+
+// SetOrganizationMember adds a user to an organization by email, or changes their role
+// Endpoint: PUT /api/organizations/{id}/members
+func (h *ProjectsHandler) SetOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	member, err := h.projectService.SetOrganizationMember(r.Context(), claims.UserID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Organization member updated successfully",
+		Data:    member,
+	})
+}
+
+// RemoveOrganizationMember removes a user from an organization and all its projects
+// Endpoint: DELETE /api/organizations/{id}/members/{userId}
+func (h *ProjectsHandler) RemoveOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.projectService.RemoveOrganizationMember(r.Context(), claims.UserID, vars["id"], vars["userId"]); err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Organization member removed successfully",
+	})
+}
+
+// CreateProject creates a project in one of the caller's organizations
+// Endpoint: POST /api/organizations/{id}/projects
+func (h *ProjectsHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	project, err := h.projectService.CreateProject(r.Context(), claims.UserID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Project created successfully",
+		Data:    project,
+	})
+}
+
+// GetProjects lists the projects the caller can use, personal project first
+// Endpoint: GET /api/projects
+func (h *ProjectsHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	projects, err := h.projectService.ListProjects(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Projects retrieved successfully",
+		Data:    projects,
+	})
+}
+
+// GetActiveProject returns the active project and the caller's role in it
+// Endpoint: GET /api/project
+func (h *ProjectsHandler) GetActiveProject(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	project, err := h.projectService.GetProject(r.Context(), member)
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Project retrieved successfully",
+		Data:    project,
+	})
+}
+
+// GetProjectMembers lists the members of the active project
+// Endpoint: GET /api/project/members
+func (h *ProjectsHandler) GetProjectMembers(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	members, err := h.projectService.ListProjectMembers(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Project members retrieved successfully",
+		Data:    members,
+	})
+}
+
+// SetProjectMember adds a member of the organization to the active project, or changes their role
+// Endpoint: PUT /api/project/members
+func (h *ProjectsHandler) SetProjectMember(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.projectService.SetProjectMember(r.Context(), member.ProjectID, req)
+	if err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Project member updated successfully",
+		Data:    updated,
+	})
+}
+
+// RemoveProjectMember removes a member from the active project
+// Endpoint: DELETE /api/project/members/{userId}
+func (h *ProjectsHandler) RemoveProjectMember(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.projectService.RemoveProjectMember(r.Context(), member.ProjectID, mux.Vars(r)["userId"]); err != nil {
+		writeError(w, projectErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Project member removed successfully",
+	})
+}
+
+// projectErrorStatus maps project service errors to HTTP status codes
+func projectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrOrganizationNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNotOrganizationOwner):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrPersonalProject), errors.Is(err, services.ErrLastAdmin):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeJSON sends a standard JSON response with the given status code
+func writeJSON(w http.ResponseWriter, statusCode int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError sends a failed JSON response with the given status code and message
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, Response{
+		Success: false,
+		Message: message,
+	})
+}
@@ -1,32 +1,205 @@
 package handlers
 
+/**
+ * Results Handler
+ *
+ * Purpose: Handle HTTP requests for test results
+ *
+ * Endpoints (protected - the upload requires a worker credential, the rest a JWT):
+ * - POST /api/runs/{id}/results: Runner uploads a result with its artifacts
+ * - GET  /api/runs/{id}/results: List the results of one of the project's runs
+ * - GET  /api/results/{id}: Get one of the project's results
+ *
+ * The upload is multipart/form-data with these parts, in this order:
+ * - result:     JSON, see services.ResultRequest (required, must come first)
+ * - video:      recorded video file (optional)
+ * - screenshot: screenshot file (optional)
+ *
+ * File parts are streamed straight to the artifact store, so videos are never held in
+ * memory. The runner is identified by its worker credential and may only
+ * upload results for a run it is executing.
+ */
+
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
 )
 
+// maxResultJSONSize limits the size of the result part; artifacts are limited separately
+const maxResultJSONSize = 10 << 20
+
 type ResultsHandler struct {
-	// TODO: Add service dependencies
+	resultService *services.ResultService
+	maxUploadSize int64
 }
 
-func NewResultsHandler() *ResultsHandler {
-	return &ResultsHandler{}
+// NewResultsHandler creates a new results handler; maxUploadSize caps the whole upload in bytes
+func NewResultsHandler(resultService *services.ResultService, maxUploadSize int64) *ResultsHandler {
+	return &ResultsHandler{
+		resultService: resultService,
+		maxUploadSize: maxUploadSize,
+	}
 }
 
+// GetResults lists the results of one of the project's runs
+// Endpoint: GET /api/runs/{id}/results
 func (h *ResultsHandler) GetResults(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get results logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get results endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	results, err := h.resultService.GetResults(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Results retrieved successfully",
+		Data:    results,
+	})
 }
 
+// GetResultByID returns one of the project's results
+// Endpoint: GET /api/results/{id}
 func (h *ResultsHandler) GetResultByID(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get result by ID logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get result by id endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	result, err := h.resultService.GetResultByID(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Result retrieved successfully",
+		Data:    result,
+	})
 }
 
+// UploadResult receives a run's result and artifacts from the runner executing it
+// Endpoint: POST /api/runs/{id}/results
 func (h *ResultsHandler) UploadResult(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement upload result logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "upload result endpoint"})
+	worker, ok := requestWorker(w, r)
+	if !ok {
+		return
+	}
+
+	// Check ownership before reading a single byte of the body
+	run, err := h.resultService.BeginUpload(r.Context(), worker.ProjectID, mux.Vars(r)["id"], worker.ID)
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Expected a multipart/form-data body")
+		return
+	}
+
+	var req services.ResultRequest
+	var haveResult bool
+	var videoKey, screenshotKey string
+
+	// Remove anything already stored if the upload does not complete
+	completed := false
+	defer func() {
+		if !completed {
+			h.resultService.DiscardArtifact(context.WithoutCancel(r.Context()), videoKey)
+			h.resultService.DiscardArtifact(context.WithoutCancel(r.Context()), screenshotKey)
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read upload: "+err.Error())
+			return
+		}
+
+		status := http.StatusBadRequest
+		switch part.FormName() {
+		case "result":
+			err = json.NewDecoder(io.LimitReader(part, maxResultJSONSize)).Decode(&req)
+			if err != nil {
+				err = errors.New("invalid result JSON")
+			}
+			haveResult = true
+		case services.ArtifactVideo, services.ArtifactScreenshot:
+			if !haveResult {
+				err = errors.New("the result part must come before any file")
+				break
+			}
+			var key string
+			key, err = h.resultService.StoreArtifact(r.Context(), run.ID, part.FormName(), part.FileName(), part)
+			if err != nil {
+				status = resultErrorStatus(err)
+			}
+			if part.FormName() == services.ArtifactVideo {
+				videoKey = key
+			} else {
+				screenshotKey = key
+			}
+		}
+		part.Close()
+
+		if err != nil {
+			writeError(w, status, err.Error())
+			return
+		}
+	}
+
+	if !haveResult {
+		writeError(w, http.StatusBadRequest, "Missing result part")
+		return
+	}
+
+	result, err := h.resultService.SaveResult(r.Context(), run, req, videoKey, screenshotKey)
+	if err != nil {
+		writeError(w, resultErrorStatus(err), err.Error())
+		return
+	}
+	completed = true
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Result uploaded successfully",
+		Data:    result,
+	})
+}
+
+// resultErrorStatus maps result and run service errors to HTTP status codes
+func resultErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrResultNotFound), errors.Is(err, services.ErrRunNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRunNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidRunTransition):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRequest):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
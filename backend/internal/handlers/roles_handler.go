@@ -0,0 +1,91 @@
+package handlers
+
+/**
+ * Roles Handler
+ *
+ * Purpose: Handle HTTP requests for roles and permissions
+ *
+ * Endpoints (all protected - require JWT):
+ * - GET /api/roles: List every role with its permissions
+ * - PUT /api/users/{id}/role: Change a user's role (requires users:admin)
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+type RolesHandler struct {
+	roleService *services.RoleService
+}
+
+// NewRolesHandler creates a new roles handler instance
+func NewRolesHandler(roleService *services.RoleService) *RolesHandler {
+	return &RolesHandler{
+		roleService: roleService,
+	}
+}
+
+// GetRoles lists every role with its permissions
+// Endpoint: GET /api/roles
+func (h *RolesHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Roles retrieved successfully",
+		Data:    models.RolePermissions,
+	})
+}
+
+// SetUserRoleRequest represents the request to change a user's role
+type SetUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// SetUserRole changes a user's role; it applies to requests without waiting for tokens to expire
+// Endpoint: PUT /api/users/{id}/role
+func (h *RolesHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req SetUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := mux.Vars(r)["id"]
+	if err := h.roleService.SetUserRole(r.Context(), claims.UserID, userID, req.Role); err != nil {
+		writeError(w, roleErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "User role updated successfully",
+		Data: map[string]string{
+			"id":   userID,
+			"role": req.Role,
+		},
+	})
+}
+
+// roleErrorStatus maps role service errors to HTTP status codes
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrOwnRole):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,144 @@
+package handlers
+
+/**
+ * Runs Handler
+ *
+ * Purpose: Handle HTTP requests for test runs
+ *
+ * Endpoints (all protected - require JWT):
+ * - POST /api/tests/{id}/runs: Start a new run of a test
+ * - GET  /api/tests/{id}/runs: List the run history of a test
+ * - GET  /api/runs/{id}: Get a single run
+ * - POST /api/runs/{id}/cancel: Cancel a queued or running run
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
+)
+
+type RunsHandler struct {
+	runService *services.RunService
+}
+
+// NewRunsHandler creates a new runs handler instance
+func NewRunsHandler(runService *services.RunService) *RunsHandler {
+	return &RunsHandler{
+		runService: runService,
+	}
+}
+
+// CreateRun starts a new run of one of the project's tests
+// The request body is optional: {"browser": "chrome", "headless": true, "timeout": 300}
+// Endpoint: POST /api/tests/{id}/runs
+func (h *RunsHandler) CreateRun(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	run, err := h.runService.CreateRun(r.Context(), member.ProjectID, member.UserID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeError(w, runErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Run queued successfully",
+		Data:    run,
+	})
+}
+
+// GetRuns lists the run history of one of the project's tests
+// Endpoint: GET /api/tests/{id}/runs
+func (h *RunsHandler) GetRuns(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	runs, err := h.runService.ListRuns(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, runErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Runs retrieved successfully",
+		Data:    runs,
+	})
+}
+
+// GetRunByID returns a single run in the project
+// Endpoint: GET /api/runs/{id}
+func (h *RunsHandler) GetRunByID(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	run, err := h.runService.GetRun(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, runErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Run retrieved successfully",
+		Data:    run,
+	})
+}
+
+// CancelRun cancels a queued or running run in the project
+// Endpoint: POST /api/runs/{id}/cancel
+func (h *RunsHandler) CancelRun(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	run, err := h.runService.CancelRun(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, runErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Run cancelled successfully",
+		Data:    run,
+	})
+}
+
+// runErrorStatus maps run service errors to HTTP status codes
+func runErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRunNotFound), errors.Is(err, services.ErrTestNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidRunTransition):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrEnqueueFailed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,171 @@
+package handlers
+
+/**
+ * Schedules Handler
+ *
+ * Purpose: Handle HTTP requests for scheduled runs
+ *
+ * Endpoints (all protected - require JWT):
+ * - POST   /api/schedules: Create a schedule
+ * - GET    /api/schedules: List the project's schedules
+ * - GET    /api/schedules/{id}: Get one of the project's schedules
+ * - PUT    /api/schedules/{id}: Update one of the project's schedules
+ * - DELETE /api/schedules/{id}: Delete one of the project's schedules
+ *
+ * A schedule has a cron expression ("0 2 * * *", "@hourly"), an IANA
+ * timezone and a target test or suite; see services.ScheduleRequest.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
+)
+
+type SchedulesHandler struct {
+	scheduleService *services.ScheduleService
+}
+
+// NewSchedulesHandler creates a new schedules handler instance
+func NewSchedulesHandler(scheduleService *services.ScheduleService) *SchedulesHandler {
+	return &SchedulesHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// CreateSchedule handles schedule creation
+// Endpoint: POST /api/schedules
+func (h *SchedulesHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(r.Context(), member.ProjectID, member.UserID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Schedule created successfully",
+		Data:    schedule,
+	})
+}
+
+// GetSchedules lists the project's schedules
+// Endpoint: GET /api/schedules
+func (h *SchedulesHandler) GetSchedules(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	schedules, err := h.scheduleService.GetAllSchedules(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Schedules retrieved successfully",
+		Data:    schedules,
+	})
+}
+
+// GetScheduleByID returns one of the project's schedules
+// Endpoint: GET /api/schedules/{id}
+func (h *SchedulesHandler) GetScheduleByID(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	schedule, err := h.scheduleService.GetScheduleByID(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, scheduleErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Schedule retrieved successfully",
+		Data:    schedule,
+	})
+}
+
+// UpdateSchedule applies a partial update to one of the project's schedules
+// Endpoint: PUT /api/schedules/{id}
+func (h *SchedulesHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.ScheduleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(r.Context(), member.ProjectID, mux.Vars(r)["id"], req)
+	if errors.Is(err, services.ErrScheduleNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Schedule updated successfully",
+		Data:    schedule,
+	})
+}
+
+// DeleteSchedule deletes one of the project's schedules
+// Endpoint: DELETE /api/schedules/{id}
+func (h *SchedulesHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(r.Context(), member.ProjectID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, scheduleErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Schedule deleted successfully",
+	})
+}
+
+// scheduleErrorStatus maps schedule service errors to HTTP status codes
+func scheduleErrorStatus(err error) int {
+	if errors.Is(err, services.ErrScheduleNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
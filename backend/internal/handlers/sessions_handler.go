@@ -0,0 +1,80 @@
+package handlers
+
+/**
+ * Sessions Handler
+ *
+ * Purpose: Handle HTTP requests for the caller's sessions
+ *
+ * Endpoints (all protected - require a JWT login session):
+ * - GET    /api/sessions: List where the caller is logged in
+ * - DELETE /api/sessions/{id}: Sign out one session or revoke a personal access token
+ *
+ * Each session shows its login method (password, the OpenID Connect
+ * provider, or token), device, IP and when it was last seen.
+ */
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/services"
+)
+
+type SessionsHandler struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionsHandler creates a new sessions handler instance
+func NewSessionsHandler(sessionService *services.SessionService) *SessionsHandler {
+	return &SessionsHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetSessions lists the caller's sessions; the one making the request has "current": true
+// Endpoint: GET /api/sessions
+func (h *SessionsHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+// RevokeSession signs out one of the caller's sessions
+// Revoking the current session is the same as logging out.
+// Endpoint: DELETE /api/sessions/{id}
+func (h *SessionsHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionClaims(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.sessionService.RevokeSession(r.Context(), claims.UserID, mux.Vars(r)["id"])
+	if errors.Is(err, services.ErrSessionNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Session revoked successfully",
+	})
+}
@@ -0,0 +1,273 @@
+package handlers
+
+/**
+ * Suites Handler
+ *
+ * Purpose: Handle HTTP requests for test suites and suite runs
+ *
+ * Endpoints (all protected - require JWT):
+ * - POST   /api/suites: Create a suite
+ * - GET    /api/suites: List the project's suites
+ * - GET    /api/suites/{id}: Get one of the project's suites
+ * - PUT    /api/suites/{id}: Update one of the project's suites
+ * - DELETE /api/suites/{id}: Delete one of the project's suites
+ * - POST   /api/suites/{id}/runs: Run every test of a suite
+ * - GET    /api/suites/{id}/runs: List the run history of a suite
+ * - GET    /api/suite-runs/{id}: Get a suite run with its member runs
+ * - POST   /api/suite-runs/{id}/cancel: Cancel the unfinished member runs
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
+)
+
+type SuitesHandler struct {
+	suiteService *services.SuiteService
+}
+
+// NewSuitesHandler creates a new suites handler instance
+func NewSuitesHandler(suiteService *services.SuiteService) *SuitesHandler {
+	return &SuitesHandler{
+		suiteService: suiteService,
+	}
+}
+
+// CreateSuite handles suite creation
+// Endpoint: POST /api/suites
+func (h *SuitesHandler) CreateSuite(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.SuiteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	suite, err := h.suiteService.CreateSuite(r.Context(), member.ProjectID, member.UserID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Suite created successfully",
+		Data:    suite,
+	})
+}
+
+// GetSuites lists the project's suites
+// Endpoint: GET /api/suites
+func (h *SuitesHandler) GetSuites(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	suites, err := h.suiteService.GetAllSuites(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suites retrieved successfully",
+		Data:    suites,
+	})
+}
+
+// GetSuiteByID returns one of the project's suites
+// Endpoint: GET /api/suites/{id}
+func (h *SuitesHandler) GetSuiteByID(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	suite, err := h.suiteService.GetSuiteByID(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite retrieved successfully",
+		Data:    suite,
+	})
+}
+
+// UpdateSuite applies a partial update to one of the project's suites
+// Endpoint: PUT /api/suites/{id}
+func (h *SuitesHandler) UpdateSuite(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.SuiteUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	suite, err := h.suiteService.UpdateSuite(r.Context(), member.ProjectID, mux.Vars(r)["id"], req)
+	if errors.Is(err, services.ErrSuiteNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite updated successfully",
+		Data:    suite,
+	})
+}
+
+// DeleteSuite deletes one of the project's suites
+// Endpoint: DELETE /api/suites/{id}
+func (h *SuitesHandler) DeleteSuite(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.suiteService.DeleteSuite(r.Context(), member.ProjectID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite deleted successfully",
+	})
+}
+
+// RunSuite launches one run per test of one of the project's suites
+// The request body is optional and overrides the suite's defaults:
+// {"browser": "firefox", "headless": true, "timeout": 600}
+// Endpoint: POST /api/suites/{id}/runs
+func (h *SuitesHandler) RunSuite(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.SuiteRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	suiteRun, err := h.suiteService.RunSuite(r.Context(), member.ProjectID, member.UserID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Suite run queued successfully",
+		Data:    suiteRun,
+	})
+}
+
+// GetSuiteRuns lists the run history of one of the project's suites
+// Endpoint: GET /api/suites/{id}/runs
+func (h *SuitesHandler) GetSuiteRuns(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	suiteRuns, err := h.suiteService.ListSuiteRuns(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite runs retrieved successfully",
+		Data:    suiteRuns,
+	})
+}
+
+// GetSuiteRun returns one of the project's suite runs with its member runs
+// Endpoint: GET /api/suite-runs/{id}
+func (h *SuitesHandler) GetSuiteRun(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	suiteRun, err := h.suiteService.GetSuiteRun(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite run retrieved successfully",
+		Data:    suiteRun,
+	})
+}
+
+// CancelSuiteRun cancels the unfinished member runs of one of the project's suite runs
+// Endpoint: POST /api/suite-runs/{id}/cancel
+func (h *SuitesHandler) CancelSuiteRun(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	suiteRun, err := h.suiteService.CancelSuiteRun(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, suiteErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Suite run cancelled successfully",
+		Data:    suiteRun,
+	})
+}
+
+// suiteErrorStatus maps suite service errors to HTTP status codes
+func suiteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSuiteNotFound), errors.Is(err, services.ErrSuiteRunNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTestNotFound), errors.Is(err, services.ErrInvalidRunTransition):
+		// A member test was deleted since the suite was saved, or the suite run already finished
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -1,44 +1,164 @@
 package handlers
 
+/**
+ * Tests Handler
+ *
+ * Purpose: Handle HTTP requests for test scripts
+ *
+ * Endpoints (all protected - require JWT):
+ * - POST   /api/tests: Create a test
+ * - GET    /api/tests: List the project's tests
+ * - GET    /api/tests/{id}: Get one of the project's tests
+ * - PUT    /api/tests/{id}: Update one of the project's tests
+ * - DELETE /api/tests/{id}: Delete one of the project's tests
+ */
+
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
 )
 
 type TestsHandler struct {
-	// TODO: Add service dependencies
+	testService *services.TestService
 }
 
-func NewTestsHandler() *TestsHandler {
-	return &TestsHandler{}
+// NewTestsHandler creates a new tests handler instance
+func NewTestsHandler(testService *services.TestService) *TestsHandler {
+	return &TestsHandler{
+		testService: testService,
+	}
 }
 
+// CreateTest handles test creation
+// Endpoint: POST /api/tests
 func (h *TestsHandler) CreateTest(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement test creation logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "create test endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.TestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	test, err := h.testService.CreateTest(r.Context(), member.ProjectID, member.UserID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Test created successfully",
+		Data:    test,
+	})
 }
 
+// GetTests lists all tests in the project
+// Endpoint: GET /api/tests
 func (h *TestsHandler) GetTests(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get tests logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get tests endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	tests, err := h.testService.GetAllTests(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Tests retrieved successfully",
+		Data:    tests,
+	})
 }
 
+// GetTestByID returns a single test in the project
+// Endpoint: GET /api/tests/{id}
 func (h *TestsHandler) GetTestByID(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get test by ID logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get test by id endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	test, err := h.testService.GetTestByID(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, testErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Test retrieved successfully",
+		Data:    test,
+	})
 }
 
+// UpdateTest applies a partial update to a test in the project
+// Endpoint: PUT /api/tests/{id}
 func (h *TestsHandler) UpdateTest(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement update test logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "update test endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.TestUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	test, err := h.testService.UpdateTest(r.Context(), member.ProjectID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeError(w, testErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Test updated successfully",
+		Data:    test,
+	})
 }
 
+// DeleteTest removes a test in the project
+// Endpoint: DELETE /api/tests/{id}
 func (h *TestsHandler) DeleteTest(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement delete test logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "delete test endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.testService.DeleteTest(r.Context(), member.ProjectID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, testErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Test deleted successfully",
+	})
+}
+
+// testErrorStatus maps test service errors to HTTP status codes
+func testErrorStatus(err error) int {
+	if errors.Is(err, services.ErrTestNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
 }
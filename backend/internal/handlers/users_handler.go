@@ -0,0 +1,189 @@
+package handlers
+
+/**
+ * Users Handler
+ *
+ * Purpose: Handle HTTP requests for user administration
+ *
+ * Endpoints (all protected - require users:admin):
+ * - GET    /api/users: Search and page through users
+ * - GET    /api/users/{id}: Get a user
+ * - POST   /api/users/{id}/disable: Disable an account and end its sessions
+ * - POST   /api/users/{id}/enable: Re-enable an account
+ * - POST   /api/users/{id}/reset-password: Clear the password and email a reset link
+ * - DELETE /api/users/{id}: Delete a user
+ *
+ * Roles are changed with PUT /api/users/{id}/role (see roles_handler.go).
+ */
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/services"
+)
+
+type UsersHandler struct {
+	userAdmin *services.UserAdminService
+}
+
+// NewUsersHandler creates a new users handler instance
+func NewUsersHandler(userAdmin *services.UserAdminService) *UsersHandler {
+	return &UsersHandler{
+		userAdmin: userAdmin,
+	}
+}
+
+// GetUsers returns a page of users, newest first
+// Query parameters: search (part of the username or email), role,
+// disabled (true or false), page (from 1), limit
+// Endpoint: GET /api/users
+func (h *UsersHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	query := services.UserListQuery{
+		Search: values.Get("search"),
+		Role:   values.Get("role"),
+	}
+
+	if value := values.Get("disabled"); value != "" {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "disabled must be true or false")
+			return
+		}
+		query.Disabled = &disabled
+	}
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			writeError(w, http.StatusBadRequest, "page must be a positive number")
+			return
+		}
+		query.Page = page
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := h.userAdmin.ListUsers(r.Context(), query)
+	if err != nil {
+		writeError(w, userAdminErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Users retrieved successfully",
+		Data:    page,
+	})
+}
+
+// GetUser returns a single user
+// Endpoint: GET /api/users/{id}
+func (h *UsersHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userAdmin.GetUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, userAdminErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "User retrieved successfully",
+		Data:    user,
+	})
+}
+
+// DisableUser disables an account; its sessions end at once
+// Endpoint: POST /api/users/{id}/disable
+func (h *UsersHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, true, "User disabled successfully")
+}
+
+// EnableUser re-enables a disabled account
+// Endpoint: POST /api/users/{id}/enable
+func (h *UsersHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, false, "User enabled successfully")
+}
+
+func (h *UsersHandler) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool, message string) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	user, err := h.userAdmin.SetDisabled(r.Context(), claims.UserID, mux.Vars(r)["id"], disabled)
+	if err != nil {
+		writeError(w, userAdminErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    user,
+	})
+}
+
+// ForcePasswordReset clears a user's password, ends their sessions and emails them a reset link
+// Endpoint: POST /api/users/{id}/reset-password
+func (h *UsersHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.userAdmin.ForcePasswordReset(r.Context(), claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, userAdminErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Password cleared - the user has been emailed a reset link",
+	})
+}
+
+// DeleteUser deletes a user with their sessions, tokens and memberships
+// Endpoint: DELETE /api/users/{id}
+func (h *UsersHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.userAdmin.DeleteUser(r.Context(), claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, userAdminErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "User deleted successfully",
+	})
+}
+
+// userAdminErrorStatus maps user admin service errors to HTTP status codes
+func userAdminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrOwnAccount), errors.Is(err, services.ErrInvalidRole):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrLastAdmin):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
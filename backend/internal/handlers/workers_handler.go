@@ -1,26 +1,377 @@
 package handlers
 
+/**
+ * Workers Handler
+ *
+ * Purpose: Handle HTTP requests from and about runners (workers)
+ *
+ * Endpoints for users (protected - require JWT):
+ * - GET    /api/workers: List the project's runners
+ * - GET    /api/workers/{id}: Get a single runner
+ * - DELETE /api/workers/{id}/credential: Revoke a runner's credential
+ * - GET    /api/workers/registration-tokens: List the project's registration tokens
+ * - POST   /api/workers/registration-tokens: Issue a one-time registration token
+ * - DELETE /api/workers/registration-tokens/{id}: Revoke a registration token
+ *
+ * Endpoints for runners:
+ * - POST /api/workers/register: Register with a registration token (name,
+ *   version, capabilities); returns the worker credential
+ * - POST /api/workers/{id}/heartbeat: Runner is still alive
+ * - PUT  /api/workers/{id}/status: Runner reports idle, or busy with a run
+ * - POST /api/workers/{id}/jobs/lease: Runner asks for its next job
+ * - POST /api/workers/{id}/jobs/{jobId}/nack: Runner gives a leased job back for another runner
+ *
+ * Runners are registered in, and only see the jobs of, the project their
+ * registration token was issued for. Their routes take only their own worker
+ * credential (see WorkerAuthMiddleware).
+ */
+
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
 )
 
 type WorkersHandler struct {
-	// TODO: Add service dependencies
+	workerService *services.WorkerService
+	runService    *services.RunService
+	workerAuth    *services.WorkerAuthService
+}
+
+func NewWorkersHandler(workerService *services.WorkerService, runService *services.RunService, workerAuth *services.WorkerAuthService) *WorkersHandler {
+	return &WorkersHandler{
+		workerService: workerService,
+		runService:    runService,
+		workerAuth:    workerAuth,
+	}
+}
+
+// Register exchanges a registration token for a new runner and its credential
+// Header: Authorization: Bearer topr_...
+// The credential is only shown in this response; the runner sends it as
+// "Authorization: Bearer topw_..." from then on.
+// Endpoint: POST /api/workers/register
+func (h *WorkersHandler) Register(w http.ResponseWriter, r *http.Request) {
+	registrationToken, ok := middleware.BearerToken(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Registration token required")
+		return
+	}
+
+	var req services.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	worker, credential, err := h.workerAuth.RegisterWorker(r.Context(), registrationToken, req)
+	if err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Worker registered successfully - store the credential now, it will not be shown again",
+		Data: map[string]interface{}{
+			"worker":     worker,
+			"credential": credential,
+		},
+	})
 }
 
-func NewWorkersHandler() *WorkersHandler {
-	return &WorkersHandler{}
+// CreateRegistrationToken issues a one-time token for registering a runner in the active project
+// Request body: {"name": "build-agent-3", "expires_in_hours": 24}
+// Endpoint: POST /api/workers/registration-tokens
+func (h *WorkersHandler) CreateRegistrationToken(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	var req services.RegistrationTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	token, plaintext, err := h.workerAuth.CreateRegistrationToken(r.Context(), member.ProjectID, member.UserID, req)
+	if err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		Success: true,
+		Message: "Registration token created - copy it now, it will not be shown again",
+		Data: map[string]interface{}{
+			"registration_token": token,
+			"token":              plaintext,
+		},
+	})
 }
 
+// GetRegistrationTokens lists the active project's unexpired registration tokens
+// Endpoint: GET /api/workers/registration-tokens
+func (h *WorkersHandler) GetRegistrationTokens(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	tokens, err := h.workerAuth.ListRegistrationTokens(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Registration tokens retrieved successfully",
+		Data:    tokens,
+	})
+}
+
+// RevokeRegistrationToken revokes one of the active project's registration tokens
+// Endpoint: DELETE /api/workers/registration-tokens/{id}
+func (h *WorkersHandler) RevokeRegistrationToken(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.workerAuth.RevokeRegistrationToken(r.Context(), member.ProjectID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Registration token revoked successfully",
+	})
+}
+
+// RevokeCredential revokes the credential of one of the active project's runners
+// Endpoint: DELETE /api/workers/{id}/credential
+func (h *WorkersHandler) RevokeCredential(w http.ResponseWriter, r *http.Request) {
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	if err := h.workerAuth.RevokeCredential(r.Context(), member.ProjectID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Worker credential revoked successfully",
+	})
+}
+
+// GetWorkers lists the runners registered in the active project
+// Endpoint: GET /api/workers
 func (h *WorkersHandler) GetWorkers(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get workers logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get workers endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	workers, err := h.workerService.GetWorkers(r.Context(), member.ProjectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Workers retrieved successfully",
+		Data:    workers,
+	})
 }
 
+// GetWorkerStatus returns a single runner
+// Endpoint: GET /api/workers/{id}
 func (h *WorkersHandler) GetWorkerStatus(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement get worker status logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "get worker status endpoint"})
+	member, ok := middleware.GetProjectFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Project not found in context")
+		return
+	}
+
+	worker, err := h.workerService.GetWorkerStatus(r.Context(), member.ProjectID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Worker retrieved successfully",
+		Data:    worker,
+	})
+}
+
+// Heartbeat records that a runner is still alive
+// Endpoint: POST /api/workers/{id}/heartbeat
+func (h *WorkersHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	worker, ok := pathWorker(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.workerService.Heartbeat(r.Context(), worker.ProjectID, worker.ID); err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Heartbeat recorded",
+	})
+}
+
+// UpdateStatus records a runner's idle/busy status
+// Reporting busy with a run marks that run as started; if the run was
+// cancelled or already started elsewhere the response is 409 and the
+// runner's lease on the run's job has been acknowledged
+// Endpoint: PUT /api/workers/{id}/status
+func (h *WorkersHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	worker, ok := pathWorker(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.StatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Status == models.WorkerStatusBusy && req.CurrentJob != "" {
+		if err := h.runService.StartRun(r.Context(), worker.ProjectID, req.CurrentJob, worker.ID); err != nil {
+			writeError(w, workerErrorStatus(err), err.Error())
+			return
+		}
+	}
+
+	if err := h.workerService.ReportStatus(r.Context(), worker.ProjectID, worker.ID, req); err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Worker status updated",
+	})
+}
+
+// LeaseJob hands the runner its next job, waiting up to ?wait= seconds for one
+// The wait is clamped to 0..services.MaxLeaseWait
+// Responds 204 No Content if no job became available
+// Endpoint: POST /api/workers/{id}/jobs/lease
+func (h *WorkersHandler) LeaseJob(w http.ResponseWriter, r *http.Request) {
+	worker, ok := pathWorker(w, r)
+	if !ok {
+		return
+	}
+
+	// Clamp before converting so huge values cannot overflow the duration
+	seconds, _ := strconv.Atoi(r.URL.Query().Get("wait"))
+	maxSeconds := int(services.MaxLeaseWait / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds > maxSeconds {
+		seconds = maxSeconds
+	}
+
+	job, err := h.workerService.LeaseJob(r.Context(), worker.ProjectID, worker.ID, time.Duration(seconds)*time.Second)
+	if err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+	if job == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Job leased",
+		Data:    job,
+	})
+}
+
+// NackJob gives a job the runner leased but has not started back to the queue
+// Endpoint: POST /api/workers/{id}/jobs/{jobId}/nack
+func (h *WorkersHandler) NackJob(w http.ResponseWriter, r *http.Request) {
+	worker, ok := pathWorker(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.runService.ReleaseJob(r.Context(), worker.ProjectID, worker.ID, mux.Vars(r)["jobId"]); err != nil {
+		writeError(w, workerErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Job returned to the queue",
+	})
+}
+
+// requestWorker returns the runner that authenticated the request (see WorkerAuthMiddleware)
+func requestWorker(w http.ResponseWriter, r *http.Request) (*models.Worker, bool) {
+	worker, ok := middleware.GetWorkerFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Worker not found in context")
+		return nil, false
+	}
+	return worker, true
+}
+
+// pathWorker returns the authenticated runner if it is the worker {id} of the route
+// A runner's credential only works for its own worker ID.
+func pathWorker(w http.ResponseWriter, r *http.Request) (*models.Worker, bool) {
+	worker, ok := requestWorker(w, r)
+	if !ok {
+		return nil, false
+	}
+	if mux.Vars(r)["id"] != worker.ID {
+		writeError(w, http.StatusForbidden, "Worker credentials only work for their own worker")
+		return nil, false
+	}
+	return worker, true
+}
+
+// workerErrorStatus maps worker and run service errors to HTTP status codes
+func workerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidRegistrationToken), errors.Is(err, services.ErrInvalidWorkerCredential):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrWorkerNotFound), errors.Is(err, services.ErrRunNotFound),
+		errors.Is(err, services.ErrRegistrationTokenNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidRunTransition), errors.Is(err, services.ErrJobNotLeased):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRequest):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
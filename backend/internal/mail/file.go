@@ -0,0 +1,60 @@
+package mail
+
+/**
+ * File Mailer
+ *
+ * Writes every message to <dir>/<time>-<recipient>.eml instead of sending
+ * it, so links in verification and reset emails can be opened during local
+ * development without an SMTP server.
+ */
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer that writes messages to dir, creating it if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, errors.New("mail directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes a message to the mail directory
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+
+	// Keep only characters that are safe in a file name
+	recipient := strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, msg.To)
+
+	name := msg.SentAt.UTC().Format("20060102T150405.000000000") + "-" + recipient + ".eml"
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, format(m.from, msg), 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("Mail to %s written to %s", msg.To, path)
+	return nil
+}
@@ -0,0 +1,66 @@
+package mail
+
+/**
+ * Mailer
+ *
+ * Purpose: Send transactional email (address verification, password reset)
+ *
+ * Drivers (selected with MAILER):
+ * - smtp:   SMTPMailer, any SMTP relay (STARTTLS when the server offers it)
+ * - file:   FileMailer, writes each message as an .eml file; the default, for local development
+ * - memory: MemoryMailer, keeps messages in memory for tests
+ */
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/utils"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	// Send delivers a message; it returns once the message is handed off
+	Send(ctx context.Context, msg Message) error
+}
+
+// Open creates the mailer selected by cfg.Mailer
+func Open(cfg *utils.Config) (Mailer, error) {
+	switch cfg.Mailer {
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	case "file":
+		return NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
+
+// format renders a message as RFC 5322 text
+func format(from string, msg Message) []byte {
+	return []byte("From: " + from + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + msg.Subject + "\r\n" +
+		"Date: " + msg.SentAt.Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		msg.Body + "\r\n")
+}
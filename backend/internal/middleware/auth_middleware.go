@@ -5,12 +5,18 @@ package middleware
  * 
  * Purpose: JWT authentication middleware
  * Verifies JWT tokens on protected routes
+ * Tokens whose session was revoked (logout, sign-out from the sessions
+ * list, refresh token reuse) are rejected; sessions are cached briefly
+ * Personal access tokens ("tops_...") are accepted in place of a JWT
+ * Tokens of disabled or deleted users are rejected with 403
+ * Event streams may pass the access token in the query string instead
  * 
  * Usage: Wrap protected routes with this middleware
  */
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -23,13 +29,19 @@ const UserContextKey contextKey = "user"
 
 // AuthMiddleware verifies JWT tokens
 type AuthMiddleware struct {
-	jwtService *services.JWTService
+	jwtService   *services.JWTService
+	tokenService *services.TokenService
+	patService   *services.PersonalAccessTokenService
+	userAdmin    *services.UserAdminService
 }
 
 // NewAuthMiddleware creates a new auth middleware instance
-func NewAuthMiddleware(jwtService *services.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService *services.JWTService, tokenService *services.TokenService, patService *services.PersonalAccessTokenService, userAdmin *services.UserAdminService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
+		jwtService:   jwtService,
+		tokenService: tokenService,
+		patService:   patService,
+		userAdmin:    userAdmin,
 	}
 }
 
@@ -52,19 +64,107 @@ func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 
 		tokenString := parts[1]
 
-		// Verify token
-		claims, err := m.jwtService.VerifyToken(tokenString)
-		if err != nil {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		// Personal access token (CI pipelines, scripts)
+		if services.IsPersonalAccessToken(tokenString) {
+			claims, err := m.patService.Authenticate(r.Context(), tokenString)
+			if errors.Is(err, services.ErrInvalidPersonalAccessToken) {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+				return
+			}
+
+			m.serve(w, r, next, claims)
+			return
+		}
+
+		m.authenticateJWT(w, r, next, tokenString)
+	}
+}
+
+// AuthenticateStream is Authenticate for Server-Sent Events routes
+// Browsers' EventSource cannot set headers, so without an Authorization header
+// a JWT access token is read from the access_token query parameter. Only the
+// short-lived access token is accepted there, never a personal access token.
+// The active project may likewise be selected by the project_id parameter.
+func (m *AuthMiddleware) AuthenticateStream(next http.HandlerFunc) http.HandlerFunc {
+	authenticate := m.Authenticate(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if projectID := r.URL.Query().Get("project_id"); projectID != "" && r.Header.Get(ProjectHeader) == "" {
+			r = r.Clone(r.Context())
+			r.Header.Set(ProjectHeader, projectID)
+		}
+
+		if r.Header.Get("Authorization") != "" {
+			authenticate(w, r)
+			return
+		}
+
+		tokenString := r.URL.Query().Get("access_token")
+		if tokenString == "" {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		if services.IsPersonalAccessToken(tokenString) {
+			http.Error(w, "Personal access tokens must be sent in the Authorization header", http.StatusUnauthorized)
 			return
 		}
 
-		// Add claims to context
-		ctx := context.WithValue(r.Context(), UserContextKey, claims)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		m.authenticateJWT(w, r, next, tokenString)
 	}
 }
 
+// authenticateJWT verifies a JWT access token and its session before serving the request
+func (m *AuthMiddleware) authenticateJWT(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, tokenString string) {
+	// Verify token
+	claims, err := m.jwtService.VerifyToken(tokenString)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+
+	// Reject tokens whose session was revoked before they expired
+	active, err := m.tokenService.IsSessionActive(r.Context(), claims)
+	if err != nil {
+		http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+		return
+	}
+
+	// Add claims to context
+	m.serve(w, r, next, claims)
+}
+
+// serve passes the request on with claims in its context, if their user may still use tokens
+func (m *AuthMiddleware) serve(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, claims *services.TokenClaims) {
+	active, err := m.userAdmin.IsActive(r.Context(), claims.UserID)
+	if err != nil {
+		http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Account is disabled", http.StatusForbidden)
+		return
+	}
+
+	next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+}
+
+// withClaims stores the claims in the context and attributes audit events to their user
+func withClaims(ctx context.Context, claims *services.TokenClaims) context.Context {
+	actor := services.AuditActorFromContext(ctx)
+	actor.UserID = claims.UserID
+	actor.Email = claims.Email
+	ctx = services.WithAuditActor(ctx, actor)
+
+	return context.WithValue(ctx, UserContextKey, claims)
+}
+
 // GetUserFromContext extracts user claims from request context
 func GetUserFromContext(ctx context.Context) (*services.TokenClaims, bool) {
 	claims, ok := ctx.Value(UserContextKey).(*services.TokenClaims)
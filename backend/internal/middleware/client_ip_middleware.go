@@ -0,0 +1,74 @@
+package middleware
+
+/**
+ * Client IP Middleware
+ *
+ * Purpose: Work out the address of the client that sent a request
+ *
+ * The address is the TCP peer's unless proxy headers are trusted, in which
+ * case it is the last X-Forwarded-For entry - the one added by our own
+ * reverse proxy; entries before it are set by the client and can be forged.
+ * Trust proxy headers only when every request comes through that proxy.
+ *
+ * The address and user agent also start the request's audit actor (see
+ * services.WithAuditActor).
+ *
+ * Usage: router.Use(clientIP.Handler), then GetClientIP(r.Context())
+ */
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"backend/internal/services"
+)
+
+// ClientIPContextKey is the context key for the client's IP address
+const ClientIPContextKey contextKey = "client_ip"
+
+// ClientIPMiddleware stores the client's IP address in the request context
+type ClientIPMiddleware struct {
+	trustProxyHeaders bool
+}
+
+// NewClientIPMiddleware creates a new client IP middleware instance
+func NewClientIPMiddleware(trustProxyHeaders bool) *ClientIPMiddleware {
+	return &ClientIPMiddleware{
+		trustProxyHeaders: trustProxyHeaders,
+	}
+}
+
+// Handler adds the client IP to the context of every request
+func (m *ClientIPMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := m.clientIP(r)
+		ctx := context.WithValue(r.Context(), ClientIPContextKey, ip)
+		ctx = services.WithAuditActor(ctx, services.AuditActor{IP: ip, UserAgent: r.UserAgent()})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (m *ClientIPMiddleware) clientIP(r *http.Request) string {
+	if m.trustProxyHeaders {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			entries := strings.Split(strings.Join(forwarded, ","), ",")
+			if ip := net.ParseIP(strings.TrimSpace(entries[len(entries)-1])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// GetClientIP returns the client IP stored by ClientIPMiddleware
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPContextKey).(string)
+	return ip
+}
@@ -0,0 +1,140 @@
+package middleware
+
+/**
+ * Permission Middleware
+ *
+ * Purpose: Role-based access control for protected routes
+ * Rejects requests whose user lacks a required permission with 403
+ *
+ * Usage: Wrap a handler already wrapped by AuthMiddleware.Authenticate:
+ *
+ *   authMiddleware.Authenticate(permissions.Require(models.PermTestsWrite, handler))
+ *
+ * Most permissions are checked against the user's role in the active project,
+ * selected by the X-Project-ID header (default: their personal project). The
+ * membership is stored in the request context for the handler; see
+ * GetProjectFromContext. Platform permissions (users:admin) are checked
+ * against the user's current platform role (see services.RoleService), not
+ * the possibly outdated role in the token; the claims passed on to the
+ * handler are updated to match.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+// ProjectContextKey is the context key for the caller's membership of the active project
+const ProjectContextKey contextKey = "project"
+
+// ProjectHeader selects the active project of a request
+const ProjectHeader = "X-Project-ID"
+
+// PermissionMiddleware checks permissions of authenticated users
+type PermissionMiddleware struct {
+	roleService    *services.RoleService
+	projectService *services.ProjectService
+}
+
+// NewPermissionMiddleware creates a new permission middleware instance
+func NewPermissionMiddleware(roleService *services.RoleService, projectService *services.ProjectService) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		roleService:    roleService,
+		projectService: projectService,
+	}
+}
+
+// Require only lets requests through whose user currently has permission
+func (m *PermissionMiddleware) Require(permission models.Permission, next http.HandlerFunc) http.HandlerFunc {
+	if !models.IsPlatformPermission(permission) {
+		return m.ResolveProject(func(w http.ResponseWriter, r *http.Request) {
+			claims, _ := GetUserFromContext(r.Context())
+			member, _ := GetProjectFromContext(r.Context())
+
+			// Personal access tokens are further limited to their scopes
+			if !models.RoleHasPermission(member.Role, permission) || !claims.Allows(permission) {
+				http.Error(w, "Missing permission "+string(permission), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "User not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		role, err := m.roleService.CurrentRole(r.Context(), claims.UserID)
+		if errors.Is(err, services.ErrUserNotFound) {
+			http.Error(w, "User no longer exists", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to verify permissions", http.StatusInternalServerError)
+			return
+		}
+
+		if !models.RoleHasPermission(role, permission) || !claims.Allows(permission) {
+			http.Error(w, "Missing permission "+string(permission), http.StatusForbidden)
+			return
+		}
+
+		// Pass the current role on to the handler
+		if role != claims.Role {
+			current := *claims
+			current.Role = role
+			r = r.WithContext(context.WithValue(r.Context(), UserContextKey, &current))
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// ResolveProject looks up the caller's membership of the active project
+// Requests for projects the caller is not a member of get 404, so project IDs
+// cannot be probed. Personal access tokens pinned to a project can only use it.
+func (m *PermissionMiddleware) ResolveProject(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "User not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		projectID := r.Header.Get(ProjectHeader)
+		if claims.ProjectID != "" {
+			if projectID != "" && projectID != claims.ProjectID {
+				http.Error(w, "Token is limited to another project", http.StatusForbidden)
+				return
+			}
+			projectID = claims.ProjectID
+		}
+
+		member, err := m.projectService.ResolveMember(r.Context(), claims.UserID, projectID)
+		if errors.Is(err, services.ErrProjectNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to verify project membership", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ProjectContextKey, member)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// GetProjectFromContext extracts the caller's membership of the active project from request context
+func GetProjectFromContext(ctx context.Context) (*models.ProjectMember, bool) {
+	member, ok := ctx.Value(ProjectContextKey).(*models.ProjectMember)
+	return member, ok
+}
@@ -0,0 +1,76 @@
+package middleware
+
+/**
+ * Worker Auth Middleware
+ *
+ * Purpose: Authenticate runners (workers) on the worker-only routes
+ * Only worker credentials ("topw_...") are accepted; user JWTs and personal
+ * access tokens are rejected with 401
+ *
+ * Usage: Wrap worker-only routes with this middleware; the handler gets the
+ * worker from GetWorkerFromContext
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+)
+
+// WorkerContextKey is the context key for the authenticated worker
+const WorkerContextKey contextKey = "worker"
+
+// WorkerAuthMiddleware verifies worker credentials
+type WorkerAuthMiddleware struct {
+	workerAuth *services.WorkerAuthService
+}
+
+// NewWorkerAuthMiddleware creates a new worker auth middleware instance
+func NewWorkerAuthMiddleware(workerAuth *services.WorkerAuthService) *WorkerAuthMiddleware {
+	return &WorkerAuthMiddleware{
+		workerAuth: workerAuth,
+	}
+}
+
+// Authenticate verifies the worker credential and adds the worker to the context
+func (m *WorkerAuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credential, ok := BearerToken(r)
+		if !ok || !services.IsWorkerCredential(credential) {
+			http.Error(w, "Worker credential required", http.StatusUnauthorized)
+			return
+		}
+
+		worker, err := m.workerAuth.Authenticate(r.Context(), credential)
+		if errors.Is(err, services.ErrInvalidWorkerCredential) {
+			http.Error(w, "Invalid or revoked worker credential", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to verify worker credential", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), WorkerContextKey, worker)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// GetWorkerFromContext extracts the authenticated worker from request context
+func GetWorkerFromContext(ctx context.Context) (*models.Worker, bool) {
+	worker, ok := ctx.Value(WorkerContextKey).(*models.Worker)
+	return worker, ok
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || scheme != "Bearer" || token == "" {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// AuditEvent records who did what to which object, and from where
+// Events are only ever inserted. Before and After are snapshots of the
+// target as the API shows it, so they never hold password hashes or secrets.
+type AuditEvent struct {
+	ID         string                 `json:"id" bson:"_id,omitempty"`
+	Time       time.Time              `json:"time" bson:"time"`
+	ActorID    string                 `json:"actor_id,omitempty" bson:"actor_id,omitempty"` // empty for anonymous requests and background jobs
+	ActorEmail string                 `json:"actor_email,omitempty" bson:"actor_email,omitempty"`
+	Action     string                 `json:"action" bson:"action"` // see the Audit* constants
+	TargetType string                 `json:"target_type,omitempty" bson:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	ProjectID  string                 `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	IP         string                 `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	Before     map[string]interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty" bson:"after,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"` // e.g. the login method or why it failed
+}
+
+// Audited actions
+const (
+	AuditLogin            = "auth.login"
+	AuditLoginFailed      = "auth.login_failed"
+	AuditLogout           = "auth.logout"
+	AuditSessionRevoked   = "auth.session_revoke"
+	AuditMFAEnabled       = "auth.mfa_enabled"
+	AuditMFADisabled      = "auth.mfa_disabled"
+	AuditMFAFailed        = "auth.mfa_failed"
+	AuditRecoveryCodes    = "auth.recovery_codes_regenerated"
+	AuditEmailVerified    = "auth.email_verified"
+	AuditPasswordReset    = "auth.password_reset"
+	AuditTokenCreated     = "token.create"
+	AuditTokenRevoked     = "token.revoke"
+	AuditUserCreated      = "user.create"
+	AuditPasswordChanged  = "user.password_change"
+	AuditRoleChanged      = "user.role_change"
+	AuditIdentityLinked   = "user.identity_link"
+	AuditIdentityUnlinked = "user.identity_unlink"
+	AuditUserUnlocked     = "user.unlock"
+	AuditUserDisabled     = "user.disable"
+	AuditUserEnabled      = "user.enable"
+	AuditPasswordCleared  = "user.force_password_reset"
+	AuditUserDeleted      = "user.delete"
+	AuditTestCreated      = "test.create"
+	AuditTestUpdated      = "test.update"
+	AuditTestDeleted      = "test.delete"
+
+	AuditWorkerTokenCreated      = "worker.registration_token_create"
+	AuditWorkerTokenRevoked      = "worker.registration_token_revoke"
+	AuditWorkerRegistered        = "worker.register"
+	AuditWorkerCredentialRevoked = "worker.credential_revoke"
+)
+
+// Audit target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetToken   = "token"
+	AuditTargetSession = "session"
+	AuditTargetTest    = "test"
+	AuditTargetWorker  = "worker"
+)
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Email token purposes
+const (
+	EmailTokenVerifyEmail   = "verify_email"
+	EmailTokenResetPassword = "reset_password"
+)
+
+// EmailToken backs a single-use link sent by email (address verification or password reset)
+// The link carries the token ID, its expiry and an HMAC over both and the
+// purpose; the stored record makes it single-use.
+type EmailToken struct {
+	ID        string     `json:"id" bson:"_id"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	Purpose   string     `json:"purpose" bson:"purpose"`
+	Email     string     `json:"email" bson:"email"` // address the link was sent to
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LoginFailures counts recent failed logins of one client IP, email or user
+// Keys look like "ip:203.0.113.7", "email:jane@example.com" or "user:<id>".
+type LoginFailures struct {
+	Key         string    `json:"key" bson:"_id"`
+	Failures    int       `json:"failures" bson:"failures"`
+	LockedUntil time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"` // forgotten after this
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OIDCLogin is an authorization code login in progress
+// It is created when the user is sent to the provider and used up when the
+// provider sends them back with the state.
+type OIDCLogin struct {
+	ID           string    `json:"-" bson:"_id"` // the OAuth state parameter
+	Provider     string    `json:"provider" bson:"provider"`
+	Nonce        string    `json:"-" bson:"nonce"`
+	CodeVerifier string    `json:"-" bson:"code_verifier"`     // PKCE
+	UserID       string    `json:"-" bson:"user_id,omitempty"` // set when linking the account to this user instead of logging in
+	ExpiresAt    time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PersonalAccessTokenPrefix starts every personal access token, so they are
+// easy to tell apart from JWTs and to spot in leaked logs or commits
+const PersonalAccessTokenPrefix = "tops_"
+
+// PersonalAccessToken is a named, scoped, expiring API token for CI pipelines and scripts
+// Only the SHA-256 of the token is stored; the token itself is shown once, on creation.
+type PersonalAccessToken struct {
+	ID         string       `json:"id" bson:"_id,omitempty"`
+	UserID     string       `json:"user_id" bson:"user_id"`
+	Name       string       `json:"name" bson:"name"`
+	TokenHash  string       `json:"-" bson:"token_hash"`
+	Hint       string       `json:"hint" bson:"hint"`                                 // last characters of the token, to recognise it
+	Scopes     []Permission `json:"scopes" bson:"scopes"`                             // subset of the owner's role permissions
+	ProjectID  string       `json:"project_id,omitempty" bson:"project_id,omitempty"` // if set, the only project the token can use
+	ExpiresAt  time.Time    `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP string       `json:"last_used_ip,omitempty" bson:"last_used_ip,omitempty"`
+	CreatedAt  time.Time    `json:"created_at" bson:"created_at"`
+}
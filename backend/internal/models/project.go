@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Organization roles
+const (
+	OrgRoleOwner  = "owner"  // manages members and creates projects
+	OrgRoleMember = "member" // can be added to the organization's projects
+)
+
+// Organization groups the projects of a team
+type Organization struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	Name      string    `json:"name" bson:"name"`
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// OrganizationMember gives a user a role in an organization
+type OrganizationMember struct {
+	ID             string    `json:"id" bson:"_id,omitempty"`
+	OrganizationID string    `json:"organization_id" bson:"organization_id"`
+	UserID         string    `json:"user_id" bson:"user_id"`
+	Role           string    `json:"role" bson:"role"` // owner or member
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Project is the tenant that tests, suites, runs, results, schedules and workers belong to
+// Every user has a personal project whose ID is their user ID; other projects
+// belong to an organization.
+type Project struct {
+	ID             string    `json:"id" bson:"_id,omitempty"`
+	OrganizationID string    `json:"organization_id,omitempty" bson:"organization_id,omitempty"`
+	Name           string    `json:"name" bson:"name"`
+	Personal       bool      `json:"personal" bson:"personal"`
+	CreatedBy      string    `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+}
+
+// ProjectMember gives a user a role (viewer, tester, maintainer or admin) in a project
+type ProjectMember struct {
+	ID        string    `json:"id" bson:"_id,omitempty"`
+	ProjectID string    `json:"project_id" bson:"project_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Role      string    `json:"role" bson:"role"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
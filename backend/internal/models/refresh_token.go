@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefreshToken is one refresh token of a login session (token family)
+// Only the SHA-256 of the token is stored. Each refresh uses the token up and
+// issues a new one in the same family; presenting a used token again means it
+// leaked, and the whole family is revoked.
+type RefreshToken struct {
+	ID        string     `json:"-" bson:"_id"` // hex SHA-256 of the token
+	FamilyID  string     `json:"family_id" bson:"family_id"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// RevokedToken marks an access token (by jti) or a whole token family (by family ID) as revoked
+// It is kept until every access token it could match has expired anyway.
+type RevokedToken struct {
+	ID        string    `json:"id" bson:"_id"` // jti or family ID
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
@@ -3,12 +3,18 @@ package models
 import "time"
 
 type Result struct {
-	ID             string    `json:"id" bson:"_id,omitempty"`
-	TestID         string    `json:"test_id" bson:"test_id"`
-	Status         string    `json:"status" bson:"status"` // success, failed
-	VideoPath      string    `json:"video_path" bson:"video_path"`
-	ScreenshotPath string    `json:"screenshot_path" bson:"screenshot_path"`
-	Logs           string    `json:"logs" bson:"logs"`
-	Duration       float64   `json:"duration" bson:"duration"` // in seconds
-	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	ID            string    `json:"id" bson:"_id,omitempty"`
+	RunID         string    `json:"run_id" bson:"run_id"`
+	TestID        string    `json:"test_id" bson:"test_id"`
+	ProjectID     string    `json:"project_id" bson:"project_id"`
+	UserID        string    `json:"user_id" bson:"user_id"`
+	WorkerID      string    `json:"worker_id" bson:"worker_id"`
+	Status        string    `json:"status" bson:"status"`                                     // passed, failed, error
+	VideoKey      string    `json:"video_key,omitempty" bson:"video_key,omitempty"`           // artifact store key
+	ScreenshotKey string    `json:"screenshot_key,omitempty" bson:"screenshot_key,omitempty"` // artifact store key
+	VideoURL      string    `json:"video_url,omitempty" bson:"-"`                             // short-lived download link
+	ScreenshotURL string    `json:"screenshot_url,omitempty" bson:"-"`                        // short-lived download link
+	Logs          string    `json:"logs" bson:"logs"`
+	Duration      float64   `json:"duration" bson:"duration"` // in seconds
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
 }
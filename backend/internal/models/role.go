@@ -0,0 +1,76 @@
+package models
+
+// Roles, from least to most privileged
+// A user has a role in every project they are a member of (ProjectMember.Role)
+// and one platform-wide role (User.Role), which only grants platform permissions.
+const (
+	RoleViewer     = "viewer"
+	RoleTester     = "tester"
+	RoleMaintainer = "maintainer"
+	RoleAdmin      = "admin"
+)
+
+// Permission is a single action a role may be allowed to perform
+type Permission string
+
+// Permissions checked by the API
+const (
+	PermTestsRead      Permission = "tests:read"      // view tests and suites
+	PermTestsWrite     Permission = "tests:write"     // create, edit and delete tests and suites
+	PermRunsRead       Permission = "runs:read"       // view runs, results, logs and schedules
+	PermRunsCreate     Permission = "runs:create"     // start runs and suite runs
+	PermRunsCancel     Permission = "runs:cancel"     // cancel runs and suite runs
+	PermSchedulesWrite Permission = "schedules:write" // create, edit and delete schedules
+	PermWorkersRead    Permission = "workers:read"    // view runners
+	PermWorkersManage  Permission = "workers:manage"  // issue runner registration tokens and revoke runner credentials
+	PermMembersManage  Permission = "members:manage"  // add, change and remove project members
+	PermUsersAdmin     Permission = "users:admin"     // manage users and their platform roles (platform permission)
+)
+
+// RolePermissions lists what each role may do; every role includes the one below it
+var RolePermissions = map[string][]Permission{
+	RoleViewer: {
+		PermTestsRead, PermRunsRead, PermWorkersRead,
+	},
+	RoleTester: {
+		PermTestsRead, PermRunsRead, PermWorkersRead,
+		PermTestsWrite, PermRunsCreate, PermRunsCancel, PermSchedulesWrite,
+	},
+	RoleMaintainer: {
+		PermTestsRead, PermRunsRead, PermWorkersRead,
+		PermTestsWrite, PermRunsCreate, PermRunsCancel, PermSchedulesWrite,
+		PermWorkersManage,
+	},
+	RoleAdmin: {
+		PermTestsRead, PermRunsRead, PermWorkersRead,
+		PermTestsWrite, PermRunsCreate, PermRunsCancel, PermSchedulesWrite,
+		PermWorkersManage, PermMembersManage, PermUsersAdmin,
+	},
+}
+
+// IsValidPermission reports whether permission is one the API checks
+func IsValidPermission(permission Permission) bool {
+	return RoleHasPermission(RoleAdmin, permission)
+}
+
+// IsPlatformPermission reports whether permission is checked against the
+// user's platform role rather than their role in the active project
+func IsPlatformPermission(permission Permission) bool {
+	return permission == PermUsersAdmin
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants permission; unknown roles grant nothing
+func RoleHasPermission(role string, permission Permission) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Log line levels
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// RunLogLine is one line of output appended by the runner while a run executes
+// Seq numbers the lines of a run in order starting at 1, so a client that
+// has seen line N resumes by asking for the lines after N.
+type RunLogLine struct {
+	ID        string    `json:"-" bson:"_id,omitempty"`
+	RunID     string    `json:"run_id" bson:"run_id"`
+	ProjectID string    `json:"-" bson:"project_id"`
+	UserID    string    `json:"-" bson:"user_id"`
+	Seq       int64     `json:"seq" bson:"seq"`
+	Level     string    `json:"level" bson:"level"`
+	Message   string    `json:"message" bson:"message"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"` // when the runner produced the line
+}
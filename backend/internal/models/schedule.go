@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Schedule targets
+const (
+	ScheduleTargetTest  = "test"
+	ScheduleTargetSuite = "suite"
+)
+
+// Schedule starts runs of a test or suite on a cron schedule
+// Browser, Headless and Timeout apply to test targets; a suite target uses the
+// suite's own defaults. NextFireAt is only set while the schedule is enabled.
+type Schedule struct {
+	ID         string     `json:"id" bson:"_id,omitempty"`
+	Name       string     `json:"name" bson:"name"`
+	Cron       string     `json:"cron" bson:"cron"`         // standard 5-field expression or @hourly, @daily, ...
+	Timezone   string     `json:"timezone" bson:"timezone"` // IANA name, e.g. Europe/Berlin
+	TargetType string     `json:"target_type" bson:"target_type"`
+	TargetID   string     `json:"target_id" bson:"target_id"`
+	Browser    string     `json:"browser,omitempty" bson:"browser,omitempty"`
+	Headless   bool       `json:"headless" bson:"headless"`
+	Timeout    int        `json:"timeout,omitempty" bson:"timeout,omitempty"` // in seconds
+	Enabled    bool       `json:"enabled" bson:"enabled"`
+	ProjectID  string     `json:"project_id" bson:"project_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	LastFireAt *time.Time `json:"last_fire_at,omitempty" bson:"last_fire_at,omitempty"`
+	NextFireAt *time.Time `json:"next_fire_at,omitempty" bson:"next_fire_at,omitempty"`
+	LastRunID  string     `json:"last_run_id,omitempty" bson:"last_run_id,omitempty"` // run or suite run started by the last fire
+	LastError  string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" bson:"updated_at"`
+}
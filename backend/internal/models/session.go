@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Login methods of a session
+// Sessions started through an OpenID Connect provider use the provider's name
+// (e.g. "google") as their method.
+const (
+	SessionMethodPassword = "password"
+	SessionMethodToken    = "token" // personal access tokens, listed alongside login sessions
+)
+
+// Session is a place a user is logged in: a refresh token family, or a personal access token
+// Login sessions share their ID with the token family (the "sid" claim).
+// Their record is kept until it expires, revoked ones included, so that
+// access tokens of a revoked session keep failing.
+type Session struct {
+	ID         string     `json:"id" bson:"_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	Method     string     `json:"method" bson:"method"` // see the SessionMethod* constants
+	Device     string     `json:"device" bson:"device"` // e.g. "Firefox on Windows", from the user agent
+	UserAgent  string     `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IP         string     `json:"ip,omitempty" bson:"ip,omitempty"` // where the session was last seen
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty" bson:"last_seen_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	RevokedAt  *time.Time `json:"-" bson:"revoked_at,omitempty"`
+	Current    bool       `json:"current" bson:"-"` // the session of the request
+}
+
+// Active reports whether the session can still be used at now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Suite is an ordered group of tests that are run together
+// Browser, Headless and Timeout are the defaults for every run the suite launches
+type Suite struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	Name        string    `json:"name" bson:"name"`
+	Description string    `json:"description" bson:"description"`
+	TestIDs     []string  `json:"test_ids" bson:"test_ids"`
+	Browser     string    `json:"browser" bson:"browser"`
+	Headless    bool      `json:"headless" bson:"headless"`
+	Timeout     int       `json:"timeout" bson:"timeout"` // in seconds, 0 for the run default
+	ProjectID   string    `json:"project_id" bson:"project_id"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
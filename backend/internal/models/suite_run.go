@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Suite run statuses, aggregated from the member runs
+//
+// running   - at least one member run is still queued or running
+// passed    - every member run passed
+// failed    - no member run passed
+// partial   - some member runs passed and some did not
+// cancelled - at least one member run was cancelled
+const (
+	SuiteRunStatusRunning   = "running"
+	SuiteRunStatusPassed    = "passed"
+	SuiteRunStatusFailed    = "failed"
+	SuiteRunStatusPartial   = "partial"
+	SuiteRunStatusCancelled = "cancelled"
+)
+
+// SuiteRun is a single execution of a Suite: one TestRun per member test
+// Status is stored once every member run has finished; until then it is
+// computed from the member runs whenever the suite run is read.
+type SuiteRun struct {
+	ID         string     `json:"id" bson:"_id,omitempty"`
+	SuiteID    string     `json:"suite_id" bson:"suite_id"`
+	ProjectID  string     `json:"project_id" bson:"project_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	SuiteName  string     `json:"suite_name" bson:"suite_name"`
+	RunIDs     []string   `json:"run_ids" bson:"run_ids"` // in suite order
+	Status     string     `json:"status" bson:"status"`
+	Summary    RunSummary `json:"summary" bson:"summary"`
+	QueuedAt   time.Time  `json:"queued_at" bson:"queued_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	Runs       []TestRun  `json:"runs,omitempty" bson:"-"` // member runs, only on detail reads
+}
+
+// RunSummary counts the member runs of a suite run by status
+type RunSummary struct {
+	Total     int `json:"total" bson:"total"`
+	Queued    int `json:"queued" bson:"queued"`
+	Running   int `json:"running" bson:"running"`
+	Passed    int `json:"passed" bson:"passed"`
+	Failed    int `json:"failed" bson:"failed"`
+	Error     int `json:"error" bson:"error"`
+	Cancelled int `json:"cancelled" bson:"cancelled"`
+}
+
+// Add counts one member run
+func (s *RunSummary) Add(status string) {
+	s.Total++
+	switch status {
+	case RunStatusQueued:
+		s.Queued++
+	case RunStatusRunning:
+		s.Running++
+	case RunStatusPassed:
+		s.Passed++
+	case RunStatusFailed:
+		s.Failed++
+	case RunStatusError:
+		s.Error++
+	case RunStatusCancelled:
+		s.Cancelled++
+	}
+}
+
+// Status aggregates the counted member runs into a suite run status
+func (s *RunSummary) Status() string {
+	switch {
+	case s.Queued+s.Running > 0:
+		return SuiteRunStatusRunning
+	case s.Cancelled > 0:
+		return SuiteRunStatusCancelled
+	case s.Passed == s.Total:
+		return SuiteRunStatusPassed
+	case s.Passed == 0:
+		return SuiteRunStatusFailed
+	}
+	return SuiteRunStatusPartial
+}
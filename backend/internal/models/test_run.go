@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Test run statuses
+//
+// queued -> running -> passed | failed | error
+// queued | running -> cancelled
+// queued -> error (could not be handed to a runner)
+const (
+	RunStatusQueued    = "queued"
+	RunStatusRunning   = "running"
+	RunStatusPassed    = "passed"
+	RunStatusFailed    = "failed"
+	RunStatusError     = "error"
+	RunStatusCancelled = "cancelled"
+)
+
+// TestRun is a single execution of a Test
+// Script and TestName are copied from the test when the run is created, so
+// later edits to the test never change what a past run executed.
+type TestRun struct {
+	ID         string     `json:"id" bson:"_id,omitempty"`
+	TestID     string     `json:"test_id" bson:"test_id"`
+	SuiteRunID string     `json:"suite_run_id,omitempty" bson:"suite_run_id,omitempty"` // set when launched by a suite
+	ProjectID  string     `json:"project_id" bson:"project_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	TestName   string     `json:"test_name" bson:"test_name"`
+	Script     string     `json:"script" bson:"script"`
+	Browser    string     `json:"browser" bson:"browser"`
+	Headless   bool       `json:"headless" bson:"headless"`
+	Timeout    int        `json:"timeout" bson:"timeout"` // in seconds
+	Status     string     `json:"status" bson:"status"`
+	JobID      string     `json:"job_id,omitempty" bson:"job_id,omitempty"`
+	WorkerID   string     `json:"worker_id,omitempty" bson:"worker_id,omitempty"`
+	Error      string     `json:"error,omitempty" bson:"error,omitempty"`
+	QueuedAt   time.Time  `json:"queued_at" bson:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	LogLines   int64      `json:"log_lines" bson:"log_lines"` // number of log lines appended so far
+}
+
+// IsFinished reports whether the run has reached a terminal status
+func (r *TestRun) IsFinished() bool {
+	switch r.Status {
+	case RunStatusPassed, RunStatusFailed, RunStatusError, RunStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -7,8 +7,33 @@ type User struct {
 	Username  string    `json:"username" bson:"username"`
 	Email     string    `json:"email" bson:"email"`
 	Password  string    `json:"-" bson:"password"`
-	Role      string    `json:"role" bson:"role"` // admin, tester, etc.
+	Role      string    `json:"role" bson:"role"` // admin, maintainer, tester or viewer (see role.go)
+	EmailVerified bool  `json:"email_verified" bson:"email_verified"` // set once the user proved they own Email
 	Picture   string    `json:"picture,omitempty" bson:"picture,omitempty"` // Profile picture URL (for Google OAuth)
+	TOTP      TOTP      `json:"totp" bson:"totp,omitempty"`
+	Identities []Identity `json:"identities,omitempty" bson:"identities,omitempty"` // linked login provider accounts
+	Disabled  bool       `json:"disabled" bson:"disabled,omitempty"` // disabled users cannot log in or use their tokens
+	DisabledAt *time.Time `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"`
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
+
+// Identity is a login provider account linked to a user
+// A user has at most one identity per provider; the provider's subject ("sub"
+// claim) identifies the account, the email is only what it was when linked.
+type Identity struct {
+	Provider string    `json:"provider" bson:"provider"` // OpenID Connect provider name, e.g. "google"
+	Subject  string    `json:"subject" bson:"subject"`
+	Email    string    `json:"email" bson:"email"`
+	LinkedAt time.Time `json:"linked_at" bson:"linked_at"`
+}
+
+// TOTP is a user's authenticator app second factor
+// Enrolling stores a new secret; it is only required at login once the
+// user confirmed it with a code (Enabled).
+type TOTP struct {
+	Enabled       bool     `json:"enabled" bson:"enabled"`
+	Secret        string   `json:"-" bson:"secret,omitempty"`         // AES-GCM encrypted, see services/mfa_service.go
+	LastStep      int64    `json:"-" bson:"last_step,omitempty"`      // time step of the last accepted code, so codes cannot be replayed
+	RecoveryCodes []string `json:"-" bson:"recovery_codes,omitempty"` // SHA-256 hashes of the unused recovery codes
+}
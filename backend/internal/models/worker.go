@@ -2,11 +2,26 @@ package models
 
 import "time"
 
+// Worker statuses
+const (
+	WorkerStatusIdle    = "idle"
+	WorkerStatusBusy    = "busy"
+	WorkerStatusOffline = "offline"
+)
+
 type Worker struct {
-	ID         string    `json:"id" bson:"_id,omitempty"`
-	Name       string    `json:"name" bson:"name"`
-	Status     string    `json:"status" bson:"status"` // idle, busy, offline
-	CurrentJob string    `json:"current_job" bson:"current_job"`
-	LastPing   time.Time `json:"last_ping" bson:"last_ping"`
-	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	ID           string    `json:"id" bson:"_id,omitempty"`
+	Name         string    `json:"name" bson:"name"`
+	Version      string    `json:"version" bson:"version"`
+	Capabilities []string  `json:"capabilities" bson:"capabilities"` // e.g. chrome, firefox
+	ProjectID    string    `json:"project_id" bson:"project_id"`     // project whose runs the worker executes
+	UserID       string    `json:"user_id" bson:"user_id"`           // user who issued the registration token
+	Status       string    `json:"status" bson:"status"`             // idle, busy, offline
+	CurrentJob   string    `json:"current_job" bson:"current_job"`   // ID of the run being executed
+	LastPing     time.Time `json:"last_ping" bson:"last_ping"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+
+	// CredentialHash is the SHA-256 of the worker's credential; empty once revoked
+	CredentialHash string `json:"-" bson:"credential_hash,omitempty"`
+	CredentialHint string `json:"credential_hint,omitempty" bson:"credential_hint,omitempty"` // last characters of the credential
 }
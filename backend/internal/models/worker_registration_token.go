@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Prefixes of the secrets runners hold, so they are easy to tell apart from
+// user tokens and to spot in leaked logs
+const (
+	WorkerRegistrationTokenPrefix = "topr_"
+	WorkerCredentialPrefix        = "topw_"
+)
+
+// WorkerRegistrationToken lets one runner register itself in a project
+// An admin issues it; the runner exchanges it once for a worker credential.
+// Only the SHA-256 of the token is stored; the token itself is shown once, on creation.
+type WorkerRegistrationToken struct {
+	ID        string     `json:"id" bson:"_id,omitempty"`
+	ProjectID string     `json:"project_id" bson:"project_id"`
+	UserID    string     `json:"user_id" bson:"user_id"` // admin who issued it
+	Name      string     `json:"name" bson:"name"`       // e.g. the machine the runner is for
+	TokenHash string     `json:"-" bson:"token_hash"`
+	Hint      string     `json:"hint" bson:"hint"` // last characters of the token, to recognise it
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	WorkerID  string     `json:"worker_id,omitempty" bson:"worker_id,omitempty"` // the worker that registered with it
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,238 @@
+package oidc
+
+/**
+ * OpenID Connect Login Providers
+ *
+ * Purpose: Verify users with external identity providers (Google, GitLab,
+ * Keycloak, ...)
+ *
+ * Each provider is configured with OIDC_<NAME>_* (see utils.OIDCProviderConfig)
+ * and supports two flows:
+ * - ID token: the frontend signs in with the provider itself (e.g. the
+ *   Google sign-in button) and hands over the ID token
+ * - Authorization code with PKCE: the user is redirected to the provider and
+ *   back to the frontend with a code, which is exchanged here
+ *
+ * Provider metadata is discovered from <issuer>/.well-known/openid-configuration
+ * on first use and kept; signing keys come from the provider's JWKS and are
+ * cached, refetched when a token names an unknown key. ID tokens must be
+ * issued by the configured issuer for the configured client ID.
+ *
+ * cmd/oidc-stub is a local stand-in provider for development.
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"backend/internal/utils"
+)
+
+// ErrUnknownProvider is returned for provider names that are not configured
+var ErrUnknownProvider = errors.New("unknown login provider")
+
+// ErrInvalidIDToken is returned for ID tokens that fail verification
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Identity is what a provider asserts about a user, mapped from ID token claims
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Registry holds the configured providers
+type Registry struct {
+	providers map[string]*Provider
+	names     []string
+}
+
+// Open creates the providers configured in cfg.OIDCProviders
+// Nothing is fetched yet; discovery happens on first use.
+func Open(cfg *utils.Config) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]*Provider)}
+
+	for _, providerCfg := range cfg.OIDCProviders {
+		providerCfg = withDefaults(providerCfg, strings.TrimRight(cfg.AppBaseURL, "/"))
+		if providerCfg.IssuerURL == "" {
+			return nil, fmt.Errorf("OIDC provider %q: issuer is required", providerCfg.Name)
+		}
+		if providerCfg.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q: client ID is required", providerCfg.Name)
+		}
+		if _, ok := registry.providers[providerCfg.Name]; ok {
+			return nil, fmt.Errorf("OIDC provider %q is configured twice", providerCfg.Name)
+		}
+
+		registry.providers[providerCfg.Name] = &Provider{cfg: providerCfg}
+		registry.names = append(registry.names, providerCfg.Name)
+	}
+
+	return registry, nil
+}
+
+// Get returns the provider called name
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// List returns the providers in configuration order
+func (r *Registry) List() []*Provider {
+	providers := make([]*Provider, 0, len(r.names))
+	for _, name := range r.names {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
+
+// Provider is one OpenID Connect provider
+type Provider struct {
+	cfg utils.OIDCProviderConfig
+
+	mu       sync.Mutex
+	verifier *gooidc.IDTokenVerifier
+	oauth2   *oauth2.Config
+}
+
+// Name returns the provider's configured name
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// DisplayName returns the name shown to users
+func (p *Provider) DisplayName() string {
+	return p.cfg.DisplayName
+}
+
+// AuthCodeURL returns the provider URL that starts the authorization code flow
+// state and nonce are echoed back; verifier is the PKCE code verifier.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	return p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems an authorization code and verifies the ID token it returns
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(p.clientContext(ctx), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+
+	return p.verify(ctx, rawIDToken, nonce)
+}
+
+// VerifyIDToken verifies an ID token the client obtained from the provider itself
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.verify(ctx, rawIDToken, "")
+}
+
+// verify checks an ID token's signature, issuer, audience, expiry and
+// (if nonce is set) nonce, and maps its claims
+func (p *Provider) verify(ctx context.Context, rawIDToken, nonce string) (*Identity, error) {
+	idToken, err := p.verifier.Verify(p.clientContext(ctx), rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if nonce != "" && idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       idToken.Subject,
+		Email:         stringClaim(claims, p.cfg.EmailClaim),
+		EmailVerified: boolClaim(claims, p.cfg.EmailVerifiedClaim),
+		Name:          stringClaim(claims, p.cfg.NameClaim),
+		Picture:       stringClaim(claims, p.cfg.PictureClaim),
+	}, nil
+}
+
+// discover fetches the provider metadata once
+// A failed discovery is retried on the next call.
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil {
+		return nil
+	}
+
+	// Signing keys are fetched later with the same HTTP client
+	provider, err := gooidc.NewProvider(p.clientContext(ctx), p.cfg.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("discovery of %s failed: %w", p.cfg.Name, err)
+	}
+
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+	}
+
+	return nil
+}
+
+// clientContext makes go-oidc and oauth2 use httpClient for requests in ctx
+func (p *Provider) clientContext(ctx context.Context) context.Context {
+	return gooidc.ClientContext(ctx, httpClient)
+}
+
+// httpClient talks to the providers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// stringClaim returns a string claim, or "" if it is missing or not a string
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// boolClaim returns a boolean claim; some providers send "true" as a string
+func boolClaim(claims map[string]interface{}, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
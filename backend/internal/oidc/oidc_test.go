@@ -0,0 +1,246 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"backend/internal/oidc/oidctest"
+	"backend/internal/utils"
+)
+
+// startProvider starts a stand-in provider and returns it with the Provider configured for it
+func startProvider(t *testing.T, providerCfg utils.OIDCProviderConfig) (*oidctest.Provider, *Provider) {
+	t.Helper()
+
+	stub, server, err := oidctest.NewServer("testops")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	providerCfg.Name = "stub"
+	providerCfg.IssuerURL = stub.Issuer
+	providerCfg.ClientID = stub.ClientID
+	registry, err := Open(&utils.Config{
+		AppBaseURL:    "http://app.example.com",
+		OIDCProviders: []utils.OIDCProviderConfig{providerCfg},
+	})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	provider, err := registry.Get("stub")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	return stub, provider
+}
+
+func TestVerifyIDTokenMapsClaims(t *testing.T) {
+	stub, provider := startProvider(t, utils.OIDCProviderConfig{})
+	stub.Email = "alice@example.com"
+	stub.Name = "Alice"
+
+	claims := stub.Claims("")
+	claims["picture"] = "https://example.com/alice.png"
+	idToken, err := stub.SignIDToken(claims)
+	if err != nil {
+		t.Fatalf("SignIDToken: %v", err)
+	}
+
+	identity, err := provider.VerifyIDToken(context.Background(), idToken)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	want := Identity{
+		Provider:      "stub",
+		Subject:       "stub|alice@example.com",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+		Picture:       "https://example.com/alice.png",
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestVerifyIDTokenCustomClaims(t *testing.T) {
+	stub, provider := startProvider(t, utils.OIDCProviderConfig{
+		EmailClaim:         "mail",
+		EmailVerifiedClaim: "mail_verified",
+		NameClaim:          "preferred_username",
+	})
+
+	claims := stub.Claims("")
+	claims["mail"] = "bob@example.com"
+	claims["mail_verified"] = "true" // some providers send booleans as strings
+	claims["preferred_username"] = "bob"
+	idToken, err := stub.SignIDToken(claims)
+	if err != nil {
+		t.Fatalf("SignIDToken: %v", err)
+	}
+
+	identity, err := provider.VerifyIDToken(context.Background(), idToken)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if identity.Email != "bob@example.com" || !identity.EmailVerified || identity.Name != "bob" {
+		t.Errorf("identity = %+v, want bob@example.com, verified, named bob", *identity)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	stub, provider := startProvider(t, utils.OIDCProviderConfig{})
+	other, err := oidctest.NewProvider(stub.Issuer, stub.ClientID)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		sign func() (string, error)
+	}{
+		{"wrong issuer", func() (string, error) {
+			claims := stub.Claims("")
+			claims["iss"] = "https://evil.example.com"
+			return stub.SignIDToken(claims)
+		}},
+		{"wrong audience", func() (string, error) {
+			claims := stub.Claims("")
+			claims["aud"] = "another-client"
+			return stub.SignIDToken(claims)
+		}},
+		{"expired", func() (string, error) {
+			claims := stub.Claims("")
+			claims["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return stub.SignIDToken(claims)
+		}},
+		{"signed by another key", func() (string, error) {
+			return other.SignIDToken(stub.Claims(""))
+		}},
+		{"malformed", func() (string, error) {
+			return "not-a-token", nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idToken, err := tt.sign()
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			if _, err := provider.VerifyIDToken(context.Background(), idToken); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestProviderCachesDiscoveryAndKeys(t *testing.T) {
+	stub, provider := startProvider(t, utils.OIDCProviderConfig{})
+	ctx := context.Background()
+
+	verify := func() {
+		t.Helper()
+		idToken, err := stub.IDToken("")
+		if err != nil {
+			t.Fatalf("IDToken: %v", err)
+		}
+		if _, err := provider.VerifyIDToken(ctx, idToken); err != nil {
+			t.Fatalf("VerifyIDToken: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		verify()
+	}
+	if n := stub.Requests("/.well-known/openid-configuration"); n != 1 {
+		t.Errorf("discovery requests = %d, want 1", n)
+	}
+	if n := stub.Requests("/jwks"); n != 1 {
+		t.Errorf("JWKS requests = %d, want 1", n)
+	}
+
+	// A token signed with a new key makes the provider refetch its keys once
+	if err := stub.RotateKey(); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	verify()
+	verify()
+	if n := stub.Requests("/.well-known/openid-configuration"); n != 1 {
+		t.Errorf("discovery requests after key rotation = %d, want 1", n)
+	}
+	if n := stub.Requests("/jwks"); n != 2 {
+		t.Errorf("JWKS requests after key rotation = %d, want 2", n)
+	}
+}
+
+func TestExchangeChecksNonce(t *testing.T) {
+	_, provider := startProvider(t, utils.OIDCProviderConfig{})
+	ctx := context.Background()
+
+	// authorize runs the authorization code flow up to the redirect back and returns the code
+	authorize := func(nonce, verifier string) string {
+		t.Helper()
+		authURL, err := provider.AuthCodeURL(ctx, "state", nonce, verifier)
+		if err != nil {
+			t.Fatalf("AuthCodeURL: %v", err)
+		}
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		resp, err := client.Get(authURL)
+		if err != nil {
+			t.Fatalf("authorize: %v", err)
+		}
+		resp.Body.Close()
+
+		location, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil || location.Query().Get("code") == "" {
+			t.Fatalf("authorize redirected to %q, want a code", resp.Header.Get("Location"))
+		}
+		if state := location.Query().Get("state"); state != "state" {
+			t.Errorf("state = %q, want it echoed", state)
+		}
+		return location.Query().Get("code")
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	identity, err := provider.Exchange(ctx, authorize("nonce-1", verifier), verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if identity.Email != "dev@example.com" {
+		t.Errorf("identity email = %q, want dev@example.com", identity.Email)
+	}
+
+	verifier = oauth2.GenerateVerifier()
+	if _, err := provider.Exchange(ctx, authorize("nonce-2", verifier), verifier, "nonce-1"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Exchange with another login's nonce = %v, want ErrInvalidIDToken", err)
+	}
+
+	// The token endpoint checks PKCE
+	code := authorize("nonce-3", oauth2.GenerateVerifier())
+	if _, err := provider.Exchange(ctx, code, oauth2.GenerateVerifier(), "nonce-3"); err == nil {
+		t.Error("Exchange with the wrong code verifier succeeded")
+	}
+}
+
+func TestRegistryGetUnknownProvider(t *testing.T) {
+	registry, err := Open(&utils.Config{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := registry.Get("stub"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Get = %v, want ErrUnknownProvider", err)
+	}
+}
@@ -0,0 +1,298 @@
+package oidctest
+
+/**
+ * OIDC Test Provider
+ *
+ * Purpose: A stand-in OpenID Connect provider for tests and local
+ * development (see cmd/oidc-stub), so OIDC logins work without Google,
+ * GitLab or Keycloak
+ *
+ * It serves discovery, a JWKS, an authorization endpoint that signs in a
+ * fixed user without asking, and a token endpoint that checks PKCE. ID
+ * tokens are signed with an RSA key generated when the provider is created;
+ * RotateKey replaces it. Tests can sign ID tokens with any claims and count
+ * the requests the provider served.
+ *
+ * GET /id-token mints an ID token directly, for the ID token flow.
+ *
+ * For development only: anyone who can reach it can log in as the user.
+ */
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider is a stand-in OpenID Connect provider
+// Set the user fields before it serves requests.
+type Provider struct {
+	Issuer        string
+	ClientID      string
+	Email         string
+	Name          string
+	EmailVerified bool
+
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	key      *rsa.PrivateKey
+	keyID    string
+	keys     int
+	codes    map[string]authorization
+	requests map[string]int
+}
+
+// authorization is an issued, not yet redeemed authorization code
+type authorization struct {
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// NewProvider creates a provider for issuer that issues ID tokens for clientID
+// It signs in dev@example.com, with a verified email, until told otherwise.
+func NewProvider(issuer, clientID string) (*Provider, error) {
+	p := &Provider{
+		Issuer:        issuer,
+		ClientID:      clientID,
+		Email:         "dev@example.com",
+		Name:          "Dev User",
+		EmailVerified: true,
+		mux:           http.NewServeMux(),
+		codes:         make(map[string]authorization),
+		requests:      make(map[string]int),
+	}
+	if err := p.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	p.mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	p.mux.HandleFunc("/jwks", p.jwks)
+	p.mux.HandleFunc("/authorize", p.authorize)
+	p.mux.HandleFunc("/token", p.token)
+	p.mux.HandleFunc("/id-token", p.idTokenHandler)
+
+	return p, nil
+}
+
+// NewServer starts a provider for clientID on a local HTTP server, which is its issuer
+// The caller closes the server.
+func NewServer(clientID string) (*Provider, *httptest.Server, error) {
+	server := httptest.NewUnstartedServer(nil)
+	provider, err := NewProvider("http://"+server.Listener.Addr().String(), clientID)
+	if err != nil {
+		server.Close()
+		return nil, nil, err
+	}
+
+	server.Config.Handler = provider
+	server.Start()
+	return provider, server, nil
+}
+
+// ServeHTTP serves the provider's endpoints
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.requests[r.URL.Path]++
+	p.mu.Unlock()
+
+	p.mux.ServeHTTP(w, r)
+}
+
+// Requests returns how many requests for path the provider served
+func (p *Provider) Requests(path string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.requests[path]
+}
+
+// RotateKey replaces the signing key; the JWKS only lists the new one
+func (p *Provider) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keys++
+	p.key = key
+	p.keyID = "oidc-stub-" + strconv.Itoa(p.keys)
+	return nil
+}
+
+// Claims returns the claims of an ID token for the configured user, valid for an hour
+func (p *Provider) Claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            "stub|" + p.Email,
+		"aud":            p.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          p.Email,
+		"email_verified": p.EmailVerified,
+		"name":           p.Name,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	return claims
+}
+
+// IDToken signs an ID token for the configured user
+func (p *Provider) IDToken(nonce string) (string, error) {
+	return p.SignIDToken(p.Claims(nonce))
+}
+
+// SignIDToken signs an ID token with any claims, using the current key
+func (p *Provider) SignIDToken(claims jwt.MapClaims) (string, error) {
+	p.mu.Lock()
+	key, keyID := p.key, p.keyID
+	p.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	public, keyID := p.key.PublicKey, p.keyID
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize signs the user in without asking and redirects back with a code
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != p.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		nonce:         query.Get("nonce"),
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token redeems an authorization code for an ID token
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	p.mu.Lock()
+	auth, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || time.Now().After(auth.expiresAt):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case r.PostForm.Get("redirect_uri") != auth.redirectURI:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri mismatch"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	idToken, err := p.IDToken(auth.nonce)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// idTokenHandler mints an ID token without a login, for the ID token flow
+func (p *Provider) idTokenHandler(w http.ResponseWriter, r *http.Request) {
+	idToken, err := p.IDToken(r.URL.Query().Get("nonce"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
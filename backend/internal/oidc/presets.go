@@ -0,0 +1,45 @@
+package oidc
+
+import "backend/internal/utils"
+
+// presets fill in well-known providers, so configuring them only takes a client ID
+// Keycloak has no preset: its issuer is <keycloak>/realms/<realm>.
+var presets = map[string]utils.OIDCProviderConfig{
+	"google": {
+		DisplayName: "Google",
+		IssuerURL:   "https://accounts.google.com",
+		// OAuth client of the frontend's Google sign-in button
+		ClientID: "39841607600-gf2herbf5t72lq15bpn8bru8u0hlfiug.apps.googleusercontent.com",
+	},
+	"gitlab": {
+		DisplayName: "GitLab",
+		IssuerURL:   "https://gitlab.com",
+	},
+}
+
+// withDefaults returns cfg with unset fields taken from its preset and the generic defaults
+func withDefaults(cfg utils.OIDCProviderConfig, appBaseURL string) utils.OIDCProviderConfig {
+	preset := presets[cfg.Name]
+	fill := func(value *string, fallbacks ...string) {
+		for _, fallback := range fallbacks {
+			if *value != "" {
+				return
+			}
+			*value = fallback
+		}
+	}
+
+	fill(&cfg.DisplayName, preset.DisplayName, cfg.Name)
+	fill(&cfg.IssuerURL, preset.IssuerURL)
+	fill(&cfg.ClientID, preset.ClientID)
+	fill(&cfg.RedirectURL, appBaseURL+"/auth/oidc/"+cfg.Name)
+	fill(&cfg.EmailClaim, preset.EmailClaim, "email")
+	fill(&cfg.EmailVerifiedClaim, preset.EmailVerifiedClaim, "email_verified")
+	fill(&cfg.NameClaim, preset.NameClaim, "name")
+	fill(&cfg.PictureClaim, preset.PictureClaim, "picture")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return cfg
+}
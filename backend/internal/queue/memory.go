@@ -0,0 +1,206 @@
+package queue
+
+/**
+ * In-Memory Job Queue
+ *
+ * Purpose: JobQueue for unit tests and single-binary dev mode
+ *
+ * Jobs live only in this process and are lost on restart. Expired leases
+ * are reclaimed lazily on the next Lease or Depth call.
+ */
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryLease struct {
+	job      *Job
+	workerID string
+	until    time.Time
+}
+
+type MemoryQueue struct {
+	mu                sync.Mutex
+	visibilityTimeout time.Duration
+	maxAttempts       int
+	pending           []*Job
+	leased            map[string]*memoryLease
+	dead              []*Job
+}
+
+// NewMemoryQueue creates an empty in-process queue
+// A job delivered maxAttempts times without an ack is dead-lettered
+func NewMemoryQueue(visibilityTimeout time.Duration, maxAttempts int) *MemoryQueue {
+	return &MemoryQueue{
+		visibilityTimeout: visibilityTimeout,
+		maxAttempts:       maxAttempts,
+		leased:            make(map[string]*memoryLease),
+	}
+}
+
+// Enqueue adds a payload to the end of the queue and returns the new job ID
+func (q *MemoryQueue) Enqueue(ctx context.Context, payload interface{}) (string, error) {
+	job, err := newJob(primitive.NewObjectID().Hex(), payload)
+	if err != nil {
+		return "", err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, job)
+	return job.ID, nil
+}
+
+// Lease hands the next job to workerID, waiting up to wait for one to arrive
+func (q *MemoryQueue) Lease(ctx context.Context, workerID string, wait time.Duration) (*Job, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		if job := q.tryLease(workerID); job != nil {
+			return job, nil
+		}
+		if ok, err := waitOrDone(ctx, deadline); !ok {
+			return nil, err
+		}
+	}
+}
+
+func (q *MemoryQueue) tryLease(workerID string) *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reclaimExpired()
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	job := q.pending[0]
+	q.pending = q.pending[1:]
+	q.leased[job.ID] = &memoryLease{
+		job:      job,
+		workerID: workerID,
+		until:    time.Now().Add(q.visibilityTimeout),
+	}
+
+	leased := *job
+	return &leased
+}
+
+// Ack marks a leased job as done so it is never delivered again
+func (q *MemoryQueue) Ack(ctx context.Context, workerID, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lease, ok := q.leased[jobID]
+	if !ok || lease.workerID != workerID {
+		return ErrJobNotLeased
+	}
+
+	delete(q.leased, jobID)
+	return nil
+}
+
+// Nack gives a leased job back so it is delivered again right away
+func (q *MemoryQueue) Nack(ctx context.Context, workerID, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lease, ok := q.leased[jobID]
+	if !ok || lease.workerID != workerID {
+		return ErrJobNotLeased
+	}
+
+	delete(q.leased, jobID)
+	if !q.requeue(lease.job) {
+		return ErrJobDeadLettered
+	}
+	return nil
+}
+
+// Extend renews workerID's lease on a job for another visibility timeout
+func (q *MemoryQueue) Extend(ctx context.Context, workerID, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lease, ok := q.leased[jobID]
+	if !ok || lease.workerID != workerID {
+		return ErrJobNotLeased
+	}
+
+	lease.until = time.Now().Add(q.visibilityTimeout)
+	return nil
+}
+
+// Remove deletes a pending or leased job, whoever holds the lease, so it is never delivered again
+func (q *MemoryQueue) Remove(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.leased[jobID]; ok {
+		delete(q.leased, jobID)
+		return nil
+	}
+	for i, job := range q.pending {
+		if job.ID == jobID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrJobNotFound
+}
+
+// Depth returns the number of jobs waiting to be leased
+func (q *MemoryQueue) Depth(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reclaimExpired()
+	return int64(len(q.pending)), nil
+}
+
+// DeadDepth returns the number of dead-lettered jobs
+func (q *MemoryQueue) DeadDepth(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reclaimExpired()
+	return int64(len(q.dead)), nil
+}
+
+// Close is a no-op; the queue holds no external resources
+func (q *MemoryQueue) Close(ctx context.Context) error {
+	return nil
+}
+
+// reclaimExpired returns jobs whose lease has run out to the pending list
+// Callers must hold q.mu
+func (q *MemoryQueue) reclaimExpired() {
+	now := time.Now()
+	for id, lease := range q.leased {
+		if now.After(lease.until) {
+			delete(q.leased, id)
+			q.requeue(lease.job)
+		}
+	}
+}
+
+// requeue puts a previously delivered job at the front of the pending list,
+// or in the dead-letter list once it has used up its attempts
+// Returns false if the job was dead-lettered. Callers must hold q.mu
+func (q *MemoryQueue) requeue(job *Job) bool {
+	job.Attempts++
+	if job.Attempts >= q.maxAttempts {
+		log.Printf("Queue: job %s dead-lettered after %d deliveries", job.ID, job.Attempts)
+		q.dead = append(q.dead, job)
+		return false
+	}
+	q.pending = append([]*Job{job}, q.pending...)
+	return true
+}
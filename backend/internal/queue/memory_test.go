@@ -0,0 +1,12 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	testJobQueue(t, func(t *testing.T, visibilityTimeout time.Duration, maxAttempts int) JobQueue {
+		return NewMemoryQueue(visibilityTimeout, maxAttempts)
+	})
+}
@@ -0,0 +1,299 @@
+package queue
+
+/**
+ * MongoDB Job Queue
+ *
+ * Purpose: JobQueue for deployments that don't run Redis
+ *
+ * All queues share the job_queue collection. A job is leased with a single
+ * findOneAndUpdate that claims either a pending job or one whose lease has
+ * expired, so concurrent backends and workers never receive the same lease.
+ * Jobs that used up their attempts stay in the collection with status "dead".
+ */
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	jobStatusPending = "pending"
+	jobStatusLeased  = "leased"
+	jobStatusDead    = "dead"
+)
+
+// mongoJob is the job_queue document layout
+type mongoJob struct {
+	ID         string    `bson:"_id"`
+	Queue      string    `bson:"queue"`
+	Payload    string    `bson:"payload"`
+	Status     string    `bson:"status"`
+	WorkerID   string    `bson:"worker_id,omitempty"`
+	LeaseUntil time.Time `bson:"lease_until,omitempty"`
+	Attempts   int       `bson:"attempts"`
+	EnqueuedAt time.Time `bson:"enqueued_at"`
+}
+
+type MongoQueue struct {
+	collection        *mongo.Collection
+	name              string
+	visibilityTimeout time.Duration
+	maxAttempts       int
+}
+
+// NewMongoQueue creates a queue stored in the job_queue collection and ensures its index
+// A job delivered maxAttempts times without an ack is dead-lettered
+func NewMongoQueue(ctx context.Context, db *mongo.Database, name string, visibilityTimeout time.Duration, maxAttempts int) (*MongoQueue, error) {
+	collection := db.Collection("job_queue")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "queue", Value: 1}, {Key: "status", Value: 1}, {Key: "enqueued_at", Value: 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoQueue{
+		collection:        collection,
+		name:              name,
+		visibilityTimeout: visibilityTimeout,
+		maxAttempts:       maxAttempts,
+	}, nil
+}
+
+// Enqueue adds a payload to the end of the queue and returns the new job ID
+func (q *MongoQueue) Enqueue(ctx context.Context, payload interface{}) (string, error) {
+	job, err := newJob(primitive.NewObjectID().Hex(), payload)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = q.collection.InsertOne(ctx, mongoJob{
+		ID:         job.ID,
+		Queue:      q.name,
+		Payload:    string(job.Payload),
+		Status:     jobStatusPending,
+		EnqueuedAt: job.EnqueuedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// Lease hands the next job to workerID, waiting up to wait for one to arrive
+func (q *MongoQueue) Lease(ctx context.Context, workerID string, wait time.Duration) (*Job, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		job, err := q.tryLease(ctx, workerID)
+		if job != nil || err != nil {
+			return job, err
+		}
+		if ok, err := waitOrDone(ctx, deadline); !ok {
+			return nil, err
+		}
+	}
+}
+
+func (q *MongoQueue) tryLease(ctx context.Context, workerID string) (*Job, error) {
+	now := time.Now()
+
+	if err := q.deadLetterExpired(ctx, now); err != nil {
+		return nil, err
+	}
+
+	// Claim a pending job, or take over one whose lease ran out
+	filter := bson.M{"queue": q.name, "$or": q.leasable(now)}
+
+	// Pipeline update so an expired lease counts as a failed attempt
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "attempts", Value: bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$status", jobStatusLeased}},
+			bson.M{"$add": bson.A{"$attempts", 1}},
+			"$attempts",
+		}}},
+		{Key: "status", Value: jobStatusLeased},
+		{Key: "worker_id", Value: workerID},
+		{Key: "lease_until", Value: now.Add(q.visibilityTimeout)},
+	}}}}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "enqueued_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var doc mongoJob
+	err := q.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		ID:         doc.ID,
+		Payload:    []byte(doc.Payload),
+		EnqueuedAt: doc.EnqueuedAt,
+		Attempts:   doc.Attempts,
+	}, nil
+}
+
+// Ack marks a leased job as done so it is never delivered again
+func (q *MongoQueue) Ack(ctx context.Context, workerID, jobID string) error {
+	result, err := q.collection.DeleteOne(ctx, q.leaseFilter(workerID, jobID))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrJobNotLeased
+	}
+
+	return nil
+}
+
+// Nack gives a leased job back so it is delivered again right away
+func (q *MongoQueue) Nack(ctx context.Context, workerID, jobID string) error {
+	// Pipeline update so the job is dead-lettered once it used up its attempts
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "attempts", Value: bson.M{"$add": bson.A{"$attempts", 1}}},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$attempts", q.maxAttempts}},
+				jobStatusDead,
+				jobStatusPending,
+			}}},
+		}}},
+		{{Key: "$unset", Value: bson.A{"worker_id", "lease_until"}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc mongoJob
+	err := q.collection.FindOneAndUpdate(ctx, q.leaseFilter(workerID, jobID), update, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrJobNotLeased
+	}
+	if err != nil {
+		return err
+	}
+	if doc.Status == jobStatusDead {
+		log.Printf("Queue %s: job %s dead-lettered after %d deliveries", q.name, jobID, doc.Attempts)
+		return ErrJobDeadLettered
+	}
+
+	return nil
+}
+
+// Extend renews workerID's lease on a job for another visibility timeout
+func (q *MongoQueue) Extend(ctx context.Context, workerID, jobID string) error {
+	update := bson.M{"$set": bson.M{"lease_until": time.Now().Add(q.visibilityTimeout)}}
+
+	result, err := q.collection.UpdateOne(ctx, q.leaseFilter(workerID, jobID), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrJobNotLeased
+	}
+
+	return nil
+}
+
+// Remove deletes a pending or leased job, whoever holds the lease, so it is never delivered again
+func (q *MongoQueue) Remove(ctx context.Context, jobID string) error {
+	filter := bson.M{
+		"_id":    jobID,
+		"queue":  q.name,
+		"status": bson.M{"$in": bson.A{jobStatusPending, jobStatusLeased}},
+	}
+
+	result, err := q.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// Depth returns the number of jobs waiting to be leased, including expired leases
+func (q *MongoQueue) Depth(ctx context.Context) (int64, error) {
+	filter := bson.M{"queue": q.name, "$or": q.leasable(time.Now())}
+
+	return q.collection.CountDocuments(ctx, filter)
+}
+
+// DeadDepth returns the number of dead-lettered jobs, including expired leases on their last attempt
+func (q *MongoQueue) DeadDepth(ctx context.Context) (int64, error) {
+	if err := q.deadLetterExpired(ctx, time.Now()); err != nil {
+		return 0, err
+	}
+
+	return q.collection.CountDocuments(ctx, bson.M{"queue": q.name, "status": jobStatusDead})
+}
+
+// leasable matches pending jobs and expired leases that have attempts left
+func (q *MongoQueue) leasable(now time.Time) bson.A {
+	return bson.A{
+		bson.M{"status": jobStatusPending},
+		bson.M{
+			"status":      jobStatusLeased,
+			"lease_until": bson.M{"$lt": now},
+			"attempts":    bson.M{"$lt": q.maxAttempts - 1},
+		},
+	}
+}
+
+// deadLetterExpired dead-letters expired leases that were the job's last attempt
+func (q *MongoQueue) deadLetterExpired(ctx context.Context, now time.Time) error {
+	filter := bson.M{
+		"queue":       q.name,
+		"status":      jobStatusLeased,
+		"lease_until": bson.M{"$lt": now},
+		"attempts":    bson.M{"$gte": q.maxAttempts - 1},
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": jobStatusDead},
+		"$unset": bson.M{"worker_id": "", "lease_until": ""},
+		"$inc":   bson.M{"attempts": 1},
+	}
+
+	result, err := q.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Queue %s: dead-lettered %d job(s) after %d deliveries", q.name, result.ModifiedCount, q.maxAttempts)
+	}
+
+	return nil
+}
+
+// Close is a no-op; the Mongo client is owned by the caller
+func (q *MongoQueue) Close(ctx context.Context) error {
+	return nil
+}
+
+// leaseFilter matches a job only while workerID still holds the lease on it
+// A late ack still succeeds as long as no other worker has taken the job over
+func (q *MongoQueue) leaseFilter(workerID, jobID string) bson.M {
+	return bson.M{
+		"_id":       jobID,
+		"queue":     q.name,
+		"status":    jobStatusLeased,
+		"worker_id": workerID,
+	}
+}
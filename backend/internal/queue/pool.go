@@ -0,0 +1,97 @@
+package queue
+
+/**
+ * Queue Pool
+ *
+ * Purpose: Hand out named queues of the configured backend, opening each on
+ * first use
+ *
+ * Each project has its own test job queue, so the set of queues is not known
+ * at startup. Redis queues in a pool share one connection.
+ */
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/utils"
+)
+
+type Pool struct {
+	cfg    *utils.Config
+	db     *mongo.Database
+	client *redis.Client // only set for the redis backend
+
+	mu     sync.Mutex
+	queues map[string]JobQueue
+}
+
+// OpenPool creates a pool for the queue backend selected by cfg.QueueBackend
+// db is only used by the mongo backend
+func OpenPool(ctx context.Context, cfg *utils.Config, db *mongo.Database) (*Pool, error) {
+	pool := &Pool{
+		cfg:    cfg,
+		db:     db,
+		queues: make(map[string]JobQueue),
+	}
+
+	switch cfg.QueueBackend {
+	case "redis":
+		client, err := connectRedis(ctx, cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		pool.client = client
+	case "mongo", "memory":
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.QueueBackend)
+	}
+
+	return pool, nil
+}
+
+// Queue returns the queue with the given name, opening it if needed
+func (p *Pool) Queue(ctx context.Context, name string) (JobQueue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if q, ok := p.queues[name]; ok {
+		return q, nil
+	}
+
+	var q JobQueue
+	switch p.cfg.QueueBackend {
+	case "redis":
+		q = newRedisQueue(p.client, name, p.cfg.QueueVisibilityTimeout, p.cfg.QueueMaxAttempts)
+	case "mongo":
+		mq, err := NewMongoQueue(ctx, p.db, name, p.cfg.QueueVisibilityTimeout, p.cfg.QueueMaxAttempts)
+		if err != nil {
+			return nil, err
+		}
+		q = mq
+	default:
+		q = NewMemoryQueue(p.cfg.QueueVisibilityTimeout, p.cfg.QueueMaxAttempts)
+	}
+
+	p.queues[name] = q
+	return q, nil
+}
+
+// Close closes every opened queue and the shared connection
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, q := range p.queues {
+		q.Close(ctx)
+		delete(p.queues, name)
+	}
+	if p.client != nil {
+		return p.client.Close()
+	}
+	return nil
+}
@@ -1,36 +1,130 @@
 package queue
 
+/**
+ * Job Queue
+ *
+ * Purpose: Deliver test jobs from the backend to the runners
+ *
+ * Every backend implements JobQueue with at-least-once delivery: a leased
+ * job that is neither acked, nacked nor extended before its visibility
+ * timeout runs out is handed to another worker. A job that has been
+ * delivered maxAttempts times without an ack is moved to the queue's
+ * dead-letter list instead of being delivered again.
+ *
+ * Backends (selected with QUEUE_BACKEND):
+ * - redis:  RedisQueue, the default; the Python runners can pop from it directly
+ * - mongo:  MongoQueue, for teams that don't want to run Redis
+ * - memory: MemoryQueue, for unit tests and single-binary dev mode
+ *
+ * Open creates a single queue; Pool hands out many queues of one backend.
+ */
+
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/utils"
 )
 
-type Queue struct {
-	// TODO: Add Redis connection
-}
+// pollInterval is how often Lease re-checks an empty queue while waiting
+const pollInterval = 500 * time.Millisecond
+
+var (
+	// ErrJobNotLeased is returned by Ack, Nack and Extend when the worker does not hold a lease on the job
+	ErrJobNotLeased = errors.New("job is not leased by this worker")
+
+	// ErrJobDeadLettered is returned by Nack when the job used up its attempts and was dead-lettered
+	ErrJobDeadLettered = errors.New("job was delivered too many times and moved to the dead-letter list")
+
+	// ErrJobNotFound is returned by Remove when the job is neither pending nor leased
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// Job is the envelope stored around every payload
+type Job struct {
+	ID         string          `json:"id"`
+	Payload    json.RawMessage `json:"payload"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
 
-func NewQueue() *Queue {
-	// TODO: Initialize Redis connection
-	log.Println("Initializing Redis queue connection...")
-	return &Queue{}
+	// Attempts counts how many earlier deliveries ended without an ack
+	Attempts int `json:"-"`
 }
 
-func (q *Queue) Connect(ctx context.Context) error {
-	// TODO: Implement Redis connection logic
-	return nil
+// JobQueue is a single named queue with at-least-once delivery
+type JobQueue interface {
+	// Enqueue adds a payload to the end of the queue and returns the new job ID
+	Enqueue(ctx context.Context, payload interface{}) (string, error)
+
+	// Lease hands the next job to workerID, waiting up to wait for one to arrive
+	// Returns nil, nil if no job became available in time
+	Lease(ctx context.Context, workerID string, wait time.Duration) (*Job, error)
+
+	// Ack marks a leased job as done so it is never delivered again
+	Ack(ctx context.Context, workerID, jobID string) error
+
+	// Nack gives a leased job back so it is delivered again right away
+	// Returns ErrJobDeadLettered if the job used up its attempts instead
+	Nack(ctx context.Context, workerID, jobID string) error
+
+	// Extend renews workerID's lease on a job for another visibility timeout
+	Extend(ctx context.Context, workerID, jobID string) error
+
+	// Remove deletes a pending or leased job, whoever holds the lease, so it is never delivered again
+	Remove(ctx context.Context, jobID string) error
+
+	// Depth returns the number of jobs waiting to be leased
+	Depth(ctx context.Context) (int64, error)
+
+	// DeadDepth returns the number of dead-lettered jobs
+	DeadDepth(ctx context.Context) (int64, error)
+
+	// Close releases any connections and background goroutines
+	Close(ctx context.Context) error
 }
 
-func (q *Queue) Disconnect(ctx context.Context) error {
-	// TODO: Implement Redis disconnection logic
-	return nil
+// Open creates the queue backend selected by cfg.QueueBackend
+// db is only used by the mongo backend
+func Open(ctx context.Context, cfg *utils.Config, db *mongo.Database, name string) (JobQueue, error) {
+	switch cfg.QueueBackend {
+	case "redis":
+		return NewRedisQueue(ctx, cfg.RedisURL, name, cfg.QueueVisibilityTimeout, cfg.QueueMaxAttempts)
+	case "mongo":
+		return NewMongoQueue(ctx, db, name, cfg.QueueVisibilityTimeout, cfg.QueueMaxAttempts)
+	case "memory":
+		return NewMemoryQueue(cfg.QueueVisibilityTimeout, cfg.QueueMaxAttempts), nil
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.QueueBackend)
+	}
 }
 
-func (q *Queue) Enqueue(ctx context.Context, queueName string, payload interface{}) error {
-	// TODO: Implement enqueue logic
-	return nil
+// newJob wraps a payload in a fresh job envelope
+func newJob(id string, payload interface{}) (*Job, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		ID:         id,
+		Payload:    body,
+		EnqueuedAt: time.Now(),
+	}, nil
 }
 
-func (q *Queue) Dequeue(ctx context.Context, queueName string) (interface{}, error) {
-	// TODO: Implement dequeue logic
-	return nil, nil
+// waitOrDone sleeps for pollInterval; it returns false if ctx is cancelled or deadline has passed
+func waitOrDone(ctx context.Context, deadline time.Time) (bool, error) {
+	if time.Now().After(deadline) {
+		return false, nil
+	}
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-time.After(pollInterval):
+		return true, nil
+	}
 }
@@ -0,0 +1,275 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newQueueFunc creates an empty queue for one test
+type newQueueFunc func(t *testing.T, visibilityTimeout time.Duration, maxAttempts int) JobQueue
+
+// testPayload is the payload enqueued by the shared tests
+type testPayload struct {
+	RunID string `json:"run_id"`
+}
+
+// testJobQueue runs the behaviour every JobQueue backend must share
+func testJobQueue(t *testing.T, newQueue newQueueFunc) {
+	t.Run("LeaseAndAck", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, time.Minute, 5)
+
+		jobID := enqueue(t, q, "run-1")
+		assertDepth(t, q, 1)
+
+		job := lease(t, q, "worker-1")
+		if job == nil || job.ID != jobID {
+			t.Fatalf("Lease returned %+v, want job %s", job, jobID)
+		}
+		if string(job.Payload) != `{"run_id":"run-1"}` {
+			t.Errorf("payload = %s", job.Payload)
+		}
+		if job.Attempts != 0 {
+			t.Errorf("attempts = %d, want 0", job.Attempts)
+		}
+		assertDepth(t, q, 0)
+
+		if err := q.Ack(ctx, "worker-2", jobID); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("Ack by another worker = %v, want ErrJobNotLeased", err)
+		}
+		if err := q.Ack(ctx, "worker-1", jobID); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+		if err := q.Ack(ctx, "worker-1", jobID); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("second Ack = %v, want ErrJobNotLeased", err)
+		}
+		if job := lease(t, q, "worker-1"); job != nil {
+			t.Errorf("acked job %s was delivered again", job.ID)
+		}
+	})
+
+	t.Run("LeaseInOrder", func(t *testing.T) {
+		q := newQueue(t, time.Minute, 5)
+
+		first := enqueue(t, q, "run-1")
+		second := enqueue(t, q, "run-2")
+
+		if job := lease(t, q, "worker-1"); job == nil || job.ID != first {
+			t.Fatalf("first Lease returned %+v, want job %s", job, first)
+		}
+		if job := lease(t, q, "worker-2"); job == nil || job.ID != second {
+			t.Fatalf("second Lease returned %+v, want job %s", job, second)
+		}
+	})
+
+	t.Run("LeaseWaitsForJob", func(t *testing.T) {
+		q := newQueue(t, time.Minute, 5)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			enqueue(t, q, "run-1")
+		}()
+
+		job, err := q.Lease(context.Background(), "worker-1", 5*time.Second)
+		if err != nil {
+			t.Fatalf("Lease: %v", err)
+		}
+		if job == nil {
+			t.Fatal("Lease returned no job")
+		}
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, time.Minute, 5)
+
+		jobID := enqueue(t, q, "run-1")
+		enqueue(t, q, "run-2")
+		lease(t, q, "worker-1")
+
+		if err := q.Nack(ctx, "worker-2", jobID); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("Nack by another worker = %v, want ErrJobNotLeased", err)
+		}
+		if err := q.Nack(ctx, "worker-1", jobID); err != nil {
+			t.Fatalf("Nack: %v", err)
+		}
+
+		// A nacked job goes ahead of jobs that were never delivered
+		job := lease(t, q, "worker-2")
+		if job == nil || job.ID != jobID {
+			t.Fatalf("Lease after Nack returned %+v, want job %s", job, jobID)
+		}
+		if job.Attempts != 1 {
+			t.Errorf("attempts = %d, want 1", job.Attempts)
+		}
+	})
+
+	t.Run("VisibilityTimeout", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, 100*time.Millisecond, 5)
+
+		jobID := enqueue(t, q, "run-1")
+		lease(t, q, "worker-1")
+		if job := lease(t, q, "worker-2"); job != nil {
+			t.Fatalf("job %s was delivered twice within the visibility timeout", job.ID)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+
+		job := lease(t, q, "worker-2")
+		if job == nil || job.ID != jobID {
+			t.Fatalf("Lease after the visibility timeout returned %+v, want job %s", job, jobID)
+		}
+		if job.Attempts != 1 {
+			t.Errorf("attempts = %d, want 1", job.Attempts)
+		}
+		if err := q.Ack(ctx, "worker-1", jobID); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("Ack by the worker whose lease expired = %v, want ErrJobNotLeased", err)
+		}
+		if err := q.Ack(ctx, "worker-2", jobID); err != nil {
+			t.Errorf("Ack: %v", err)
+		}
+	})
+
+	t.Run("Extend", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, 300*time.Millisecond, 5)
+
+		jobID := enqueue(t, q, "run-1")
+		lease(t, q, "worker-1")
+
+		if err := q.Extend(ctx, "worker-2", jobID); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("Extend by another worker = %v, want ErrJobNotLeased", err)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+		if err := q.Extend(ctx, "worker-1", jobID); err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+
+		// Past the original deadline, but within the extended one
+		if job := lease(t, q, "worker-2"); job != nil {
+			t.Fatalf("job %s was delivered again while its lease was extended", job.ID)
+		}
+		if err := q.Ack(ctx, "worker-1", jobID); err != nil {
+			t.Errorf("Ack: %v", err)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, time.Minute, 5)
+
+		pending := enqueue(t, q, "run-1")
+		leased := enqueue(t, q, "run-2")
+		kept := enqueue(t, q, "run-3")
+
+		// Lease the first two, then give the first one back so it is pending again
+		lease(t, q, "worker-1")
+		lease(t, q, "worker-1")
+		if err := q.Nack(ctx, "worker-1", pending); err != nil {
+			t.Fatalf("Nack: %v", err)
+		}
+
+		for _, jobID := range []string{pending, leased} {
+			if err := q.Remove(ctx, jobID); err != nil {
+				t.Fatalf("Remove %s: %v", jobID, err)
+			}
+		}
+		if err := q.Remove(ctx, pending); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("second Remove = %v, want ErrJobNotFound", err)
+		}
+		if err := q.Ack(ctx, "worker-1", leased); !errors.Is(err, ErrJobNotLeased) {
+			t.Errorf("Ack of a removed job = %v, want ErrJobNotLeased", err)
+		}
+
+		if job := lease(t, q, "worker-2"); job == nil || job.ID != kept {
+			t.Fatalf("Lease returned %+v, want job %s", job, kept)
+		}
+		assertDepth(t, q, 0)
+	})
+
+	t.Run("DeadLetterAfterNacks", func(t *testing.T) {
+		ctx := context.Background()
+		q := newQueue(t, time.Minute, 2)
+
+		jobID := enqueue(t, q, "run-1")
+
+		lease(t, q, "worker-1")
+		if err := q.Nack(ctx, "worker-1", jobID); err != nil {
+			t.Fatalf("first Nack: %v", err)
+		}
+		lease(t, q, "worker-1")
+		if err := q.Nack(ctx, "worker-1", jobID); !errors.Is(err, ErrJobDeadLettered) {
+			t.Fatalf("last Nack = %v, want ErrJobDeadLettered", err)
+		}
+
+		if job := lease(t, q, "worker-1"); job != nil {
+			t.Fatalf("dead-lettered job %s was delivered again", job.ID)
+		}
+		assertDepth(t, q, 0)
+		assertDeadDepth(t, q, 1)
+	})
+
+	t.Run("DeadLetterAfterTimeouts", func(t *testing.T) {
+		q := newQueue(t, 100*time.Millisecond, 2)
+
+		enqueue(t, q, "run-1")
+		lease(t, q, "worker-1")
+		time.Sleep(200 * time.Millisecond)
+		if job := lease(t, q, "worker-2"); job == nil {
+			t.Fatal("job was not delivered again after its first lease expired")
+		}
+		time.Sleep(200 * time.Millisecond)
+
+		if job := lease(t, q, "worker-3"); job != nil {
+			t.Fatalf("job %s was delivered after using up its attempts", job.ID)
+		}
+		assertDepth(t, q, 0)
+		assertDeadDepth(t, q, 1)
+	})
+}
+
+func enqueue(t *testing.T, q JobQueue, runID string) string {
+	t.Helper()
+	jobID, err := q.Enqueue(context.Background(), testPayload{RunID: runID})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return jobID
+}
+
+// lease leases a job without waiting; nil means the queue had none to hand out
+func lease(t *testing.T, q JobQueue, workerID string) *Job {
+	t.Helper()
+	job, err := q.Lease(context.Background(), workerID, 0)
+	if err != nil {
+		t.Fatalf("Lease: %v", err)
+	}
+	return job
+}
+
+func assertDepth(t *testing.T, q JobQueue, want int64) {
+	t.Helper()
+	depth, err := q.Depth(context.Background())
+	if err != nil {
+		t.Fatalf("Depth: %v", err)
+	}
+	if depth != want {
+		t.Errorf("depth = %d, want %d", depth, want)
+	}
+}
+
+func assertDeadDepth(t *testing.T, q JobQueue, want int64) {
+	t.Helper()
+	depth, err := q.DeadDepth(context.Background())
+	if err != nil {
+		t.Fatalf("DeadDepth: %v", err)
+	}
+	if depth != want {
+		t.Errorf("dead depth = %d, want %d", depth, want)
+	}
+}
@@ -0,0 +1,355 @@
+package queue
+
+/**
+ * Redis Job Queue
+ *
+ * Redis keys used for a queue named "jobs":
+ * - queue:{jobs}:pending    LIST of job JSON waiting to be picked up
+ * - queue:{jobs}:leased     HASH job ID -> job JSON of leased jobs
+ * - queue:{jobs}:leases     ZSET job ID -> lease deadline (unix ms)
+ * - queue:{jobs}:owners     HASH job ID -> worker ID holding the lease
+ * - queue:{jobs}:attempts   HASH job ID -> number of earlier deliveries
+ * - queue:{jobs}:dead       LIST of job JSON that used up its attempts
+ *
+ * The queue name is a hash tag, so all keys of a queue live in one Redis
+ * Cluster slot, and every script receives all the keys it touches in KEYS.
+ *
+ * Lease atomically moves a job from the pending list into the leased hash
+ * and records a lease. Ack and Remove delete it for good; Extend moves the lease
+ * deadline. A job whose lease expires (the worker crashed or never acked)
+ * is moved back to the pending list by the next Lease, and by
+ * RequeueExpired, which a background goroutine runs periodically. Once a
+ * job has been delivered maxAttempts times it goes to the dead list instead.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// requeueInterval is how often expired leases are returned to the pending list
+const requeueInterval = 30 * time.Second
+
+type RedisQueue struct {
+	client            *redis.Client
+	ownsClient        bool
+	name              string
+	visibilityTimeout time.Duration
+	maxAttempts       int
+	stopRequeuer      context.CancelFunc
+}
+
+// NewRedisQueue connects to Redis and starts re-queueing expired leases
+// visibilityTimeout is how long a leased job may stay un-acked before it is re-queued;
+// a job delivered maxAttempts times without an ack is dead-lettered
+func NewRedisQueue(ctx context.Context, redisURL, name string, visibilityTimeout time.Duration, maxAttempts int) (*RedisQueue, error) {
+	client, err := connectRedis(ctx, redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newRedisQueue(client, name, visibilityTimeout, maxAttempts)
+	q.ownsClient = true
+	return q, nil
+}
+
+// connectRedis opens and checks a Redis connection
+func connectRedis(ctx context.Context, redisURL string) (*redis.Client, error) {
+	log.Println("Initializing Redis queue connection...")
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// newRedisQueue creates a queue on an existing connection and starts its requeuer
+func newRedisQueue(client *redis.Client, name string, visibilityTimeout time.Duration, maxAttempts int) *RedisQueue {
+	requeueCtx, stop := context.WithCancel(context.Background())
+	q := &RedisQueue{
+		client:            client,
+		name:              name,
+		visibilityTimeout: visibilityTimeout,
+		maxAttempts:       maxAttempts,
+		stopRequeuer:      stop,
+	}
+	go q.runRequeuer(requeueCtx)
+
+	return q
+}
+
+// Close stops the requeuer and closes the Redis connection if the queue opened it
+func (q *RedisQueue) Close(ctx context.Context) error {
+	q.stopRequeuer()
+	if !q.ownsClient {
+		return nil
+	}
+	return q.client.Close()
+}
+
+// Enqueue adds a payload to the end of the queue and returns the new job ID
+func (q *RedisQueue) Enqueue(ctx context.Context, payload interface{}) (string, error) {
+	job, err := newJob(primitive.NewObjectID().Hex(), payload)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+
+	if err := q.client.LPush(ctx, q.key("pending"), raw).Err(); err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// requeueLua defines requeue, which puts a delivered job back at the front
+// of the pending list with its attempts bumped, or in the dead list once it
+// has used up its attempts (returning false), and requeueExpired, which
+// requeues every job whose lease has expired. Shared by the scripts below.
+const requeueLua = `
+local function requeue(id, raw, maxAttempts)
+	local attempts = redis.call('HINCRBY', KEYS[5], id, 1)
+	if attempts >= tonumber(maxAttempts) then
+		redis.call('HDEL', KEYS[5], id)
+		redis.call('LPUSH', KEYS[6], raw)
+		return false
+	end
+	redis.call('RPUSH', KEYS[1], raw)
+	return true
+end
+
+local function requeueExpired(now, maxAttempts)
+	local count = 0
+	for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now)) do
+		local raw = redis.call('HGET', KEYS[2], id)
+		if raw then
+			redis.call('HDEL', KEYS[2], id)
+			if requeue(id, raw, maxAttempts) then
+				count = count + 1
+			end
+		end
+		redis.call('ZREM', KEYS[3], id)
+		redis.call('HDEL', KEYS[4], id)
+	end
+	return count
+end
+`
+
+// leaseScript returns expired leases to the queue, then moves the oldest
+// pending job into the leased hash and records its lease, in one atomic step.
+// KEYS: see RedisQueue.keys  ARGV: now, deadline, workerID, maxAttempts
+var leaseScript = redis.NewScript(requeueLua + `
+requeueExpired(ARGV[1], ARGV[4])
+local raw = redis.call('RPOP', KEYS[1])
+if not raw then
+	return false
+end
+local job = cjson.decode(raw)
+redis.call('HSET', KEYS[2], job.id, raw)
+redis.call('ZADD', KEYS[3], ARGV[2], job.id)
+redis.call('HSET', KEYS[4], job.id, ARGV[3])
+local attempts = redis.call('HGET', KEYS[5], job.id) or '0'
+return {raw, attempts}
+`)
+
+// Lease hands the next job to workerID, waiting up to wait for one to arrive
+func (q *RedisQueue) Lease(ctx context.Context, workerID string, wait time.Duration) (*Job, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		now := time.Now()
+		leaseUntil := now.Add(q.visibilityTimeout).UnixMilli()
+
+		result, err := leaseScript.Run(ctx, q.client, q.keys(), now.UnixMilli(), leaseUntil, workerID, q.maxAttempts).StringSlice()
+		if err == nil {
+			var job Job
+			if err := json.Unmarshal([]byte(result[0]), &job); err != nil {
+				return nil, err
+			}
+			job.Attempts, _ = strconv.Atoi(result[1])
+			return &job, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		if ok, err := waitOrDone(ctx, deadline); !ok {
+			return nil, err
+		}
+	}
+}
+
+// releaseScript takes a job leased by ARGV[2] out of the leased hash and
+// drops its lease. When ARGV[3] is "requeue" the job is requeued (returning
+// 2 if it was dead-lettered instead); otherwise it is forgotten.
+// KEYS: see RedisQueue.keys  ARGV: jobID, workerID, mode, maxAttempts
+var releaseScript = redis.NewScript(requeueLua + `
+if redis.call('HGET', KEYS[4], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+local raw = redis.call('HGET', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+if ARGV[3] == 'requeue' and raw then
+	if not requeue(ARGV[1], raw, ARGV[4]) then
+		return 2
+	end
+else
+	redis.call('HDEL', KEYS[5], ARGV[1])
+end
+return 1
+`)
+
+// Ack marks a leased job as done so it is never delivered again
+func (q *RedisQueue) Ack(ctx context.Context, workerID, jobID string) error {
+	return q.release(ctx, workerID, jobID, "ack")
+}
+
+// Nack gives a leased job back so it is delivered again right away
+func (q *RedisQueue) Nack(ctx context.Context, workerID, jobID string) error {
+	return q.release(ctx, workerID, jobID, "requeue")
+}
+
+func (q *RedisQueue) release(ctx context.Context, workerID, jobID, mode string) error {
+	released, err := releaseScript.Run(ctx, q.client, q.keys(), jobID, workerID, mode, q.maxAttempts).Int()
+	if err != nil {
+		return err
+	}
+	switch released {
+	case 0:
+		return ErrJobNotLeased
+	case 2:
+		log.Printf("Queue %s: job %s dead-lettered after %d deliveries", q.name, jobID, q.maxAttempts)
+		return ErrJobDeadLettered
+	}
+
+	return nil
+}
+
+// extendScript moves the lease deadline of a job still leased by ARGV[2]
+// KEYS: see RedisQueue.keys  ARGV: jobID, workerID, deadline
+var extendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[4], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1
+`)
+
+// Extend renews workerID's lease on a job for another visibility timeout
+func (q *RedisQueue) Extend(ctx context.Context, workerID, jobID string) error {
+	leaseUntil := time.Now().Add(q.visibilityTimeout).UnixMilli()
+
+	extended, err := extendScript.Run(ctx, q.client, q.keys(), jobID, workerID, leaseUntil).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrJobNotLeased
+	}
+
+	return nil
+}
+
+// removeScript deletes a job from the leased hash, or else from the pending list
+// KEYS: see RedisQueue.keys  ARGV: jobID
+var removeScript = redis.NewScript(`
+local removed = redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+if removed == 1 then
+	return 1
+end
+for _, raw in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	if cjson.decode(raw).id == ARGV[1] then
+		redis.call('LREM', KEYS[1], 1, raw)
+		return 1
+	end
+end
+return 0
+`)
+
+// Remove deletes a pending or leased job, whoever holds the lease, so it is never delivered again
+func (q *RedisQueue) Remove(ctx context.Context, jobID string) error {
+	removed, err := removeScript.Run(ctx, q.client, q.keys(), jobID).Int()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// requeueScript returns every expired lease to the pending list
+// KEYS: see RedisQueue.keys  ARGV: now, maxAttempts
+var requeueScript = redis.NewScript(requeueLua + `
+return requeueExpired(ARGV[1], ARGV[2])
+`)
+
+// RequeueExpired returns jobs whose lease has expired to the pending list
+// Returns the number of jobs re-queued, not counting dead-lettered ones
+func (q *RedisQueue) RequeueExpired(ctx context.Context) (int, error) {
+	return requeueScript.Run(ctx, q.client, q.keys(), time.Now().UnixMilli(), q.maxAttempts).Int()
+}
+
+// runRequeuer calls RequeueExpired every requeueInterval until ctx is cancelled
+func (q *RedisQueue) runRequeuer(ctx context.Context) {
+	ticker := time.NewTicker(requeueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := q.RequeueExpired(ctx)
+			if err != nil {
+				log.Printf("Queue %s: failed to re-queue expired jobs: %v", q.name, err)
+			} else if count > 0 {
+				log.Printf("Queue %s: re-queued %d expired job(s)", q.name, count)
+			}
+		}
+	}
+}
+
+// Depth returns the number of jobs waiting to be leased
+func (q *RedisQueue) Depth(ctx context.Context) (int64, error) {
+	return q.client.LLen(ctx, q.key("pending")).Result()
+}
+
+// DeadDepth returns the number of dead-lettered jobs
+func (q *RedisQueue) DeadDepth(ctx context.Context) (int64, error) {
+	return q.client.LLen(ctx, q.key("dead")).Result()
+}
+
+// key names one of the queue's keys; the name in braces is the Cluster hash tag
+func (q *RedisQueue) key(suffix string) string {
+	return "queue:{" + q.name + "}:" + suffix
+}
+
+// keys lists the queue's keys in the order every script expects them
+func (q *RedisQueue) keys() []string {
+	return []string{q.key("pending"), q.key("leased"), q.key("leases"), q.key("owners"), q.key("attempts"), q.key("dead")}
+}
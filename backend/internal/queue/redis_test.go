@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis starts an in-process Redis stand-in and connects to it
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestRedisQueue(t *testing.T) {
+	testJobQueue(t, func(t *testing.T, visibilityTimeout time.Duration, maxAttempts int) JobQueue {
+		_, client := newTestRedis(t)
+		q := newRedisQueue(client, "jobs", visibilityTimeout, maxAttempts)
+		t.Cleanup(func() { q.Close(context.Background()) })
+		return q
+	})
+}
+
+func TestRedisQueueRequeueExpired(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	q := newRedisQueue(client, "jobs", 100*time.Millisecond, 5)
+	defer q.Close(ctx)
+
+	jobID := enqueue(t, q, "run-1")
+	lease(t, q, "worker-1")
+	time.Sleep(200 * time.Millisecond)
+
+	count, err := q.RequeueExpired(ctx)
+	if err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("re-queued %d jobs, want 1", count)
+	}
+	assertDepth(t, q, 1)
+	if server.Exists(q.key("leased")) || server.Exists(q.key("owners")) {
+		t.Error("the expired lease was not cleaned up")
+	}
+
+	job := lease(t, q, "worker-2")
+	if job == nil || job.ID != jobID || job.Attempts != 1 {
+		t.Fatalf("Lease returned %+v, want job %s on its second attempt", job, jobID)
+	}
+}
+
+func TestRedisQueueKeysShareHashSlot(t *testing.T) {
+	_, client := newTestRedis(t)
+	q := newRedisQueue(client, "test_jobs:p1", time.Minute, 5)
+	defer q.Close(context.Background())
+
+	for _, key := range q.keys() {
+		if want := "queue:{test_jobs:p1}:"; key[:len(want)] != want {
+			t.Errorf("key %q does not carry the queue's hash tag", key)
+		}
+	}
+}
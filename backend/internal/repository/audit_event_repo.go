@@ -0,0 +1,131 @@
+package repository
+
+/**
+ * Audit Event Repository
+ *
+ * Purpose: Handle all database operations for the audit_events collection
+ *
+ * Operations:
+ * - Insert: Append an event
+ * - Find: Read a page of events, newest first
+ * - Each: Stream every matching event, newest first (exports)
+ *
+ * The collection is append-only: there is deliberately no update or delete.
+ * Event IDs are ObjectIDs, so their hex strings sort by creation time.
+ */
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// AuditEventFilter selects audit events; zero fields match everything
+type AuditEventFilter struct {
+	ActorID      string
+	ActorEmail   string
+	Action       string // exact action
+	ActionPrefix string // e.g. "auth." for every auth action
+	From         time.Time
+	To           time.Time
+	BeforeID     string // only events older than this one (paging)
+}
+
+type AuditEventRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditEventRepository creates a new audit event repository instance
+func NewAuditEventRepository(db *mongo.Database) *AuditEventRepository {
+	return &AuditEventRepository{
+		collection: db.Collection("audit_events"),
+	}
+}
+
+// Insert appends an event
+func (r *AuditEventRepository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	event.ID = primitive.NewObjectID().Hex()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+// Find returns up to limit matching events, newest first
+func (r *AuditEventRepository) Find(ctx context.Context, filter AuditEventFilter, limit int64) ([]models.AuditEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter.query(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []models.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Each calls fn with every matching event, newest first, until fn returns an error
+func (r *AuditEventRepository) Each(ctx context.Context, filter AuditEventFilter, fn func(*models.AuditEvent) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter.query(), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event models.AuditEvent
+		if err := cursor.Decode(&event); err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// query builds the MongoDB filter
+func (f AuditEventFilter) query() bson.M {
+	query := bson.M{}
+	if f.ActorID != "" {
+		query["actor_id"] = f.ActorID
+	}
+	if f.ActorEmail != "" {
+		query["actor_email"] = f.ActorEmail
+	}
+	if f.Action != "" {
+		query["action"] = f.Action
+	} else if f.ActionPrefix != "" {
+		query["action"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.ActionPrefix)}
+	}
+
+	timeRange := bson.M{}
+	if !f.From.IsZero() {
+		timeRange["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		timeRange["$lt"] = f.To
+	}
+	if len(timeRange) > 0 {
+		query["time"] = timeRange
+	}
+
+	if f.BeforeID != "" {
+		query["_id"] = bson.M{"$lt": f.BeforeID}
+	}
+
+	return query
+}
@@ -0,0 +1,72 @@
+package repository
+
+/**
+ * Email Token Repository
+ *
+ * Purpose: Handle all database operations for the email_tokens collection
+ *
+ * Operations:
+ * - Create: Store a new token
+ * - Use: Atomically mark an unused, unexpired token as used
+ * - InvalidateUnused: Use up a user's outstanding tokens of one purpose
+ *
+ * The collection has a TTL index on expires_at.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+type EmailTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewEmailTokenRepository creates a new email token repository instance
+func NewEmailTokenRepository(db *mongo.Database) *EmailTokenRepository {
+	return &EmailTokenRepository{
+		collection: db.Collection("email_tokens"),
+	}
+}
+
+// Create inserts a new email token
+func (r *EmailTokenRepository) Create(ctx context.Context, token *models.EmailToken) error {
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// Use marks an unused, unexpired token of the given purpose as used and returns it
+// Returns mongo.ErrNoDocuments if no such token exists
+func (r *EmailTokenRepository) Use(ctx context.Context, id, purpose string, now time.Time) (*models.EmailToken, error) {
+	filter := bson.M{
+		"_id":        id,
+		"purpose":    purpose,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var token models.EmailToken
+	err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// InvalidateUnused marks every unused token of a user and purpose as used
+func (r *EmailTokenRepository) InvalidateUnused(ctx context.Context, userID, purpose string, now time.Time) error {
+	filter := bson.M{"user_id": userID, "purpose": purpose, "used_at": nil}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
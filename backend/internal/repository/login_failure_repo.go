@@ -0,0 +1,131 @@
+package repository
+
+/**
+ * Login Failure Repository
+ *
+ * Purpose: Handle all database operations for the login_failures collection
+ *
+ * Operations:
+ * - Find: Get the unexpired counters of some keys
+ * - Attempt: Count an attempt and lock past the limit, unless already locked
+ * - Refund: Take back a counted attempt that did not fail
+ * - Delete: Forget counters (successful login, admin unlock)
+ *
+ * Counters are shared by every backend replica. The collection has a TTL
+ * index on expires_at.
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type LoginFailureRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginFailureRepository creates a new login failure repository instance
+func NewLoginFailureRepository(db *mongo.Database) *LoginFailureRepository {
+	return &LoginFailureRepository{
+		collection: db.Collection("login_failures"),
+	}
+}
+
+// Find returns the counters of the given keys that have not expired
+func (r *LoginFailureRepository) Find(ctx context.Context, keys []string, now time.Time) ([]models.LoginFailures, error) {
+	filter := bson.M{
+		"_id":        bson.M{"$in": keys},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counters []models.LoginFailures
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
+
+// Attempt counts one attempt of key as a failure, unless key is locked,
+// and returns the counter as it was before (nil if there was none)
+// The check and the count are a single update, so concurrent attempts cannot
+// all get past the limit. The attempt that reaches maxFailures, and each one
+// after it, locks the counter for backoff doubled per attempt past the limit,
+// up to lockout. An expired counter (the TTL monitor only runs every minute)
+// starts over at one; either way the counter is remembered until at least
+// expiresAt. A locked counter is left unchanged.
+// locks[i] is the lock of the attempt maxFailures+i; the last one also
+// applies to every attempt after it.
+func (r *LoginFailureRepository) Attempt(ctx context.Context, key string, now, expiresAt time.Time, maxFailures int, locks []time.Duration) (*models.LoginFailures, error) {
+	live := bson.M{"$gt": bson.A{"$expires_at", now}}
+	locked := bson.M{"$and": bson.A{live, bson.M{"$gt": bson.A{"$locked_until", now}}}}
+	failures := bson.M{"$cond": bson.A{
+		live,
+		bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+		1,
+	}}
+	var lockMillis interface{} = locks[len(locks)-1].Milliseconds()
+	if len(locks) > 1 {
+		branches := bson.A{}
+		for i, lock := range locks[:len(locks)-1] {
+			branches = append(branches, bson.M{
+				"case": bson.M{"$eq": bson.A{failures, maxFailures + i}},
+				"then": lock.Milliseconds(),
+			})
+		}
+		lockMillis = bson.M{"$switch": bson.M{"branches": branches, "default": lockMillis}}
+	}
+	lockedUntil := bson.M{"$cond": bson.A{
+		bson.M{"$gte": bson.A{failures, maxFailures}},
+		bson.M{"$add": bson.A{now, lockMillis}},
+		bson.M{"$cond": bson.A{live, "$locked_until", "$$REMOVE"}},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"failures":     bson.M{"$cond": bson.A{locked, "$failures", failures}},
+		"locked_until": bson.M{"$cond": bson.A{locked, "$locked_until", lockedUntil}},
+		"expires_at": bson.M{"$cond": bson.A{locked, "$expires_at", bson.M{"$max": bson.A{
+			expiresAt,
+			bson.M{"$cond": bson.A{live, "$expires_at", expiresAt}},
+			lockedUntil,
+		}}}},
+		"updated_at": bson.M{"$cond": bson.A{locked, "$updated_at", now}},
+	}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var counter models.LoginFailures
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &counter, nil
+}
+
+// Refund takes back one attempt counted on key; a lock it caused is kept
+func (r *LoginFailureRepository) Refund(ctx context.Context, key string) error {
+	filter := bson.M{"_id": key, "failures": bson.M{"$gt": 0}}
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"failures": -1}})
+	return err
+}
+
+// Delete forgets the counters of the given keys
+func (r *LoginFailureRepository) Delete(ctx context.Context, keys []string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	return err
+}
@@ -0,0 +1,57 @@
+package repository
+
+/**
+ * OIDC Login Repository
+ *
+ * Purpose: Handle all database operations for the oidc_logins collection
+ *
+ * The collection has a TTL index on expires_at, so abandoned logins are
+ * removed by MongoDB.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+type OIDCLoginRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOIDCLoginRepository creates a new OIDC login repository instance
+func NewOIDCLoginRepository(db *mongo.Database) *OIDCLoginRepository {
+	return &OIDCLoginRepository{
+		collection: db.Collection("oidc_logins"),
+	}
+}
+
+// Create stores a login in progress
+func (r *OIDCLoginRepository) Create(ctx context.Context, login *models.OIDCLogin) error {
+	login.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, login)
+	return err
+}
+
+// Take removes and returns the unexpired login with the given state and provider
+// Returns mongo.ErrNoDocuments if there is none, so a state works only once.
+func (r *OIDCLoginRepository) Take(ctx context.Context, state, provider string, now time.Time) (*models.OIDCLogin, error) {
+	filter := bson.M{
+		"_id":        state,
+		"provider":   provider,
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var login models.OIDCLogin
+	err := r.collection.FindOneAndDelete(ctx, filter).Decode(&login)
+	if err != nil {
+		return nil, err
+	}
+
+	return &login, nil
+}
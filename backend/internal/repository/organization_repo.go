@@ -0,0 +1,143 @@
+package repository
+
+/**
+ * Organization Repository
+ *
+ * Purpose: Handle all database operations for the organizations and
+ * organization_members collections
+ *
+ * A membership's ID is "<organization ID>:<user ID>", so a user is a member
+ * of an organization at most once.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type OrganizationRepository struct {
+	organizations *mongo.Collection
+	members       *mongo.Collection
+}
+
+// NewOrganizationRepository creates a new organization repository instance
+func NewOrganizationRepository(db *mongo.Database) *OrganizationRepository {
+	return &OrganizationRepository{
+		organizations: db.Collection("organizations"),
+		members:       db.Collection("organization_members"),
+	}
+}
+
+// Create inserts a new organization
+func (r *OrganizationRepository) Create(ctx context.Context, org *models.Organization) error {
+	org.ID = primitive.NewObjectID().Hex()
+	org.CreatedAt = time.Now()
+
+	_, err := r.organizations.InsertOne(ctx, org)
+	return err
+}
+
+// GetByID retrieves an organization
+func (r *OrganizationRepository) GetByID(ctx context.Context, id string) (*models.Organization, error) {
+	var org models.Organization
+	err := r.organizations.FindOne(ctx, bson.M{"_id": id}).Decode(&org)
+	if err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// ListByIDs returns the organizations with the given IDs, by name
+func (r *OrganizationRepository) ListByIDs(ctx context.Context, ids []string) ([]models.Organization, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.organizations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := []models.Organization{}
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// SetMember adds a user to an organization or changes their role
+func (r *OrganizationRepository) SetMember(ctx context.Context, member *models.OrganizationMember) error {
+	member.ID = member.OrganizationID + ":" + member.UserID
+	filter := bson.M{"_id": member.ID}
+	update := bson.M{
+		"$set": bson.M{"role": member.Role},
+		"$setOnInsert": bson.M{
+			"organization_id": member.OrganizationID,
+			"user_id":         member.UserID,
+			"created_at":      time.Now(),
+		},
+	}
+
+	_, err := r.members.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetMember retrieves a user's membership of an organization
+// Returns mongo.ErrNoDocuments if the user is not a member
+func (r *OrganizationRepository) GetMember(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.members.FindOne(ctx, bson.M{"_id": orgID + ":" + userID}).Decode(&member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ListMembers returns every member of an organization
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error) {
+	return r.findMembers(ctx, bson.M{"organization_id": orgID})
+}
+
+// ListMembershipsByUser returns every organization membership of a user
+func (r *OrganizationRepository) ListMembershipsByUser(ctx context.Context, userID string) ([]models.OrganizationMember, error) {
+	return r.findMembers(ctx, bson.M{"user_id": userID})
+}
+
+// RemoveMember removes a user from an organization
+// Returns mongo.ErrNoDocuments if the user is not a member
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+	result, err := r.members.DeleteOne(ctx, bson.M{"_id": orgID + ":" + userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) findMembers(ctx context.Context, filter bson.M) ([]models.OrganizationMember, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.members.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []models.OrganizationMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
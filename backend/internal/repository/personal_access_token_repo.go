@@ -0,0 +1,107 @@
+package repository
+
+/**
+ * Personal Access Token Repository
+ *
+ * Purpose: Handle all database operations for the personal_access_tokens collection
+ *
+ * Tokens are looked up by the SHA-256 of the token (unique index on
+ * token_hash); listing and deleting are scoped by the owner's user ID.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type PersonalAccessTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPersonalAccessTokenRepository creates a new personal access token repository instance
+func NewPersonalAccessTokenRepository(db *mongo.Database) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		collection: db.Collection("personal_access_tokens"),
+	}
+}
+
+// Create inserts a new token owned by token.UserID
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	token.ID = primitive.NewObjectID().Hex()
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// GetByHash retrieves the token with the given hash
+func (r *PersonalAccessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ListByUser returns every token owned by the user, newest first
+func (r *PersonalAccessTokenRepository) ListByUser(ctx context.Context, userID string) ([]models.PersonalAccessToken, error) {
+	filter := bson.M{"user_id": userID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []models.PersonalAccessToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Delete removes a token owned by the user
+// Returns mongo.ErrNoDocuments if no matching token was found
+func (r *PersonalAccessTokenRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteByUser removes every token of a user
+func (r *PersonalAccessTokenRepository) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+// TouchLastUsed records that a token was used at now from ip
+// The write is skipped if the recorded time is less than resolution old.
+func (r *PersonalAccessTokenRepository) TouchLastUsed(ctx context.Context, id, ip string, now time.Time, resolution time.Duration) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"last_used_at": nil},
+			bson.M{"last_used_at": bson.M{"$lt": now.Add(-resolution)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"last_used_at": now, "last_used_ip": ip}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,174 @@
+package repository
+
+/**
+ * Project Repository
+ *
+ * Purpose: Handle all database operations for the projects and
+ * project_members collections
+ *
+ * A membership's ID is "<project ID>:<user ID>", so a user has at most one
+ * role per project. A personal project's ID is its owner's user ID.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type ProjectRepository struct {
+	projects *mongo.Collection
+	members  *mongo.Collection
+}
+
+// NewProjectRepository creates a new project repository instance
+func NewProjectRepository(db *mongo.Database) *ProjectRepository {
+	return &ProjectRepository{
+		projects: db.Collection("projects"),
+		members:  db.Collection("project_members"),
+	}
+}
+
+// Create inserts a new organization project
+func (r *ProjectRepository) Create(ctx context.Context, project *models.Project) error {
+	project.ID = primitive.NewObjectID().Hex()
+	project.CreatedAt = time.Now()
+
+	_, err := r.projects.InsertOne(ctx, project)
+	return err
+}
+
+// EnsurePersonal creates the personal project of a user unless it already exists
+func (r *ProjectRepository) EnsurePersonal(ctx context.Context, userID, name string) error {
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$setOnInsert": bson.M{
+		"name":       name,
+		"personal":   true,
+		"created_by": userID,
+		"created_at": time.Now(),
+	}}
+
+	_, err := r.projects.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetByID retrieves a project
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*models.Project, error) {
+	var project models.Project
+	err := r.projects.FindOne(ctx, bson.M{"_id": id}).Decode(&project)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+// ListByIDs returns the projects with the given IDs, by name
+func (r *ProjectRepository) ListByIDs(ctx context.Context, ids []string) ([]models.Project, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.projects.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []models.Project{}
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// ListByOrganization returns every project of an organization, by name
+func (r *ProjectRepository) ListByOrganization(ctx context.Context, orgID string) ([]models.Project, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.projects.Find(ctx, bson.M{"organization_id": orgID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []models.Project{}
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// SetMember adds a user to a project or changes their role
+func (r *ProjectRepository) SetMember(ctx context.Context, member *models.ProjectMember) error {
+	member.ID = member.ProjectID + ":" + member.UserID
+	filter := bson.M{"_id": member.ID}
+	update := bson.M{
+		"$set": bson.M{"role": member.Role},
+		"$setOnInsert": bson.M{
+			"project_id": member.ProjectID,
+			"user_id":    member.UserID,
+			"created_at": time.Now(),
+		},
+	}
+
+	_, err := r.members.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetMember retrieves a user's membership of a project
+// Returns mongo.ErrNoDocuments if the user is not a member
+func (r *ProjectRepository) GetMember(ctx context.Context, projectID, userID string) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := r.members.FindOne(ctx, bson.M{"_id": projectID + ":" + userID}).Decode(&member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ListMembers returns every member of a project
+func (r *ProjectRepository) ListMembers(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	return r.findMembers(ctx, bson.M{"project_id": projectID})
+}
+
+// ListMembershipsByUser returns every project membership of a user
+func (r *ProjectRepository) ListMembershipsByUser(ctx context.Context, userID string) ([]models.ProjectMember, error) {
+	return r.findMembers(ctx, bson.M{"user_id": userID})
+}
+
+// RemoveMember removes a user from a project
+// Returns mongo.ErrNoDocuments if the user is not a member
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	result, err := r.members.DeleteOne(ctx, bson.M{"_id": projectID + ":" + userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) findMembers(ctx context.Context, filter bson.M) ([]models.ProjectMember, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.members.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []models.ProjectMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
@@ -1,28 +1,80 @@
 package repository
 
+/**
+ * Result Repository
+ *
+ * Purpose: Handle all database operations for the results collection
+ *
+ * Reads are scoped by the project ID, copied from the run.
+ */
+
 import (
 	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
 )
 
 type ResultRepository struct {
-	// TODO: Add database connection
+	collection *mongo.Collection
 }
 
-func NewResultRepository() *ResultRepository {
-	return &ResultRepository{}
+// NewResultRepository creates a new result repository instance
+func NewResultRepository(db *mongo.Database) *ResultRepository {
+	return &ResultRepository{
+		collection: db.Collection("results"),
+	}
 }
 
-func (r *ResultRepository) Create(ctx context.Context, testID, status, videoPath, screenshotPath string) error {
-	// TODO: Implement result creation in database
-	return nil
+// Create inserts a new result
+func (r *ResultRepository) Create(ctx context.Context, result *models.Result) error {
+	result.ID = primitive.NewObjectID().Hex()
+	result.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, result)
+	return err
 }
 
-func (r *ResultRepository) GetByTestID(ctx context.Context, testID string) ([]interface{}, error) {
-	// TODO: Implement get results by test ID
-	return nil, nil
+// GetByRunID returns the results recorded for a run in the project, newest first
+func (r *ResultRepository) GetByRunID(ctx context.Context, runID, projectID string) ([]models.Result, error) {
+	filter := bson.M{"run_id": runID, "project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.Result{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetByID retrieves a single result in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *ResultRepository) GetByID(ctx context.Context, id, projectID string) (*models.Result, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var result models.Result
+	err := r.collection.FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
-func (r *ResultRepository) GetByID(ctx context.Context, id string) (interface{}, error) {
-	// TODO: Implement get result by ID
-	return nil, nil
+// Delete removes a result
+// Only used to undo a result whose run could not be finished
+func (r *ResultRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
 }
@@ -0,0 +1,64 @@
+package repository
+
+/**
+ * Run Log Repository
+ *
+ * Purpose: Handle all database operations for the run_logs collection
+ *
+ * One document per log line, keyed by (run_id, seq). Reads are scoped by
+ * the project ID, copied from the run.
+ */
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type RunLogRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRunLogRepository creates a new run log repository instance
+func NewRunLogRepository(db *mongo.Database) *RunLogRepository {
+	return &RunLogRepository{
+		collection: db.Collection("run_logs"),
+	}
+}
+
+// Append inserts log lines whose Seq has already been assigned
+func (r *RunLogRepository) Append(ctx context.Context, lines []models.RunLogLine) error {
+	docs := make([]interface{}, len(lines))
+	for i := range lines {
+		lines[i].ID = primitive.NewObjectID().Hex()
+		docs[i] = lines[i]
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// ListAfter returns up to limit lines of a run in the project with seq greater than after, oldest first
+func (r *RunLogRepository) ListAfter(ctx context.Context, runID, projectID string, after int64, limit int64) ([]models.RunLogLine, error) {
+	filter := bson.M{"run_id": runID, "project_id": projectID, "seq": bson.M{"$gt": after}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "seq", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []models.RunLogLine{}
+	if err := cursor.All(ctx, &lines); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+/**
+ * Schedule Repository
+ *
+ * Purpose: Handle all database operations for the schedules collection
+ *
+ * User-facing queries are scoped by the project ID, like the tests
+ * collection; the scheduler reads due schedules across all projects.
+ * Firing is claimed with a conditional update on next_fire_at, so when
+ * several backend replicas run a scheduler each fire happens exactly once.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type ScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewScheduleRepository creates a new schedule repository instance
+func NewScheduleRepository(db *mongo.Database) *ScheduleRepository {
+	return &ScheduleRepository{
+		collection: db.Collection("schedules"),
+	}
+}
+
+// Create inserts a new schedule in schedule.ProjectID
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *models.Schedule) error {
+	schedule.ID = primitive.NewObjectID().Hex()
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, schedule)
+	return err
+}
+
+// GetAll returns every schedule in the project, newest first
+func (r *ScheduleRepository) GetAll(ctx context.Context, projectID string) ([]models.Schedule, error) {
+	filter := bson.M{"project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []models.Schedule{}
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// GetByID retrieves a single schedule in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *ScheduleRepository) GetByID(ctx context.Context, id, projectID string) (*models.Schedule, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var schedule models.Schedule
+	err := r.collection.FindOne(ctx, filter).Decode(&schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// Update applies the given field updates to a schedule in the project
+// Returns mongo.ErrNoDocuments if no matching schedule was found
+func (r *ScheduleRepository) Update(ctx context.Context, id, projectID string, updates bson.M) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	set := bson.M{"updated_at": time.Now()}
+	for field, value := range updates {
+		set[field] = value
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Delete removes a schedule in the project
+// Returns mongo.ErrNoDocuments if no matching schedule was found
+func (r *ScheduleRepository) Delete(ctx context.Context, id, projectID string) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// GetDue returns up to limit enabled schedules whose next fire time is not after now
+func (r *ScheduleRepository) GetDue(ctx context.Context, now time.Time, limit int64) ([]models.Schedule, error) {
+	filter := bson.M{"enabled": true, "next_fire_at": bson.M{"$lte": now}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_fire_at", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []models.Schedule{}
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// Claim moves a due schedule's next fire time from due to next and records firedAt
+// Returns mongo.ErrNoDocuments if the schedule was changed, disabled or claimed
+// by another replica since it was read
+func (r *ScheduleRepository) Claim(ctx context.Context, id string, due, next, firedAt time.Time) error {
+	filter := bson.M{"_id": id, "enabled": true, "next_fire_at": due}
+	update := bson.M{"$set": bson.M{
+		"next_fire_at": next,
+		"last_fire_at": firedAt,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Disable turns a schedule off and records why in its last error
+func (r *ScheduleRepository) Disable(ctx context.Context, id, errorMessage string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":    false,
+			"last_error": errorMessage,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{"next_fire_at": ""},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RecordFire stores the outcome of the last fire: the run it started, or why it failed
+func (r *ScheduleRepository) RecordFire(ctx context.Context, id, runID, errorMessage string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"last_run_id": runID,
+		"last_error":  errorMessage,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,119 @@
+package repository
+
+/**
+ * Session Repository
+ *
+ * Purpose: Handle all database operations for the sessions collection
+ *
+ * Operations:
+ * - Create / Get: Record and look up a login session
+ * - Extend: Push back a session's expiry after a refresh
+ * - Touch: Record where and when a session was last seen
+ * - ListActive: Get a user's sessions that can still be used
+ * - Revoke: End a session
+ *
+ * Revoked sessions are kept until they expire. The collection has a TTL
+ * index on expires_at.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type SessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates a new session repository instance
+func NewSessionRepository(db *mongo.Database) *SessionRepository {
+	return &SessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+// Create inserts a new session
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// Get retrieves a session in any state
+func (r *SessionRepository) Get(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Extend moves the expiry of session id to expiresAt
+// Sessions started before sessions were recorded are created on their next refresh.
+func (r *SessionRepository) Extend(ctx context.Context, id, userID string, now, expiresAt time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set":         bson.M{"expires_at": expiresAt},
+		"$setOnInsert": bson.M{"user_id": userID, "method": "", "device": "", "created_at": now},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Touch records that session id was seen at now from ip, and returns the session
+// Returns mongo.ErrNoDocuments if the session is not recorded.
+func (r *SessionRepository) Touch(ctx context.Context, id, ip string, now time.Time) (*models.Session, error) {
+	set := bson.M{"last_seen_at": now}
+	if ip != "" {
+		set["ip"] = ip
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.Session
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActive returns the unrevoked, unexpired sessions of a user, most recently created first
+func (r *SessionRepository) ListActive(ctx context.Context, userID string, now time.Time) ([]models.Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Revoke marks session id as revoked
+func (r *SessionRepository) Revoke(ctx context.Context, id string, now time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": now}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+/**
+ * Suite Repository
+ *
+ * Purpose: Handle all database operations for the suites collection
+ *
+ * Every query is scoped by the project ID, like the tests collection.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type SuiteRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSuiteRepository creates a new suite repository instance
+func NewSuiteRepository(db *mongo.Database) *SuiteRepository {
+	return &SuiteRepository{
+		collection: db.Collection("suites"),
+	}
+}
+
+// Create inserts a new suite in suite.ProjectID
+func (r *SuiteRepository) Create(ctx context.Context, suite *models.Suite) error {
+	suite.ID = primitive.NewObjectID().Hex()
+	suite.CreatedAt = time.Now()
+	suite.UpdatedAt = suite.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, suite)
+	return err
+}
+
+// GetAll returns every suite in the project, newest first
+func (r *SuiteRepository) GetAll(ctx context.Context, projectID string) ([]models.Suite, error) {
+	filter := bson.M{"project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := []models.Suite{}
+	if err := cursor.All(ctx, &suites); err != nil {
+		return nil, err
+	}
+
+	return suites, nil
+}
+
+// GetByID retrieves a single suite in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *SuiteRepository) GetByID(ctx context.Context, id, projectID string) (*models.Suite, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var suite models.Suite
+	err := r.collection.FindOne(ctx, filter).Decode(&suite)
+	if err != nil {
+		return nil, err
+	}
+
+	return &suite, nil
+}
+
+// Update applies the given field updates to a suite in the project
+// Returns mongo.ErrNoDocuments if no matching suite was found
+func (r *SuiteRepository) Update(ctx context.Context, id, projectID string, updates bson.M) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	set := bson.M{"updated_at": time.Now()}
+	for field, value := range updates {
+		set[field] = value
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Delete removes a suite in the project
+// Returns mongo.ErrNoDocuments if no matching suite was found
+func (r *SuiteRepository) Delete(ctx context.Context, id, projectID string) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
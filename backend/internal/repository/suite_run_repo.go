@@ -0,0 +1,96 @@
+package repository
+
+/**
+ * Suite Run Repository
+ *
+ * Purpose: Handle all database operations for the suite_runs collection
+ *
+ * Reads are scoped by the project ID. The aggregated status is only
+ * written once, when the last member run has finished.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type SuiteRunRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSuiteRunRepository creates a new suite run repository instance
+func NewSuiteRunRepository(db *mongo.Database) *SuiteRunRepository {
+	return &SuiteRunRepository{
+		collection: db.Collection("suite_runs"),
+	}
+}
+
+// Create inserts a new suite run
+func (r *SuiteRunRepository) Create(ctx context.Context, suiteRun *models.SuiteRun) error {
+	suiteRun.ID = primitive.NewObjectID().Hex()
+	suiteRun.QueuedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, suiteRun)
+	return err
+}
+
+// GetByID retrieves a suite run in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *SuiteRunRepository) GetByID(ctx context.Context, id, projectID string) (*models.SuiteRun, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var suiteRun models.SuiteRun
+	err := r.collection.FindOne(ctx, filter).Decode(&suiteRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return &suiteRun, nil
+}
+
+// ListBySuite returns the runs of one suite in the project, newest first
+func (r *SuiteRunRepository) ListBySuite(ctx context.Context, suiteID, projectID string) ([]models.SuiteRun, error) {
+	filter := bson.M{"suite_id": suiteID, "project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "queued_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	suiteRuns := []models.SuiteRun{}
+	if err := cursor.All(ctx, &suiteRuns); err != nil {
+		return nil, err
+	}
+
+	return suiteRuns, nil
+}
+
+// SetRunIDs records the member runs once they have been created
+func (r *SuiteRunRepository) SetRunIDs(ctx context.Context, id string, runIDs []string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"run_ids": runIDs}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// Finish stores the final aggregated status of a suite run that is still running
+func (r *SuiteRunRepository) Finish(ctx context.Context, id, status string, summary models.RunSummary, finishedAt time.Time) error {
+	filter := bson.M{"_id": id, "status": models.SuiteRunStatusRunning}
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"summary":     summary,
+		"finished_at": finishedAt,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -1,38 +1,113 @@
 package repository
 
+/**
+ * Test Repository
+ *
+ * Purpose: Handle all database operations for the tests collection
+ *
+ * Every query is scoped by the project ID so that members of one project
+ * can never read or modify another project's scripts. A test that exists
+ * but belongs to another project is reported exactly like a missing test.
+ */
+
 import (
 	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
 )
 
 type TestRepository struct {
-	// TODO: Add database connection
+	collection *mongo.Collection
 }
 
-func NewTestRepository() *TestRepository {
-	return &TestRepository{}
+// NewTestRepository creates a new test repository instance
+func NewTestRepository(db *mongo.Database) *TestRepository {
+	return &TestRepository{
+		collection: db.Collection("tests"),
+	}
 }
 
-func (r *TestRepository) Create(ctx context.Context, name, script, userID string) error {
-	// TODO: Implement test creation in database
-	return nil
+// Create inserts a new test in test.ProjectID
+func (r *TestRepository) Create(ctx context.Context, test *models.Test) error {
+	test.ID = primitive.NewObjectID().Hex()
+	test.CreatedAt = time.Now()
+	test.UpdatedAt = test.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, test)
+	return err
 }
 
-func (r *TestRepository) GetAll(ctx context.Context, userID string) ([]interface{}, error) {
-	// TODO: Implement get all tests
-	return nil, nil
+// GetAll returns every test in the project, newest first
+func (r *TestRepository) GetAll(ctx context.Context, projectID string) ([]models.Test, error) {
+	filter := bson.M{"project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tests := []models.Test{}
+	if err := cursor.All(ctx, &tests); err != nil {
+		return nil, err
+	}
+
+	return tests, nil
 }
 
-func (r *TestRepository) GetByID(ctx context.Context, id string) (interface{}, error) {
-	// TODO: Implement get test by ID
-	return nil, nil
+// GetByID retrieves a single test in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *TestRepository) GetByID(ctx context.Context, id, projectID string) (*models.Test, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var test models.Test
+	err := r.collection.FindOne(ctx, filter).Decode(&test)
+	if err != nil {
+		return nil, err
+	}
+
+	return &test, nil
 }
 
-func (r *TestRepository) Update(ctx context.Context, id string, updates map[string]interface{}) error {
-	// TODO: Implement test update
+// Update applies the given field updates to a test in the project
+// Returns mongo.ErrNoDocuments if no matching test was found
+func (r *TestRepository) Update(ctx context.Context, id, projectID string, updates bson.M) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	set := bson.M{"updated_at": time.Now()}
+	for field, value := range updates {
+		set[field] = value
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
 
-func (r *TestRepository) Delete(ctx context.Context, id string) error {
-	// TODO: Implement test deletion
+// Delete removes a test in the project
+// Returns mongo.ErrNoDocuments if no matching test was found
+func (r *TestRepository) Delete(ctx context.Context, id, projectID string) error {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
@@ -0,0 +1,168 @@
+package repository
+
+/**
+ * Test Run Repository
+ *
+ * Purpose: Handle all database operations for the test_runs collection
+ *
+ * Reads are scoped by the project ID. Status changes are conditional
+ * on the current status so that two concurrent transitions (for example a
+ * cancel racing a runner picking the job up) can never both succeed.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type TestRunRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTestRunRepository creates a new test run repository instance
+func NewTestRunRepository(db *mongo.Database) *TestRunRepository {
+	return &TestRunRepository{
+		collection: db.Collection("test_runs"),
+	}
+}
+
+// Create inserts a new run
+func (r *TestRunRepository) Create(ctx context.Context, run *models.TestRun) error {
+	run.ID = primitive.NewObjectID().Hex()
+	run.QueuedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, run)
+	return err
+}
+
+// GetByID retrieves a run in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *TestRunRepository) GetByID(ctx context.Context, id, projectID string) (*models.TestRun, error) {
+	filter := bson.M{"_id": id, "project_id": projectID}
+
+	var run models.TestRun
+	err := r.collection.FindOne(ctx, filter).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// FindByID retrieves a run without project scoping
+// Only for runner-side operations that act on behalf of the system, never for user requests
+func (r *TestRunRepository) FindByID(ctx context.Context, id string) (*models.TestRun, error) {
+	var run models.TestRun
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// GetByJobID retrieves the run in the project that was enqueued as a queue job
+// Returns mongo.ErrNoDocuments if there is none
+func (r *TestRunRepository) GetByJobID(ctx context.Context, jobID, projectID string) (*models.TestRun, error) {
+	filter := bson.M{"job_id": jobID, "project_id": projectID}
+
+	var run models.TestRun
+	err := r.collection.FindOne(ctx, filter).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// ListByTest returns the runs of one test in the project, newest first
+func (r *TestRunRepository) ListByTest(ctx context.Context, testID, projectID string) ([]models.TestRun, error) {
+	filter := bson.M{"test_id": testID, "project_id": projectID}
+	opts := options.Find().SetSort(bson.D{{Key: "queued_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []models.TestRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// ListBySuiteRun returns the member runs of a suite run in the project
+func (r *TestRunRepository) ListBySuiteRun(ctx context.Context, suiteRunID, projectID string) ([]models.TestRun, error) {
+	filter := bson.M{"suite_run_id": suiteRunID, "project_id": projectID}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []models.TestRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// UpdateStatus moves a run to status, but only if its current status is one of from
+// Extra fields in set are written in the same update
+// Returns mongo.ErrNoDocuments if the run does not exist or is not in one of the from statuses
+func (r *TestRunRepository) UpdateStatus(ctx context.Context, id string, from []string, status string, set bson.M) error {
+	filter := bson.M{"_id": id, "status": bson.M{"$in": from}}
+
+	fields := bson.M{"status": status}
+	for field, value := range set {
+		fields[field] = value
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetJobID records the queue job that will execute the run
+func (r *TestRunRepository) SetJobID(ctx context.Context, id, jobID string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"job_id": jobID}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// ReserveLogLines reserves n log sequence numbers on a running run and returns the last one
+// The reserved numbers are last-n+1 through last
+// Returns mongo.ErrNoDocuments if the run does not exist or is not running
+func (r *TestRunRepository) ReserveLogLines(ctx context.Context, id string, n int) (int64, error) {
+	filter := bson.M{"_id": id, "status": models.RunStatusRunning}
+	update := bson.M{"$inc": bson.M{"log_lines": n}}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"log_lines": 1})
+
+	var run models.TestRun
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&run)
+	if err != nil {
+		return 0, err
+	}
+
+	return run.LogLines, nil
+}
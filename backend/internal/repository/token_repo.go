@@ -0,0 +1,131 @@
+package repository
+
+/**
+ * Token Repository
+ *
+ * Purpose: Handle all database operations for the refresh_tokens and
+ * revoked_tokens collections
+ *
+ * Both collections have a TTL index on expires_at, so MongoDB removes
+ * entries once they can no longer matter.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type TokenRepository struct {
+	refreshTokens *mongo.Collection
+	revokedTokens *mongo.Collection
+}
+
+// NewTokenRepository creates a new token repository instance
+func NewTokenRepository(db *mongo.Database) *TokenRepository {
+	return &TokenRepository{
+		refreshTokens: db.Collection("refresh_tokens"),
+		revokedTokens: db.Collection("revoked_tokens"),
+	}
+}
+
+// CreateRefreshToken inserts a new refresh token
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	token.CreatedAt = time.Now()
+
+	_, err := r.refreshTokens.InsertOne(ctx, token)
+	return err
+}
+
+// UseRefreshToken marks an unused, unrevoked, unexpired refresh token as used and returns it
+// Returns mongo.ErrNoDocuments if no such token exists; FindRefreshToken tells why
+func (r *TokenRepository) UseRefreshToken(ctx context.Context, id string, now time.Time) (*models.RefreshToken, error) {
+	filter := bson.M{
+		"_id":        id,
+		"used_at":    nil,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var token models.RefreshToken
+	err := r.refreshTokens.FindOneAndUpdate(ctx, filter, update).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// FindRefreshToken retrieves a refresh token in any state
+func (r *TokenRepository) FindRefreshToken(ctx context.Context, id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.refreshTokens.FindOne(ctx, bson.M{"_id": id}).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RevokeFamily revokes every refresh token of a family
+func (r *TokenRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	filter := bson.M{"family_id": familyID, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": now}}
+
+	_, err := r.refreshTokens.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// ActiveFamilies returns the IDs of a user's sessions that still have a usable refresh token
+func (r *TokenRepository) ActiveFamilies(ctx context.Context, userID string, now time.Time) ([]string, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"used_at":    nil,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	values, err := r.refreshTokens.Distinct(ctx, "family_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	families := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			families = append(families, id)
+		}
+	}
+
+	return families, nil
+}
+
+// Revoke records a revoked jti or family ID until expiresAt
+func (r *TokenRepository) Revoke(ctx context.Context, id string, expiresAt time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+		"$max": bson.M{"expires_at": expiresAt},
+	}
+
+	_, err := r.revokedTokens.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// IsRevoked reports whether any of the given jtis or family IDs has been revoked
+func (r *TokenRepository) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+
+	count, err := r.revokedTokens.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
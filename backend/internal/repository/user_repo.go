@@ -10,14 +10,26 @@ package repository
  * - CreateUser: Insert new user into database
  * - CheckEmailExists: Verify if email already registered
  * - CheckUsernameExists: Verify if username already taken
+ * - GetUserByID / GetUserByEmail: Look up a user
+ * - UpdateUserPassword / UpdateUserRole: Change a user's password or role
+ * - SetPasswordByID / MarkEmailVerified: Account changes from emailed links
+ * - SetTOTPSecret / EnableTOTP / DisableTOTP / UseTOTPStep / UseRecoveryCode /
+ *   SetRecoveryCodes: Two-factor authentication
+ * - ListUsers / SetDisabled / DeleteUser: User administration
+ * - GetUserByIdentity / AddIdentity / RemoveIdentity: Linked login provider accounts
+ *
+ * User IDs are stored as ObjectIDs and exposed as their hex string.
  */
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/models"
 )
@@ -40,8 +52,16 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) erro
 	user.UpdatedAt = time.Now()
 
 	// Insert into database
-	_, err := r.collection.InsertOne(ctx, user)
-	return err
+	result, err := r.collection.InsertOne(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	// Expose the generated ID to the caller
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		user.ID = id.Hex()
+	}
+	return nil
 }
 
 // CheckEmailExists checks if an email already exists in database
@@ -68,6 +88,25 @@ func (r *UserRepository) CheckUsernameExists(ctx context.Context, username strin
 	return count > 0, nil
 }
 
+// GetUserByID retrieves a user by their ID
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, userIDFilter(id)).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// userIDFilter matches a user by ID, whether its _id is stored as an ObjectID or a string
+func userIDFilter(id string) bson.M {
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		return bson.M{"_id": bson.M{"$in": bson.A{oid, id}}}
+	}
+	return bson.M{"_id": id}
+}
+
 // GetUserByEmail retrieves a user by their email address
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	filter := bson.M{"email": email}
@@ -95,4 +134,298 @@ func (r *UserRepository) UpdateUserPassword(ctx context.Context, email, password
 	return err
 }
 
+// SetPasswordByID sets the password of a user identified by ID
+// Returns mongo.ErrNoDocuments if the user does not exist
+func (r *UserRepository) SetPasswordByID(ctx context.Context, id, password string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"password":   password,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, userIDFilter(id), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that a user owns email
+// Nothing is changed if the user's address is no longer email; returns
+// mongo.ErrNoDocuments in that case or if the user does not exist.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id, email string) error {
+	filter := userIDFilter(id)
+	filter["email"] = email
+	update := bson.M{
+		"$set": bson.M{
+			"email_verified": true,
+			"updated_at":     time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetTOTPSecret stores a new, not yet enabled TOTP secret for a user
+// Returns mongo.ErrNoDocuments if the user does not exist or already has TOTP enabled
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id, secret string) error {
+	filter := userIDFilter(id)
+	filter["totp.enabled"] = bson.M{"$ne": true}
+	update := bson.M{
+		"$set": bson.M{
+			"totp":       bson.M{"enabled": false, "secret": secret},
+			"updated_at": time.Now(),
+		},
+	}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// EnableTOTP turns on a user's stored TOTP secret
+// step is the time step of the code that confirmed it.
+func (r *UserRepository) EnableTOTP(ctx context.Context, id string, step int64, recoveryCodes []string) error {
+	filter := userIDFilter(id)
+	filter["totp.enabled"] = false
+	update := bson.M{
+		"$set": bson.M{
+			"totp.enabled":        true,
+			"totp.last_step":      step,
+			"totp.recovery_codes": recoveryCodes,
+			"updated_at":          time.Now(),
+		},
+	}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// DisableTOTP removes a user's TOTP secret and recovery codes
+func (r *UserRepository) DisableTOTP(ctx context.Context, id string) error {
+	update := bson.M{
+		"$unset": bson.M{"totp": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateOne(ctx, userIDFilter(id), update)
+}
+
+// UseTOTPStep records step as the last accepted TOTP time step
+// Returns mongo.ErrNoDocuments if a code of this or a later step was already
+// accepted, so two requests cannot use the same code.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	filter := userIDFilter(id)
+	filter["totp.enabled"] = true
+	filter["$or"] = bson.A{
+		bson.M{"totp.last_step": bson.M{"$lt": step}},
+		bson.M{"totp.last_step": bson.M{"$exists": false}},
+	}
+	update := bson.M{"$set": bson.M{"totp.last_step": step}}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// UseRecoveryCode removes a recovery code (by hash) from a user
+// Returns mongo.ErrNoDocuments if the user has no such unused code.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) error {
+	filter := userIDFilter(id)
+	filter["totp.enabled"] = true
+	filter["totp.recovery_codes"] = codeHash
+	update := bson.M{"$pull": bson.M{"totp.recovery_codes": codeHash}}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// SetRecoveryCodes replaces a user's recovery codes (by hash)
+func (r *UserRepository) SetRecoveryCodes(ctx context.Context, id string, recoveryCodes []string) error {
+	filter := userIDFilter(id)
+	filter["totp.enabled"] = true
+	update := bson.M{
+		"$set": bson.M{
+			"totp.recovery_codes": recoveryCodes,
+			"updated_at":          time.Now(),
+		},
+	}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// updateOne applies update to the user matching filter
+// Returns mongo.ErrNoDocuments if no user matches
+func (r *UserRepository) updateOne(ctx context.Context, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UpdateUserRole sets the role of a user identified by ID
+// Returns mongo.ErrNoDocuments if the user does not exist
+func (r *UserRepository) UpdateUserRole(ctx context.Context, id, role string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"role":       role,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, userIDFilter(id), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ==================================================
+// USER ADMINISTRATION
+// ==================================================
+
+// UserFilter selects users for ListUsers; zero fields match everything
+type UserFilter struct {
+	Search   string // case-insensitive substring of the username or email
+	Role     string
+	Disabled *bool
+}
+
+// ListUsers returns up to limit matching users after skipping skip, newest
+// first, and how many users match in total
+func (r *UserRepository) ListUsers(ctx context.Context, filter UserFilter, skip, limit int64) ([]models.User, int64, error) {
+	query := bson.M{}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = bson.A{bson.M{"username": pattern}, bson.M{"email": pattern}}
+	}
+	if filter.Role != "" {
+		query["role"] = filter.Role
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query["disabled"] = true
+		} else {
+			query["disabled"] = bson.M{"$ne": true}
+		}
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// SetDisabled disables or re-enables a user
+// Returns mongo.ErrNoDocuments if the user does not exist
+func (r *UserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"disabled": true, "disabled_at": now, "updated_at": now},
+	}
+	if !disabled {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": now},
+			"$unset": bson.M{"disabled": "", "disabled_at": ""},
+		}
+	}
+
+	return r.updateOne(ctx, userIDFilter(id), update)
+}
+
+// DeleteUser removes a user
+// Returns mongo.ErrNoDocuments if the user does not exist
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, userIDFilter(id))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ==================================================
+// IDENTITIES
+// ==================================================
+
+// GetUserByIdentity retrieves the user a provider account is linked to
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	filter := bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}},
+	}
 
+	var user models.User
+	if err := r.collection.FindOne(ctx, filter).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// AddIdentity links a provider account to a user who has none of that provider yet
+// Returns mongo.ErrNoDocuments if the user does not exist or already has an
+// identity of the provider, and a duplicate key error if the account is
+// linked to another user.
+func (r *UserRepository) AddIdentity(ctx context.Context, id string, identity models.Identity) error {
+	filter := userIDFilter(id)
+	filter["identities.provider"] = bson.M{"$ne": identity.Provider}
+	update := bson.M{
+		"$push": bson.M{"identities": identity},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateOne(ctx, filter, update)
+}
+
+// RemoveIdentity unlinks a user's identity of provider, unless it is their only way to log in
+// Returns mongo.ErrNoDocuments if the user has no such identity, or no
+// password and no other identity.
+func (r *UserRepository) RemoveIdentity(ctx context.Context, id, provider string) error {
+	filter := userIDFilter(id)
+	filter["identities.provider"] = provider
+	filter["$or"] = bson.A{
+		bson.M{"password": bson.M{"$nin": bson.A{"", nil}}},
+		bson.M{"identities.1": bson.M{"$exists": true}},
+	}
+	update := bson.M{
+		"$pull": bson.M{"identities": bson.M{"provider": provider}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateOne(ctx, filter, update)
+}
@@ -0,0 +1,102 @@
+package repository
+
+/**
+ * Worker Registration Token Repository
+ *
+ * Purpose: Handle all database operations for the worker_registration_tokens collection
+ *
+ * Tokens are looked up by the SHA-256 of the token (unique index on
+ * token_hash); listing and deleting are scoped by the project ID. The
+ * collection has a TTL index on expires_at, so used tokens are kept until
+ * they would have expired.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type WorkerRegistrationTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWorkerRegistrationTokenRepository creates a new worker registration token repository instance
+func NewWorkerRegistrationTokenRepository(db *mongo.Database) *WorkerRegistrationTokenRepository {
+	return &WorkerRegistrationTokenRepository{
+		collection: db.Collection("worker_registration_tokens"),
+	}
+}
+
+// Create inserts a new token
+func (r *WorkerRegistrationTokenRepository) Create(ctx context.Context, token *models.WorkerRegistrationToken) error {
+	token.ID = primitive.NewObjectID().Hex()
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// ListByProject returns the unexpired tokens of a project, newest first
+func (r *WorkerRegistrationTokenRepository) ListByProject(ctx context.Context, projectID string, now time.Time) ([]models.WorkerRegistrationToken, error) {
+	filter := bson.M{"project_id": projectID, "expires_at": bson.M{"$gt": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []models.WorkerRegistrationToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Use marks an unused, unexpired token as used and returns it
+// Returns mongo.ErrNoDocuments if there is no such token, so a token works only once.
+func (r *WorkerRegistrationTokenRepository) Use(ctx context.Context, tokenHash string, now time.Time) (*models.WorkerRegistrationToken, error) {
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.WorkerRegistrationToken
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// SetWorker records the worker that registered with a token
+func (r *WorkerRegistrationTokenRepository) SetWorker(ctx context.Context, id, workerID string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"worker_id": workerID}})
+	return err
+}
+
+// Delete removes a token of the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *WorkerRegistrationTokenRepository) Delete(ctx context.Context, id, projectID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "project_id": projectID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -1,33 +1,195 @@
 package repository
 
+/**
+ * Worker Repository
+ *
+ * Purpose: Handle all database operations for the workers collection
+ *
+ * A worker belongs to one project and only executes its runs. Queries made
+ * for a runner or a user are scoped by the project ID; the reaper looks for
+ * silent workers across all projects. Runners are looked up by the SHA-256
+ * of their credential.
+ */
+
 import (
 	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
 )
 
 type WorkerRepository struct {
-	// TODO: Add database connection
+	collection *mongo.Collection
+}
+
+// NewWorkerRepository creates a new worker repository instance
+func NewWorkerRepository(db *mongo.Database) *WorkerRepository {
+	return &WorkerRepository{
+		collection: db.Collection("workers"),
+	}
+}
+
+// Create inserts a newly registered worker
+func (r *WorkerRepository) Create(ctx context.Context, worker *models.Worker) error {
+	worker.ID = primitive.NewObjectID().Hex()
+	worker.CreatedAt = time.Now()
+	worker.LastPing = worker.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, worker)
+	return err
 }
 
-func NewWorkerRepository() *WorkerRepository {
-	return &WorkerRepository{}
+// GetAll returns every worker registered in the project ordered by name
+func (r *WorkerRepository) GetAll(ctx context.Context, projectID string) ([]models.Worker, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"project_id": projectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := []models.Worker{}
+	if err := cursor.All(ctx, &workers); err != nil {
+		return nil, err
+	}
+
+	return workers, nil
+}
+
+// GetByCredentialHash retrieves the worker holding a credential
+func (r *WorkerRepository) GetByCredentialHash(ctx context.Context, credentialHash string) (*models.Worker, error) {
+	var worker models.Worker
+	err := r.collection.FindOne(ctx, bson.M{"credential_hash": credentialHash}).Decode(&worker)
+	if err != nil {
+		return nil, err
+	}
+
+	return &worker, nil
 }
 
-func (r *WorkerRepository) Create(ctx context.Context, name string) error {
-	// TODO: Implement worker creation in database
+// RevokeCredential removes a worker's credential; the worker record stays
+// Returns mongo.ErrNoDocuments if the worker does not exist in the project
+func (r *WorkerRepository) RevokeCredential(ctx context.Context, id, projectID string) error {
+	update := bson.M{"$unset": bson.M{"credential_hash": "", "credential_hint": ""}}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "project_id": projectID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
 
-func (r *WorkerRepository) GetAll(ctx context.Context) ([]interface{}, error) {
-	// TODO: Implement get all workers
-	return nil, nil
+// GetByID retrieves a single worker in the project
+// Returns mongo.ErrNoDocuments if it does not exist or belongs to another project
+func (r *WorkerRepository) GetByID(ctx context.Context, id, projectID string) (*models.Worker, error) {
+	var worker models.Worker
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "project_id": projectID}).Decode(&worker)
+	if err != nil {
+		return nil, err
+	}
+
+	return &worker, nil
 }
 
-func (r *WorkerRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	// TODO: Implement worker status update
+// UpdateStatus records a worker's reported status and current job
+// A status report also counts as a ping
+// Returns mongo.ErrNoDocuments if the worker does not exist in the project
+func (r *WorkerRepository) UpdateStatus(ctx context.Context, id, projectID, status, currentJob string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":      status,
+			"current_job": currentJob,
+			"last_ping":   time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "project_id": projectID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
 
-func (r *WorkerRepository) UpdatePing(ctx context.Context, id string) error {
-	// TODO: Implement worker ping update
+// UpdatePing records a heartbeat; an offline worker that pings again comes back as idle
+// Returns mongo.ErrNoDocuments if the worker does not exist in the project
+func (r *WorkerRepository) UpdatePing(ctx context.Context, id, projectID string) error {
+	now := time.Now()
+
+	// Pipeline update so only offline workers have their status reset
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"last_ping": now,
+		"status": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$status", models.WorkerStatusOffline}},
+			models.WorkerStatusIdle,
+			"$status",
+		}},
+	}}}}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "project_id": projectID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
+
+// GetSilentSince returns online workers whose last ping is older than cutoff
+func (r *WorkerRepository) GetSilentSince(ctx context.Context, cutoff time.Time) ([]models.Worker, error) {
+	filter := bson.M{
+		"status":    bson.M{"$ne": models.WorkerStatusOffline},
+		"last_ping": bson.M{"$lt": cutoff},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := []models.Worker{}
+	if err := cursor.All(ctx, &workers); err != nil {
+		return nil, err
+	}
+
+	return workers, nil
+}
+
+// MarkOffline marks a worker offline if it is still silent since cutoff and clears its job
+// Returns the worker as it was before the update, or mongo.ErrNoDocuments if it
+// pinged in the meantime or another backend replica already marked it offline
+func (r *WorkerRepository) MarkOffline(ctx context.Context, id string, cutoff time.Time) (*models.Worker, error) {
+	filter := bson.M{
+		"_id":       id,
+		"status":    bson.M{"$ne": models.WorkerStatusOffline},
+		"last_ping": bson.M{"$lt": cutoff},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      models.WorkerStatusOffline,
+			"current_job": "",
+		},
+	}
+
+	var worker models.Worker
+	err := r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&worker)
+	if err != nil {
+		return nil, err
+	}
+
+	return &worker, nil
+}
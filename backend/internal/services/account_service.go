@@ -0,0 +1,317 @@
+package services
+
+/**
+ * Account Service
+ *
+ * Purpose: Prove ownership of an email address through emailed links
+ *
+ * Operations:
+ * - SendVerification / ResendVerification: Email an address verification link
+ * - VerifyEmail: Use a verification link and mark the address verified
+ * - RequestPasswordReset / SendForcedPasswordReset: Email a password reset link
+ * - ResetPassword: Use a reset link, set a new password and end every session
+ *
+ * A link token is "<id>.<expiry>.<signature>": the signature is an
+ * HMAC-SHA256 over the purpose, ID and expiry, so forged or altered tokens
+ * are rejected without a database lookup. The stored record (see
+ * models.EmailToken) makes each token single-use, and requesting a new link
+ * invalidates the previous ones.
+ */
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/mail"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+var (
+	// ErrInvalidEmailToken is returned for forged, expired, used or superseded link tokens
+	ErrInvalidEmailToken = errors.New("invalid or expired link")
+
+	// ErrEmailAlreadyVerified is returned when asking to verify an address that is already verified
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+// AccountConfig holds the settings for emailed links
+type AccountConfig struct {
+	// BaseURL is the frontend URL the links point to
+	BaseURL string
+
+	// Secret signs link tokens
+	Secret string
+
+	// VerificationTTL and ResetTTL are how long each kind of link stays valid
+	VerificationTTL time.Duration
+	ResetTTL        time.Duration
+}
+
+type AccountService struct {
+	userRepo       *repository.UserRepository
+	emailTokenRepo *repository.EmailTokenRepository
+	tokenService   *TokenService
+	mailer         mail.Mailer
+	audit          *AuditService
+	cfg            AccountConfig
+}
+
+// NewAccountService creates a new account service instance
+func NewAccountService(userRepo *repository.UserRepository, emailTokenRepo *repository.EmailTokenRepository, tokenService *TokenService, mailer mail.Mailer, audit *AuditService, cfg AccountConfig) *AccountService {
+	return &AccountService{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		tokenService:   tokenService,
+		mailer:         mailer,
+		audit:          audit,
+		cfg:            cfg,
+	}
+}
+
+// ResetPasswordRequest represents the data needed to reset a password
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ==================================================
+// EMAIL VERIFICATION
+// ==================================================
+
+// SendVerification emails user a link that verifies their address
+func (s *AccountService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issue(ctx, user, models.EmailTokenVerifyEmail, s.cfg.VerificationTTL)
+	if err != nil {
+		return errors.New("failed to send verification email")
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your TestOps email address",
+		Body: fmt.Sprintf("Hi %s,\n\nconfirm your email address by opening this link:\n\n%s\n\nThe link expires in %s. If you did not sign up for TestOps, ignore this email.\n",
+			user.Username, s.link("verify_token", token), s.cfg.VerificationTTL),
+	})
+	if err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		return errors.New("failed to send verification email")
+	}
+
+	return nil
+}
+
+// ResendVerification emails a new verification link to the user with userID
+func (s *AccountService) ResendVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail uses a verification token and marks the address it was sent to as verified
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	record, err := s.use(ctx, token, models.EmailTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	// The link only verifies the address it was sent to
+	err = s.userRepo.MarkEmailVerified(ctx, record.UserID, record.Email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return errors.New("failed to verify email")
+	}
+
+	// The link's owner acts on their own account
+	s.audit.Record(ctx, models.AuditEvent{
+		ActorID:    record.UserID,
+		ActorEmail: record.Email,
+		Action:     models.AuditEmailVerified,
+		TargetType: models.AuditTargetUser,
+		TargetID:   record.UserID,
+		After:      map[string]interface{}{"email": record.Email, "email_verified": true},
+	})
+
+	return nil
+}
+
+// ==================================================
+// PASSWORD RESET
+// ==================================================
+
+// RequestPasswordReset emails a password reset link to the user with email
+// Unknown addresses are ignored without an error, so the endpoint does not
+// reveal which addresses have an account.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to request password reset")
+	}
+
+	return s.sendPasswordReset(ctx, user, "If you did not ask to reset your password, ignore this email.")
+}
+
+// SendForcedPasswordReset emails a password reset link after an admin cleared the user's password
+func (s *AccountService) SendForcedPasswordReset(ctx context.Context, user *models.User) error {
+	return s.sendPasswordReset(ctx, user, "An administrator has reset your password; you need to set a new one before you can log in with a password again.")
+}
+
+// sendPasswordReset emails user a password reset link, followed by note
+func (s *AccountService) sendPasswordReset(ctx context.Context, user *models.User, note string) error {
+	token, err := s.issue(ctx, user, models.EmailTokenResetPassword, s.cfg.ResetTTL)
+	if err != nil {
+		return errors.New("failed to request password reset")
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your TestOps password",
+		Body: fmt.Sprintf("Hi %s,\n\nset a new password by opening this link:\n\n%s\n\nThe link expires in %s and can be used once. %s\n",
+			user.Username, s.link("reset_token", token), s.cfg.ResetTTL, note),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+		return errors.New("failed to request password reset")
+	}
+
+	return nil
+}
+
+// ResetPassword uses a reset token and sets a new password
+// The reset link proves ownership of the address, so it also verifies it.
+// Every session of the user is ended.
+func (s *AccountService) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	if err := validatePassword(req.Password); err != nil {
+		return err
+	}
+
+	record, err := s.use(ctx, req.Token, models.EmailTokenResetPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, record.UserID)
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && user.Email != record.Email {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+	if err := s.userRepo.SetPasswordByID(ctx, user.ID, passwordHash); err != nil {
+		return errors.New("failed to reset password")
+	}
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+		log.Printf("Failed to mark email of user %s verified: %v", user.ID, err)
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		ActorID:    user.ID,
+		ActorEmail: user.Email,
+		Action:     models.AuditPasswordReset,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     map[string]interface{}{"has_password": user.Password != ""},
+		After:      map[string]interface{}{"has_password": true},
+	})
+
+	return s.tokenService.LogoutAll(ctx, user.ID)
+}
+
+// ==================================================
+// TOKENS
+// ==================================================
+
+// issue invalidates the user's outstanding tokens of purpose and returns a new one
+func (s *AccountService) issue(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := s.emailTokenRepo.InvalidateUnused(ctx, user.ID, purpose, now); err != nil {
+		return "", err
+	}
+
+	record := &models.EmailToken{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.emailTokenRepo.Create(ctx, record); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(record.ExpiresAt.Unix(), 10)
+	return record.ID + "." + expires + "." + s.sign(purpose, record.ID, expires), nil
+}
+
+// use checks a token's signature and expiry and marks it used
+func (s *AccountService) use(ctx context.Context, token, purpose string) (*models.EmailToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidEmailToken
+	}
+	id, expires, signature := parts[0], parts[1], parts[2]
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(purpose, id, expires))) {
+		return nil, ErrInvalidEmailToken
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, ErrInvalidEmailToken
+	}
+
+	record, err := s.emailTokenRepo.Use(ctx, id, purpose, time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to check link")
+	}
+
+	return record, nil
+}
+
+// sign returns the URL-safe HMAC-SHA256 of a token's purpose, ID and expiry
+func (s *AccountService) sign(purpose, id, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(purpose + "|" + id + "|" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// link returns the frontend URL that hands token to the auth page as param
+func (s *AccountService) link(param, token string) string {
+	return strings.TrimRight(s.cfg.BaseURL, "/") + "/auth?" + param + "=" + url.QueryEscape(token)
+}
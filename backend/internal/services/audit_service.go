@@ -0,0 +1,172 @@
+package services
+
+/**
+ * Audit Service
+ *
+ * Purpose: Record security-relevant and destructive actions
+ *
+ * Operations:
+ * - Record: Append an event, attributed to the actor of the request
+ * - Query: Read events filtered by actor, action and time range
+ * - Export: Stream every matching event (JSON Lines export)
+ *
+ * The actor (user, client IP and user agent) travels in the request context:
+ * the client IP middleware stores the address, the auth middleware the user
+ * (see WithAuditActor). Failing to record an event is logged but does not
+ * fail the action itself.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// ErrInvalidAuditQuery is returned for queries whose time range is reversed
+var ErrInvalidAuditQuery = errors.New("from must be before to")
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditActor is who makes a request
+type AuditActor struct {
+	UserID    string
+	Email     string
+	IP        string
+	UserAgent string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context whose recorded events are attributed to actor
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor stored by WithAuditActor
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
+
+// AuditQuery selects audit events
+type AuditQuery struct {
+	Actor  string    // user ID, or email if it contains "@"
+	Action string    // exact action, or a prefix ending in "*" such as "auth.*"
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+	Before string    // event ID to page back from
+	Limit  int       // page size for Query (default 100, at most 1000)
+}
+
+type AuditService struct {
+	auditRepo *repository.AuditEventRepository
+}
+
+// NewAuditService creates a new audit service instance
+func NewAuditService(auditRepo *repository.AuditEventRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends an event
+// The actor fields the event leaves empty, the client IP and the user agent
+// are taken from the context.
+func (s *AuditService) Record(ctx context.Context, event models.AuditEvent) {
+	actor := AuditActorFromContext(ctx)
+	if event.ActorID == "" && event.ActorEmail == "" {
+		event.ActorID = actor.UserID
+		event.ActorEmail = actor.Email
+	}
+	event.IP = actor.IP
+	event.UserAgent = actor.UserAgent
+	event.Time = time.Now()
+
+	// The action already happened, so record it even if the client went away
+	if err := s.auditRepo.Insert(context.WithoutCancel(ctx), &event); err != nil {
+		log.Printf("Failed to record audit event %s on %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	}
+}
+
+// Query returns a page of matching events, newest first
+func (s *AuditService) Query(ctx context.Context, query AuditQuery) ([]models.AuditEvent, error) {
+	filter, err := query.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.auditRepo.Find(ctx, filter, int64(query.PageSize()))
+	if err != nil {
+		return nil, errors.New("failed to retrieve audit events")
+	}
+
+	return events, nil
+}
+
+// Export calls fn with every matching event, newest first; Limit is ignored
+func (s *AuditService) Export(ctx context.Context, query AuditQuery, fn func(*models.AuditEvent) error) error {
+	filter, err := query.filter()
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepo.Each(ctx, filter, fn)
+}
+
+// PageSize returns how many events Query returns at most
+func (q AuditQuery) PageSize() int {
+	switch {
+	case q.Limit <= 0:
+		return defaultAuditLimit
+	case q.Limit > maxAuditLimit:
+		return maxAuditLimit
+	default:
+		return q.Limit
+	}
+}
+
+// filter converts the query into a repository filter
+func (q AuditQuery) filter() (repository.AuditEventFilter, error) {
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return repository.AuditEventFilter{}, ErrInvalidAuditQuery
+	}
+
+	filter := repository.AuditEventFilter{From: q.From, To: q.To, BeforeID: q.Before}
+	if strings.Contains(q.Actor, "@") {
+		filter.ActorEmail = q.Actor
+	} else {
+		filter.ActorID = q.Actor
+	}
+	if prefix, ok := strings.CutSuffix(q.Action, "*"); ok {
+		filter.ActionPrefix = prefix
+	} else {
+		filter.Action = q.Action
+	}
+
+	return filter, nil
+}
+
+// AuditSnapshot returns v as the API shows it, for an event's Before or After
+// Going through the JSON encoding leaves out every field tagged json:"-",
+// such as password hashes and TOTP secrets.
+func AuditSnapshot(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
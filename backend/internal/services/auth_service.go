@@ -9,27 +9,43 @@ package services
  * Operations:
  * - CreateUser: Validate and create new user
  * - LoginUser: Validate credentials and return user
+ * - SetUserPassword: Set or change the caller's own password
+ * - Passwords are stored as argon2id hashes (see utils/password.go);
+ *   legacy plaintext passwords are upgraded on the next successful login
  * - Automatically sets role to "tester"
  * - Validates email and username uniqueness
+ * - New users and password changes are recorded in the audit log
  */
 
 import (
 	"context"
 	"errors"
+	"log"
 	"strings"
 
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+var (
+	// ErrInvalidCredentials is returned by LoginUser for a wrong email or password
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrIncorrectPassword is returned by SetUserPassword for a wrong current password
+	ErrIncorrectPassword = errors.New("current password is incorrect")
 )
 
 type UserService struct {
 	userRepo *repository.UserRepository
+	audit    *AuditService
 }
 
 // NewUserService creates a new user service instance
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, audit *AuditService) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		audit:    audit,
 	}
 }
 
@@ -79,11 +95,16 @@ func (s *UserService) CreateUser(ctx context.Context, req SignupRequest) (*model
 	// CREATE USER
 	// ==================================================
 	
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, errors.New("failed to create user")
+	}
+
 	user := &models.User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: req.Password, // Plain text for now (no hashing)
-		Role:     "tester",     // Automatically set role to tester
+		Password: passwordHash,
+		Role:     models.RoleTester, // Automatically set role to tester
 	}
 
 	// Save to database
@@ -98,100 +119,140 @@ func (s *UserService) CreateUser(ctx context.Context, req SignupRequest) (*model
 		return nil, errors.New("failed to retrieve created user")
 	}
 
+	s.recordUserCreated(ctx, createdUser, "password")
+
 	return createdUser, nil
 }
 
-// CreateGoogleUser creates or retrieves a user authenticated via Google OAuth
-// This method handles users signing up/logging in with their Google account
-func (s *UserService) CreateGoogleUser(ctx context.Context, name, email, picture string) (*models.User, error) {
+// CreateOIDCUser creates a user for a provider account that is not linked to anyone yet
+// This method handles users signing up with Google, GitLab, Keycloak, ...
+// The provider must have verified the email; logging in to an existing user
+// is decided by OIDCService.
+func (s *UserService) CreateOIDCUser(ctx context.Context, name, picture string, identity models.Identity) (*models.User, error) {
 	// ==================================================
 	// VALIDATION
 	// ==================================================
 	
-	if strings.TrimSpace(email) == "" {
+	if strings.TrimSpace(identity.Email) == "" {
 		return nil, errors.New("email is required")
 	}
 	if strings.TrimSpace(name) == "" {
 		return nil, errors.New("name is required")
 	}
 
-	// ==================================================
-	// CHECK IF USER EXISTS
-	// ==================================================
-	
-	// Check if user with this email already exists
-	emailExists, err := s.userRepo.CheckEmailExists(ctx, email)
+	// Existing users are never matched here, only by OIDCService
+	emailExists, err := s.userRepo.CheckEmailExists(ctx, identity.Email)
 	if err != nil {
 		return nil, errors.New("failed to check email existence")
 	}
-
-	// If user exists, return existing user (login scenario)
 	if emailExists {
-		user, err := s.userRepo.GetUserByEmail(ctx, email)
-		if err != nil {
-			return nil, errors.New("failed to retrieve existing user")
-		}
-		return user, nil
+		return nil, errors.New("email already registered")
 	}
 
 	// ==================================================
-	// CREATE NEW USER (First time Google signup)
+	// CREATE NEW USER (First time provider signup)
 	// ==================================================
 	
 	user := &models.User{
 		Username: name,
-		Email:    email,
-		Password: "", // No password for Google OAuth users
-		Role:     "tester", // Automatically set role to tester
-		Picture:  picture, // Store Google profile picture URL
+		Email:    identity.Email,
+		Password: "", // No password for provider users
+		Role:     models.RoleTester, // Automatically set role to tester
+		Picture:  picture, // Store provider profile picture URL
+		EmailVerified: true, // The provider has verified the address
+		Identities: []models.Identity{identity},
 	}
 
 	// Save to database
 	err = s.userRepo.CreateUser(ctx, user)
 	if err != nil {
-		return nil, errors.New("failed to create Google user")
+		return nil, errors.New("failed to create user")
 	}
 
+	s.recordUserCreated(ctx, user, "oidc")
+
 	return user, nil
 }
 
-// SetUserPassword updates the password for a user (typically for Google OAuth users)
-func (s *UserService) SetUserPassword(ctx context.Context, email, password string) error {
-	// ==================================================
-	// VALIDATION
-	// ==================================================
-	
-	if strings.TrimSpace(email) == "" {
-		return errors.New("email is required")
+// recordUserCreated audits a new user; sign-ups are attributed to the new user
+func (s *UserService) recordUserCreated(ctx context.Context, user *models.User, method string) {
+	event := models.AuditEvent{
+		Action:     models.AuditUserCreated,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		After:      AuditSnapshot(user),
+		Details:    map[string]interface{}{"method": method},
 	}
-	if strings.TrimSpace(password) == "" {
-		return errors.New("password is required")
-	}
-	if len(password) < 6 {
-		return errors.New("password must be at least 6 characters")
+	if AuditActorFromContext(ctx).UserID == "" {
+		event.ActorID = user.ID
+		event.ActorEmail = user.Email
 	}
+	s.audit.Record(ctx, event)
+}
 
+// SetPasswordRequest represents the data needed to set or change a password
+type SetPasswordRequest struct {
+	CurrentPassword string `json:"current_password"` // required if the user already has a password
+	Password        string `json:"password"`
+}
+
+// SetUserPassword sets the password of the user with userID
+// Users without a password (Google OAuth sign-ups) can set one directly;
+// everyone else has to confirm their current password.
+func (s *UserService) SetUserPassword(ctx context.Context, userID string, req SetPasswordRequest) error {
 	// ==================================================
-	// CHECK IF USER EXISTS
+	// VALIDATION
 	// ==================================================
 	
-	emailExists, err := s.userRepo.CheckEmailExists(ctx, email)
-	if err != nil {
-		return errors.New("failed to check email existence")
+	if err := validatePassword(req.Password); err != nil {
+		return err
 	}
-	if !emailExists {
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
 		return errors.New("user not found")
 	}
 
+	if user.Password != "" {
+		match, _, _ := utils.VerifyPassword(user.Password, req.CurrentPassword)
+		if !match {
+			return ErrIncorrectPassword
+		}
+	}
+
 	// ==================================================
 	// UPDATE PASSWORD
 	// ==================================================
 	
-	err = s.userRepo.UpdateUserPassword(ctx, email, password)
+	passwordHash, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return errors.New("failed to update password")
 	}
 
+	err = s.userRepo.SetPasswordByID(ctx, user.ID, passwordHash)
+	if err != nil {
+		return errors.New("failed to update password")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditPasswordChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     map[string]interface{}{"has_password": user.Password != ""},
+		After:      map[string]interface{}{"has_password": true},
+	})
+
+	return nil
+}
+
+// validatePassword checks a new password
+func validatePassword(password string) error {
+	if strings.TrimSpace(password) == "" {
+		return errors.New("password is required")
+	}
+	if len(password) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
 	return nil
 }
 
@@ -228,16 +289,32 @@ func (s *UserService) LoginUser(ctx context.Context, email, password string) (*m
 	
 	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, ErrInvalidCredentials
 	}
 
 	// ==================================================
 	// VERIFY PASSWORD
 	// ==================================================
 	
-	// Plain text comparison (no hashing as requested)
-	if user.Password != password {
-		return nil, errors.New("invalid email or password")
+	match, needsRehash, err := utils.VerifyPassword(user.Password, password)
+	if err != nil {
+		log.Printf("Stored password of user %s cannot be verified: %v", user.ID, err)
+	}
+	if !match {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Upgrade a legacy plaintext password (or an outdated hash) now that we know it
+	if needsRehash {
+		passwordHash, err := utils.HashPassword(password)
+		if err == nil {
+			err = s.userRepo.UpdateUserPassword(ctx, user.Email, passwordHash)
+		}
+		if err != nil {
+			log.Printf("Failed to upgrade password hash of user %s: %v", user.ID, err)
+		} else {
+			user.Password = passwordHash
+		}
 	}
 
 	return user, nil
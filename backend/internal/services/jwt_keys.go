@@ -0,0 +1,213 @@
+package services
+
+/**
+ * JWT Signing Keys
+ *
+ * Purpose: Load the keys used to sign and verify JWTs
+ *
+ * One key signs new tokens; it and any number of previous keys verify
+ * them. Every token carries the ID of its signing key in the "kid" header,
+ * so rotating the key only requires moving the old one to the previous
+ * list until the tokens it signed have expired.
+ *
+ * Configuration (see utils.Config):
+ * - JWT_ALGORITHM: HS256 (default), RS256 or EdDSA
+ * - JWT_SECRET: the HS256 secret
+ * - JWT_PRIVATE_KEY_FILE: PEM private key for RS256 / EdDSA
+ * - JWT_KEY_ID: optional; defaults to a fingerprint of the key
+ * - JWT_PREVIOUS_SECRETS: comma-separated HS256 secrets that still verify
+ * - JWT_PREVIOUS_PUBLIC_KEY_FILES: comma-separated PEM public keys that still verify
+ */
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"backend/internal/utils"
+)
+
+// SigningKey is one JWT key: a signing method plus the material to sign and/or verify
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+}
+
+// KeySet holds the current signing key and every key that may still verify tokens
+type KeySet struct {
+	current *SigningKey
+	keys    map[string]*SigningKey
+}
+
+// NewKeySet creates a key set that signs with current and also verifies with previous
+func NewKeySet(current *SigningKey, previous ...*SigningKey) (*KeySet, error) {
+	if current == nil || current.signKey == nil {
+		return nil, errors.New("a signing key is required")
+	}
+
+	set := &KeySet{current: current, keys: map[string]*SigningKey{}}
+	for _, key := range append([]*SigningKey{current}, previous...) {
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	return set, nil
+}
+
+// LoadKeySet builds the key set described by the configuration
+func LoadKeySet(cfg *utils.Config) (*KeySet, error) {
+	var current *SigningKey
+	var err error
+
+	switch cfg.JWTAlgorithm {
+	case "", "HS256":
+		current, err = NewHMACKey(cfg.JWTSecret)
+	case "RS256", "EdDSA":
+		current, err = loadPrivateKeyFile(cfg.JWTPrivateKeyFile)
+		if err == nil && current.Method.Alg() != cfg.JWTAlgorithm {
+			err = fmt.Errorf("JWT_PRIVATE_KEY_FILE does not hold a %s key", cfg.JWTAlgorithm)
+		}
+	default:
+		err = fmt.Errorf("unsupported JWT algorithm %q", cfg.JWTAlgorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.JWTKeyID != "" {
+		current.ID = cfg.JWTKeyID
+	}
+
+	var previous []*SigningKey
+	for _, secret := range splitList(cfg.JWTPreviousSecrets) {
+		key, err := NewHMACKey(secret)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range splitList(cfg.JWTPreviousPublicKeyFiles) {
+		key, err := loadPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeySet(current, previous...)
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(secret string) (*SigningKey, error) {
+	if secret == "" {
+		return nil, errors.New("JWT secret cannot be empty")
+	}
+
+	return &SigningKey{
+		ID:        fingerprint([]byte(secret)),
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}, nil
+}
+
+// NewPrivateKey creates an RS256 or EdDSA key from an RSA or Ed25519 private key
+func NewPrivateKey(private crypto.Signer) (*SigningKey, error) {
+	key, err := newPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.signKey = private
+	return key, nil
+}
+
+// newPublicKey creates a verification-only key, identified by the fingerprint of its DER encoding
+func newPublicKey(public crypto.PublicKey) (*SigningKey, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("JWT keys must be RSA or Ed25519")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:        fingerprint(der),
+		Method:    method,
+		verifyKey: public,
+	}, nil
+}
+
+func loadPrivateKeyFile(path string) (*SigningKey, error) {
+	if path == "" {
+		return nil, errors.New("JWT_PRIVATE_KEY_FILE is required for asymmetric algorithms")
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return NewPrivateKey(private)
+	}
+	if private, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		if signer, ok := private.(crypto.Signer); ok {
+			return NewPrivateKey(signer)
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not an RSA or Ed25519 private key", path)
+}
+
+func loadPublicKeyFile(path string) (*SigningKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if public, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return newPublicKey(public)
+	}
+	if public, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return newPublicKey(public)
+	}
+
+	return nil, fmt.Errorf("%s is not an RSA or Ed25519 public key", path)
+}
+
+// fingerprint derives a short, stable key ID that does not reveal the key
+func fingerprint(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -10,17 +10,29 @@ package services
  * - GenerateToken: Create JWT token with user claims
  * - VerifyToken: Validate JWT token
  * - ExtractClaims: Get user data from token
+ *
+ * Tokens are signed with the current key of a KeySet and stamped with its
+ * "kid"; verification accepts the current key and any previous key.
+ *
+ * Every token has a unique ID ("jti") and the ID of the login session it
+ * belongs to ("sid"), so it can be revoked before it expires (see TokenService).
+ *
+ * MFA pending tokens (GenerateMFAToken) are issued after the password check
+ * of a user with two-factor authentication. They carry the "testops-mfa"
+ * audience: VerifyToken rejects them, so they can only be exchanged for a
+ * full token pair through the second-factor check (see MFAService).
  */
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-)
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
-// JWT secret key - In production, load this from environment variable
-const jwtSecret = "your-secret-key-change-this-in-production"
+	"backend/internal/models"
+)
 
 // TokenClaims represents the JWT claims structure
 type TokenClaims struct {
@@ -28,22 +40,60 @@ type TokenClaims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// SessionID is the refresh token family the token was issued for
+	SessionID string `json:"sid,omitempty"`
+	// Scopes limits the request to these permissions; nil means every permission of the role
+	Scopes []models.Permission `json:"scp,omitempty"`
+	// ProjectID pins a personal access token to one project; empty means any of the user's projects
+	ProjectID string `json:"pid,omitempty"`
+	// LoginMethod is how the user logged in; only set on MFA pending tokens
+	LoginMethod string `json:"lm,omitempty"`
+	// PersonalAccessTokenID is set when the request used a personal access token instead of a JWT
+	PersonalAccessTokenID string `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// Allows reports whether the token's scopes permit permission
+func (c *TokenClaims) Allows(permission models.Permission) bool {
+	if c.Scopes == nil {
+		return true
+	}
+	for _, scope := range c.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaAudience marks MFA pending tokens
+const mfaAudience = "testops-mfa"
+
 // JWTService handles JWT operations
-type JWTService struct{}
+type JWTService struct {
+	keys *KeySet
+	ttl  time.Duration
+}
+
+// NewJWTService creates a new JWT service instance that signs and verifies with keys
+// Tokens it issues are valid for ttl
+func NewJWTService(keys *KeySet, ttl time.Duration) *JWTService {
+	return &JWTService{
+		keys: keys,
+		ttl:  ttl,
+	}
+}
 
-// NewJWTService creates a new JWT service instance
-func NewJWTService() *JWTService {
-	return &JWTService{}
+// TTL returns how long issued tokens are valid
+func (s *JWTService) TTL() time.Duration {
+	return s.ttl
 }
 
-// GenerateToken creates a new JWT token for a user
-// Token expires in 24 hours
-func (s *JWTService) GenerateToken(userID, email, username, role string) (string, error) {
-	// Set token expiration to 24 hours from now
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateToken creates a new JWT token for a user in login session sessionID
+// Token expires after the configured TTL
+func (s *JWTService) GenerateToken(userID, email, username, role, sessionID string) (string, error) {
+	// Set token expiration from now
+	expirationTime := time.Now().Add(s.ttl)
 
 	// Create token claims
 	claims := &TokenClaims{
@@ -51,34 +101,90 @@ func (s *JWTService) GenerateToken(userID, email, username, role string) (string
 		Email:    email,
 		Username: username,
 		Role:     role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "testops-backend",
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return s.sign(claims)
+}
 
-	// Sign the token with secret key
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", err
+// GenerateMFAToken creates an MFA pending token for a user who logged in with method, valid for ttl
+// It proves the first factor passed and nothing else.
+func (s *JWTService) GenerateMFAToken(userID, method string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &TokenClaims{
+		UserID:      userID,
+		LoginMethod: method,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
+			Audience:  jwt.ClaimStrings{mfaAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "testops-backend",
+		},
 	}
 
-	return tokenString, nil
+	return s.sign(claims)
+}
+
+// sign signs claims with the current key and stamps the token with its ID
+func (s *JWTService) sign(claims *TokenClaims) (string, error) {
+	key := s.keys.current
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signKey)
 }
 
-// VerifyToken validates a JWT token and returns the claims
+// VerifyToken validates a JWT access token and returns the claims
+// MFA pending tokens are rejected.
 func (s *JWTService) VerifyToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.verify(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(claims.Audience, mfaAudience) {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// VerifyMFAToken validates an MFA pending token and returns the claims
+func (s *JWTService) VerifyMFAToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.verify(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(claims.Audience, mfaAudience) {
+		return nil, errors.New("not an MFA token")
+	}
+
+	return claims, nil
+}
+
+// verify checks a token's signature and expiry
+func (s *JWTService) verify(tokenString string) (*TokenClaims, error) {
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Tokens issued before key IDs were introduced are checked against the current key
+		key := s.keys.current
+		if kid, ok := token.Header["kid"].(string); ok {
+			if key, ok = s.keys.keys[kid]; !ok {
+				return nil, errors.New("unknown signing key")
+			}
+		}
+
+		// The key decides the algorithm, never the token
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("invalid signing method")
 		}
-		return []byte(jwtSecret), nil
+		return key.verifyKey, nil
 	})
 
 	if err != nil {
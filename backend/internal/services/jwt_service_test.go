@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJWTServiceRejectsMFAPendingToken(t *testing.T) {
+	jwtService := testJWTService(t)
+
+	mfaToken, err := jwtService.GenerateMFAToken("user-1", "password", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateMFAToken: %v", err)
+	}
+	if _, err := jwtService.VerifyToken(mfaToken); err == nil {
+		t.Error("VerifyToken accepted an MFA pending token")
+	}
+	claims, err := jwtService.VerifyMFAToken(mfaToken)
+	if err != nil {
+		t.Fatalf("VerifyMFAToken: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.LoginMethod != "password" {
+		t.Errorf("MFA token claims = %q/%q, want user-1/password", claims.UserID, claims.LoginMethod)
+	}
+
+	accessToken, err := jwtService.GenerateToken("user-1", "alice@example.com", "alice", "tester", "session-1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := jwtService.VerifyMFAToken(accessToken); err == nil {
+		t.Error("VerifyMFAToken accepted an access token")
+	}
+	if _, err := jwtService.VerifyToken(accessToken); err != nil {
+		t.Errorf("VerifyToken: %v", err)
+	}
+}
+
+func TestJWTServiceRejectsExpiredMFAPendingToken(t *testing.T) {
+	jwtService := testJWTService(t)
+
+	mfaToken, err := jwtService.GenerateMFAToken("user-1", "password", -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateMFAToken: %v", err)
+	}
+	if _, err := jwtService.VerifyMFAToken(mfaToken); err == nil {
+		t.Error("VerifyMFAToken accepted an expired token")
+	}
+}
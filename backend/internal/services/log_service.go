@@ -0,0 +1,294 @@
+package services
+
+/**
+ * Log Service
+ *
+ * Purpose: Handle business logic for the live output of test runs
+ *
+ * Operations:
+ * - AppendLogs: The worker executing a run appends a batch of log lines
+ * - GetLogs: Read the lines of one of the project's runs after an offset
+ * - FollowLogs: Replay from an offset, then deliver new lines until the run finishes
+ *
+ * Lines are stored in MongoDB, so a follower can connect to any backend
+ * replica. Followers on the replica that received the lines are woken
+ * immediately; the others pick new lines up on the next poll.
+ */
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+const (
+	// maxLogBatch is the most lines a single append may carry
+	maxLogBatch = 1000
+
+	// maxLogLineLength is the longest message kept; longer lines are truncated
+	maxLogLineLength = 16 << 10
+
+	// logPageSize is the most lines returned by one read
+	logPageSize = 1000
+
+	// logPollInterval is how often followers check for lines appended on other replicas
+	logPollInterval = 2 * time.Second
+
+	// logKeepAlive is how long a follower may go without being sent anything
+	logKeepAlive = 15 * time.Second
+
+	// logGapTimeout is how long a follower waits for missing seqs before skipping them
+	// Appends reserve their seqs before writing the lines, so a concurrent append
+	// leaves a gap that normally fills within moments; one that failed never does
+	logGapTimeout = 10 * time.Second
+)
+
+// LogLineRequest is one line of a log batch a worker appends
+type LogLineRequest struct {
+	Level     string    `json:"level"` // debug, info, warn or error (default info)
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"` // defaults to the time the batch is received
+}
+
+// AppendLogsRequest represents the body of a log append
+type AppendLogsRequest struct {
+	Lines []LogLineRequest `json:"lines"`
+}
+
+type LogService struct {
+	logRepo    *repository.RunLogRepository
+	runRepo    *repository.TestRunRepository
+	runService *RunService
+	broker     *logBroker
+}
+
+// NewLogService creates a new log service instance
+func NewLogService(logRepo *repository.RunLogRepository, runRepo *repository.TestRunRepository, runService *RunService) *LogService {
+	return &LogService{
+		logRepo:    logRepo,
+		runRepo:    runRepo,
+		runService: runService,
+		broker:     newLogBroker(),
+	}
+}
+
+// AppendLogs appends lines to a run in projectID that workerID is executing and returns the last seq assigned
+func (s *LogService) AppendLogs(ctx context.Context, projectID, runID, workerID string, req AppendLogsRequest) (int64, error) {
+	run, err := s.runService.GetRunForWorker(ctx, projectID, runID, workerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(req.Lines) == 0 {
+		return 0, invalidRequest("at least one line is required")
+	}
+	if len(req.Lines) > maxLogBatch {
+		return 0, invalidRequest("too many lines in one batch")
+	}
+
+	last, err := s.runRepo.ReserveLogLines(ctx, run.ID, len(req.Lines))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, ErrRunNotOwned
+	}
+	if err != nil {
+		return 0, errors.New("failed to append logs")
+	}
+
+	now := time.Now()
+	first := last - int64(len(req.Lines)) + 1
+	lines := make([]models.RunLogLine, len(req.Lines))
+	for i, line := range req.Lines {
+		lines[i] = models.RunLogLine{
+			RunID:     run.ID,
+			ProjectID: run.ProjectID,
+			UserID:    run.UserID,
+			Seq:       first + int64(i),
+			Level:     normalizeLogLevel(line.Level),
+			Message:   truncateLogLine(line.Message),
+			Timestamp: line.Timestamp,
+		}
+		if lines[i].Timestamp.IsZero() {
+			lines[i].Timestamp = now
+		}
+	}
+
+	if err := s.logRepo.Append(ctx, lines); err != nil {
+		return 0, errors.New("failed to append logs")
+	}
+
+	s.broker.publish(run.ID)
+	return last, nil
+}
+
+// GetLogs returns up to one page of lines after seq after of a run in projectID
+func (s *LogService) GetLogs(ctx context.Context, projectID, runID string, after int64) ([]models.RunLogLine, error) {
+	if _, err := s.runService.GetRun(ctx, projectID, runID); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.logRepo.ListAfter(ctx, runID, projectID, after, logPageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve logs")
+	}
+
+	return lines, nil
+}
+
+// FollowLogs delivers the lines after seq after of a run in projectID to send,
+// then keeps delivering new lines until the run finishes or ctx is cancelled
+//
+// send is called once straight away with the backlog (possibly empty), then
+// with each batch of new lines, and with no lines as a keep-alive. Returns the
+// finished run, or ctx.Err() if the follower went away first.
+func (s *LogService) FollowLogs(ctx context.Context, projectID, runID string, after int64, send func([]models.RunLogLine) error) (*models.TestRun, error) {
+	if _, err := s.runService.GetRun(ctx, projectID, runID); err != nil {
+		return nil, err
+	}
+
+	// Subscribe before the first read so no append can slip in between
+	wake, unsubscribe := s.broker.subscribe(runID)
+	defer unsubscribe()
+
+	poll := time.NewTicker(logPollInterval)
+	defer poll.Stop()
+
+	sentAt := time.Time{}
+	gapSince := time.Time{}
+	for {
+		// Check whether the run has finished before reading; a finished run
+		// reserves no more seqs, so its line count is final
+		run, err := s.runService.GetRun(ctx, projectID, runID)
+		if err != nil {
+			return nil, err
+		}
+
+		gap := false
+		for {
+			lines, err := s.logRepo.ListAfter(ctx, runID, projectID, after, logPageSize)
+			if err != nil {
+				return nil, errors.New("failed to retrieve logs")
+			}
+			// Hold the cursor before a missing seq so lines still being written are not skipped
+			if n := contiguousLogLines(lines, after); n < len(lines) && (gapSince.IsZero() || time.Since(gapSince) < logGapTimeout) {
+				lines = lines[:n]
+				gap = true
+			}
+			if len(lines) > 0 || sentAt.IsZero() || time.Since(sentAt) >= logKeepAlive {
+				if err := send(lines); err != nil {
+					return nil, err
+				}
+				sentAt = time.Now()
+			}
+			if len(lines) > 0 {
+				after = lines[len(lines)-1].Seq
+			}
+			if gap || len(lines) < logPageSize {
+				break
+			}
+		}
+
+		missing := gap || (run.IsFinished() && after < run.LogLines)
+		switch {
+		case !missing:
+			gapSince = time.Time{}
+		case gapSince.IsZero():
+			gapSince = time.Now()
+		}
+
+		if run.IsFinished() && (!missing || time.Since(gapSince) >= logGapTimeout) {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wake:
+		case <-poll.C:
+		}
+	}
+}
+
+// contiguousLogLines returns how many of lines, in seq order, directly follow seq after
+func contiguousLogLines(lines []models.RunLogLine, after int64) int {
+	for i, line := range lines {
+		if line.Seq != after+int64(i)+1 {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// normalizeLogLevel maps unknown or missing levels to info
+func normalizeLogLevel(level string) string {
+	level = strings.ToLower(level)
+	switch level {
+	case models.LogLevelDebug, models.LogLevelInfo, models.LogLevelWarn, models.LogLevelError:
+		return level
+	case "warning":
+		return models.LogLevelWarn
+	}
+	return models.LogLevelInfo
+}
+
+func truncateLogLine(message string) string {
+	if len(message) <= maxLogLineLength {
+		return message
+	}
+	return strings.ToValidUTF8(message[:maxLogLineLength], "")
+}
+
+// ==================================================
+// IN-PROCESS NOTIFICATIONS
+// ==================================================
+
+// logBroker wakes the followers of a run when lines are appended on this replica
+type logBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func newLogBroker() *logBroker {
+	return &logBroker{subscribers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// subscribe returns a channel that receives a value after lines are appended to the run
+// Notifications are coalesced; the caller must call the returned function when done
+func (b *logBroker) subscribe(runID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[runID] == nil {
+		b.subscribers[runID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[runID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers[runID], ch)
+		if len(b.subscribers[runID]) == 0 {
+			delete(b.subscribers, runID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// publish wakes every follower of the run without blocking
+func (b *logBroker) publish(runID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[runID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
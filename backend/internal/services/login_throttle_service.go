@@ -0,0 +1,249 @@
+package services
+
+/**
+ * Login Throttle Service
+ *
+ * Purpose: Brute-force protection for password and code checks
+ *
+ * Operations:
+ * - Check: Refuse an attempt while its client IP, email or user is locked,
+ *   or else count it as a failure until it turns out otherwise
+ * - Succeed: Forget the account's failures (the client IP's are kept)
+ * - Cancel: Take back an attempt that never got to check the credential
+ * - Status / Unlock: Inspect and lift a user's lock (admins)
+ *
+ * Each client IP, email and user has its own counter. After MaxFailures
+ * failures (IPMaxFailures for an IP, which several users may share) every
+ * further failure locks the counter for Backoff, doubling each time up to
+ * Lockout. Failures are forgotten Window after the last one. Counters live
+ * in MongoDB, so the limits hold across backend replicas. Attempts are
+ * counted when they are checked, so concurrent attempts cannot all get in
+ * before the first failure is recorded.
+ */
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// ErrTooManyAttempts is returned (as a *ThrottledError) while an attempt is locked out
+var ErrTooManyAttempts = errors.New("too many failed attempts - try again later")
+
+// ThrottledError is returned while a client IP, email or user is locked
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string { return ErrTooManyAttempts.Error() }
+
+func (e *ThrottledError) Unwrap() error { return ErrTooManyAttempts }
+
+// RetryAfterSeconds is RetryAfter in whole seconds, rounded up, for the
+// Retry-After header; a client retrying after it is never early
+func (e *ThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// LoginThrottleConfig holds the brute-force protection settings
+type LoginThrottleConfig struct {
+	// MaxFailures is how many failures an email or user gets before backoff starts
+	MaxFailures int
+
+	// IPMaxFailures is how many failures a client IP gets before backoff starts
+	IPMaxFailures int
+
+	// Backoff is the first lock; it doubles with every further failure
+	Backoff time.Duration
+
+	// Lockout is the longest lock
+	Lockout time.Duration
+
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// lockSchedule returns the locks of the attempts from MaxFailures on:
+// Backoff, doubling with each attempt, up to Lockout (the last entry, which
+// also applies to every later attempt)
+func (c LoginThrottleConfig) lockSchedule() []time.Duration {
+	var locks []time.Duration
+	for lock := c.Backoff; lock > 0 && lock < c.Lockout; lock *= 2 {
+		locks = append(locks, lock)
+	}
+	return append(locks, c.Lockout)
+}
+
+// LoginAttempt identifies who is trying to log in; empty fields are not counted
+type LoginAttempt struct {
+	IP     string
+	Email  string
+	UserID string
+}
+
+// LockoutStatus is the state of a user's failure counters
+type LockoutStatus struct {
+	Failures    int        `json:"failures"`
+	Locked      bool       `json:"locked"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+type LoginThrottleService struct {
+	failureRepo *repository.LoginFailureRepository
+	userRepo    *repository.UserRepository
+	cfg         LoginThrottleConfig
+}
+
+// NewLoginThrottleService creates a new login throttle service instance
+func NewLoginThrottleService(failureRepo *repository.LoginFailureRepository, userRepo *repository.UserRepository, cfg LoginThrottleConfig) *LoginThrottleService {
+	return &LoginThrottleService{
+		failureRepo: failureRepo,
+		userRepo:    userRepo,
+		cfg:         cfg,
+	}
+}
+
+// Check returns a *ThrottledError if any counter of the attempt is locked
+// Otherwise the attempt is counted as a failure of each counter straight away;
+// the caller must call Succeed or Cancel unless the credential was wrong.
+func (s *LoginThrottleService) Check(ctx context.Context, attempt LoginAttempt) error {
+	now := time.Now()
+	locks := s.cfg.lockSchedule()
+
+	var counted []string
+	var wait time.Duration
+	for _, key := range attempt.keys() {
+		maxFailures := s.cfg.MaxFailures
+		if strings.HasPrefix(key, "ip:") {
+			maxFailures = s.cfg.IPMaxFailures
+		}
+
+		before, err := s.failureRepo.Attempt(ctx, key, now, now.Add(s.cfg.Window), maxFailures, locks)
+		if err != nil {
+			s.refund(ctx, counted)
+			return errors.New("failed to check login attempts")
+		}
+		if before == nil || !before.ExpiresAt.After(now) || !before.LockedUntil.After(now) {
+			counted = append(counted, key)
+			continue
+		}
+		if left := before.LockedUntil.Sub(now); left > wait {
+			wait = left
+		}
+	}
+
+	// A locked counter was left alone; take the attempt back from the others
+	if wait > 0 {
+		s.refund(ctx, counted)
+		return &ThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// Succeed forgets the failures of the attempt's email and user
+// The client IP's failures are kept, so that one good account does not
+// reset a guessing run over many others; only this attempt is taken back.
+func (s *LoginThrottleService) Succeed(ctx context.Context, attempt LoginAttempt) error {
+	ip := LoginAttempt{IP: attempt.IP}
+	attempt.IP = ""
+	if err := s.failureRepo.Delete(ctx, attempt.keys()); err != nil {
+		return errors.New("failed to reset login attempts")
+	}
+	if err := s.refund(ctx, ip.keys()); err != nil {
+		return errors.New("failed to reset login attempts")
+	}
+	return nil
+}
+
+// Cancel takes back an attempt that failed before its credential was checked
+func (s *LoginThrottleService) Cancel(ctx context.Context, attempt LoginAttempt) error {
+	if err := s.refund(ctx, attempt.keys()); err != nil {
+		return errors.New("failed to reset login attempts")
+	}
+	return nil
+}
+
+// Status returns the combined state of a user's email and user counters
+func (s *LoginThrottleService) Status(ctx context.Context, userID string) (*LockoutStatus, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	counters, err := s.failureRepo.Find(ctx, userAttempt(user).keys(), now)
+	if err != nil {
+		return nil, errors.New("failed to get lockout status")
+	}
+
+	status := &LockoutStatus{}
+	for _, counter := range counters {
+		if counter.Failures > status.Failures {
+			status.Failures = counter.Failures
+		}
+		if counter.LockedUntil.After(now) && (status.LockedUntil == nil || counter.LockedUntil.After(*status.LockedUntil)) {
+			lockedUntil := counter.LockedUntil
+			status.LockedUntil = &lockedUntil
+			status.Locked = true
+		}
+	}
+
+	return status, nil
+}
+
+// Unlock lifts a user's lock and forgets their failures
+// Locks of client IPs are left alone; they expire on their own.
+func (s *LoginThrottleService) Unlock(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.failureRepo.Delete(ctx, userAttempt(user).keys()); err != nil {
+		return errors.New("failed to unlock user")
+	}
+	return nil
+}
+
+// refund takes back one counted attempt from each key
+func (s *LoginThrottleService) refund(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := s.failureRepo.Refund(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userAttempt is an attempt on every account counter of a user
+func userAttempt(user *models.User) LoginAttempt {
+	return LoginAttempt{Email: user.Email, UserID: user.ID}
+}
+
+// keys returns the counter keys of an attempt
+// Emails are compared case-insensitively, and IPv6 clients are counted per
+// /64 network since a single host usually has all of one.
+func (a LoginAttempt) keys() []string {
+	var keys []string
+	if a.IP != "" {
+		ip := a.IP
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			ip = parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+		}
+		keys = append(keys, "ip:"+ip)
+	}
+	if email := strings.ToLower(strings.TrimSpace(a.Email)); email != "" {
+		keys = append(keys, "email:"+email)
+	}
+	if a.UserID != "" {
+		keys = append(keys, "user:"+a.UserID)
+	}
+	return keys
+}
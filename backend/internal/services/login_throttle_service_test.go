@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/internal/repository"
+)
+
+func TestLockSchedule(t *testing.T) {
+	tests := []struct {
+		backoff time.Duration
+		lockout time.Duration
+		want    []time.Duration
+	}{
+		{30 * time.Second, 5 * time.Minute, []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute}},
+		{time.Minute, 4 * time.Minute, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}},
+		{time.Minute, time.Minute, []time.Duration{time.Minute}},
+		{time.Hour, time.Minute, []time.Duration{time.Minute}},
+	}
+
+	for _, tt := range tests {
+		cfg := LoginThrottleConfig{Backoff: tt.backoff, Lockout: tt.lockout}
+		if got := cfg.lockSchedule(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lockSchedule(%v, %v) = %v, want %v", tt.backoff, tt.lockout, got, tt.want)
+		}
+	}
+
+	// The schedule stays short for any configuration
+	cfg := LoginThrottleConfig{Backoff: time.Nanosecond, Lockout: 1<<63 - 1}
+	if locks := cfg.lockSchedule(); len(locks) > 64 || locks[len(locks)-1] != cfg.Lockout {
+		t.Errorf("lockSchedule(1ns, max) has %d locks ending in %v", len(locks), locks[len(locks)-1])
+	}
+}
+
+func TestLoginAttemptKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt LoginAttempt
+		want    []string
+	}{
+		{"everything", LoginAttempt{IP: "203.0.113.7", Email: "jane@example.com", UserID: "u1"}, []string{"ip:203.0.113.7", "email:jane@example.com", "user:u1"}},
+		{"email case and spaces", LoginAttempt{Email: "  Jane@Example.COM "}, []string{"email:jane@example.com"}},
+		{"IPv6 per /64", LoginAttempt{IP: "2001:db8:1:2:3:4:5:6"}, []string{"ip:2001:db8:1:2::/64"}},
+		{"unparsable IP", LoginAttempt{IP: "unknown"}, []string{"ip:unknown"}},
+		{"nothing", LoginAttempt{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.attempt.keys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Hosts of one /64 share a counter, other networks do not
+	a := LoginAttempt{IP: "2001:db8:1:2::1"}.keys()
+	b := LoginAttempt{IP: "2001:db8:1:2:ffff::9"}.keys()
+	c := LoginAttempt{IP: "2001:db8:1:3::1"}.keys()
+	if !reflect.DeepEqual(a, b) || reflect.DeepEqual(a, c) {
+		t.Errorf("IPv6 keys %v, %v, %v: want the first two equal and the third different", a, b, c)
+	}
+}
+
+func TestThrottledErrorRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{time.Second + time.Millisecond, 2},
+		{29500 * time.Millisecond, 30},
+		{time.Hour, 3600},
+	}
+
+	for _, tt := range tests {
+		err := &ThrottledError{RetryAfter: tt.retryAfter}
+		if got := err.RetryAfterSeconds(); got != tt.want {
+			t.Errorf("RetryAfterSeconds(%v) = %d, want %d", tt.retryAfter, got, tt.want)
+		}
+		if !errors.Is(err, ErrTooManyAttempts) {
+			t.Error("ThrottledError is not ErrTooManyAttempts")
+		}
+	}
+}
+
+func TestLoginFailureLocks(t *testing.T) {
+	repo := repository.NewLoginFailureRepository(testDatabase(t))
+	ctx := context.Background()
+	locks := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+
+	// attempt counts one attempt at now and returns the lock it left
+	now := time.Now().Truncate(time.Second)
+	attempt := func() time.Time {
+		t.Helper()
+		if _, err := repo.Attempt(ctx, "email:jane@example.com", now, now.Add(time.Hour), 2, locks); err != nil {
+			t.Fatalf("Attempt: %v", err)
+		}
+		counters, err := repo.Find(ctx, []string{"email:jane@example.com"}, now)
+		if err != nil || len(counters) != 1 {
+			t.Fatalf("Find = %v, %v", counters, err)
+		}
+		return counters[0].LockedUntil
+	}
+
+	if lockedUntil := attempt(); !lockedUntil.IsZero() {
+		t.Errorf("first attempt locked until %v, want no lock", lockedUntil)
+	}
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		if lockedUntil := attempt(); !lockedUntil.Equal(now.Add(want)) {
+			t.Errorf("attempt %d locked until %v, want %v", i+2, lockedUntil, now.Add(want))
+		}
+
+		// Attempts while locked change nothing
+		if lockedUntil := attempt(); !lockedUntil.Equal(now.Add(want)) {
+			t.Errorf("attempt while locked moved the lock to %v", lockedUntil)
+		}
+		now = now.Add(want + time.Second)
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	db := testDatabase(t)
+	throttle := NewLoginThrottleService(repository.NewLoginFailureRepository(db), repository.NewUserRepository(db), LoginThrottleConfig{
+		MaxFailures:   3,
+		IPMaxFailures: 5,
+		Backoff:       time.Minute,
+		Lockout:       time.Hour,
+		Window:        time.Hour,
+	})
+	ctx := context.Background()
+
+	check := func(attempt LoginAttempt) error {
+		t.Helper()
+		err := throttle.Check(ctx, attempt)
+		if err != nil && !errors.Is(err, ErrTooManyAttempts) {
+			t.Fatalf("Check: %v", err)
+		}
+		return err
+	}
+
+	// Attempts that never checked a credential, or that succeeded, do not count
+	jane := LoginAttempt{IP: "203.0.113.7", Email: "jane@example.com"}
+	for i := 0; i < 10; i++ {
+		if err := check(jane); err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+		if i%2 == 0 {
+			throttle.Cancel(ctx, jane)
+		} else {
+			throttle.Succeed(ctx, jane)
+		}
+	}
+
+	// The attempt that reaches MaxFailures locks the account for Backoff
+	for i := 0; i < 3; i++ {
+		if err := check(jane); err != nil {
+			t.Fatalf("failure %d: %v", i+1, err)
+		}
+	}
+	var throttled *ThrottledError
+	if err := check(jane); !errors.As(err, &throttled) {
+		t.Fatalf("attempt past MaxFailures = %v, want a ThrottledError", err)
+	}
+	if throttled.RetryAfter <= 58*time.Second || throttled.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want about a minute", throttled.RetryAfter)
+	}
+
+	// The account is locked from any IP; the IP is not locked for other accounts
+	if err := check(LoginAttempt{IP: "198.51.100.1", Email: "JANE@example.com"}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("locked account from another IP = %v, want ErrTooManyAttempts", err)
+	}
+	bob := LoginAttempt{IP: jane.IP, Email: "bob@example.com"}
+	if err := check(bob); err != nil {
+		t.Errorf("other account from the same IP = %v, want nil", err)
+	}
+
+	// The refused attempts were taken back from the IP: 3 failures of jane and 1 of bob so far.
+	// A guessing run over many accounts still locks the IP.
+	if err := check(LoginAttempt{IP: jane.IP, Email: "carol@example.com"}); err != nil {
+		t.Fatalf("fifth failure of the IP: %v", err)
+	}
+	if err := check(LoginAttempt{IP: jane.IP, Email: "dave@example.com"}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("attempt from a locked IP = %v, want ErrTooManyAttempts", err)
+	}
+	if err := check(LoginAttempt{IP: "198.51.100.1", Email: "dave@example.com"}); err != nil {
+		t.Errorf("same account from another IP = %v, want nil", err)
+	}
+}
@@ -0,0 +1,409 @@
+package services
+
+/**
+ * MFA Service
+ *
+ * Purpose: TOTP two-factor authentication
+ *
+ * Operations:
+ * - Login: Finish a password (or Google) login - a token pair, or an MFA
+ *   pending token if the user has TOTP enabled
+ * - Verify: Exchange an MFA pending token and a code for a token pair
+ * - Enroll / Confirm: Set up an authenticator app; confirming returns the
+ *   one-time recovery codes
+ * - Disable / RegenerateRecoveryCodes: Need a current code
+ *
+ * A code is either a 6-digit TOTP code or one of the recovery codes. TOTP
+ * secrets are stored AES-GCM encrypted; recovery codes are stored as SHA-256
+ * hashes and each works once.
+ */
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+var (
+	// ErrInvalidMFAToken is returned for invalid, expired or used MFA pending tokens
+	ErrInvalidMFAToken = errors.New("invalid or expired MFA token - log in again")
+
+	// ErrInvalidMFACode is returned for wrong, reused or expired codes
+	ErrInvalidMFACode = errors.New("invalid authentication code")
+
+	// ErrTOTPAlreadyEnabled is returned when enrolling a user who already has TOTP enabled
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrTOTPNotEnabled is returned when the user has no (confirmed) TOTP secret
+	ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+)
+
+// recoveryCodeCount is how many recovery codes a user gets
+const recoveryCodeCount = 10
+
+// MFAConfig holds the two-factor authentication settings
+type MFAConfig struct {
+	// Issuer is the account name shown in authenticator apps
+	Issuer string
+
+	// EncryptionKey encrypts stored TOTP secrets
+	EncryptionKey string
+
+	// PendingTTL is how long an MFA pending token is valid
+	PendingTTL time.Duration
+}
+
+type MFAService struct {
+	userRepo     *repository.UserRepository
+	tokenService *TokenService
+	jwtService   *JWTService
+	aead         cipher.AEAD
+	issuer       string
+	pendingTTL   time.Duration
+}
+
+// NewMFAService creates a new MFA service instance
+func NewMFAService(userRepo *repository.UserRepository, tokenService *TokenService, jwtService *JWTService, cfg MFAConfig) (*MFAService, error) {
+	key := sha256.Sum256([]byte(cfg.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAService{
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		jwtService:   jwtService,
+		aead:         aead,
+		issuer:       cfg.Issuer,
+		pendingTTL:   cfg.PendingTTL,
+	}, nil
+}
+
+// LoginResult is the outcome of a successful first factor
+// Exactly one of Tokens and MFAToken is set.
+type LoginResult struct {
+	Tokens   *TokenPair
+	MFAToken string
+}
+
+// TOTPEnrollment is returned when enrolling; the secret is shown once
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest represents a request carrying a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// MFAVerifyRequest represents the second step of a login with two-factor authentication
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// ==================================================
+// LOGIN
+// ==================================================
+
+// Login finishes a login whose first factor (password, Google) has been checked
+// method is recorded on the session (see models.SessionMethodPassword).
+func (s *MFAService) Login(ctx context.Context, user *models.User, method string) (*LoginResult, error) {
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	if !user.TOTP.Enabled {
+		tokens, err := s.tokenService.IssueTokens(ctx, user, method)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Tokens: tokens}, nil
+	}
+
+	mfaToken, err := s.jwtService.GenerateMFAToken(user.ID, method, s.pendingTTL)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &LoginResult{MFAToken: mfaToken}, nil
+}
+
+// PendingTTL returns how long MFA pending tokens are valid
+func (s *MFAService) PendingTTL() time.Duration {
+	return s.pendingTTL
+}
+
+// PendingUserID returns the user an MFA pending token was issued to, or "" if it is invalid
+// It lets failed codes be counted against the user before Verify.
+func (s *MFAService) PendingUserID(mfaToken string) string {
+	claims, err := s.jwtService.VerifyMFAToken(mfaToken)
+	if err != nil {
+		return ""
+	}
+	return claims.UserID
+}
+
+// Verify exchanges an MFA pending token and a code for a token pair
+// The pending token is used up on success.
+func (s *MFAService) Verify(ctx context.Context, req MFAVerifyRequest) (*TokenPair, *models.User, error) {
+	claims, err := s.jwtService.VerifyMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+	revoked, err := s.tokenService.IsRevoked(ctx, claims)
+	if err != nil {
+		return nil, nil, errors.New("failed to verify code")
+	}
+	if revoked {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.checkCode(ctx, claims.UserID, req.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Logout revokes the pending token's jti
+	if err := s.tokenService.Logout(ctx, claims); err != nil {
+		return nil, nil, errors.New("failed to verify code")
+	}
+
+	tokens, err := s.tokenService.IssueTokens(ctx, user, claims.LoginMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
+
+// ==================================================
+// ENROLLMENT
+// ==================================================
+
+// Enroll creates a new TOTP secret for a user
+// It is required at login only after Confirm; enrolling again before that
+// replaces the secret.
+func (s *MFAService) Enroll(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTP.Enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to enroll")
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, errors.New("failed to enroll")
+	}
+
+	err = s.userRepo.SetTOTPSecret(ctx, userID, encrypted)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if err != nil {
+		return nil, errors.New("failed to enroll")
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables TOTP with a code from the authenticator app and returns the recovery codes
+func (s *MFAService) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTP.Enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTP.Secret == "" {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	secret, err := s.decrypt(user.TOTP.Secret)
+	if err != nil {
+		return nil, errors.New("failed to confirm two-factor authentication")
+	}
+	step, ok := utils.VerifyTOTP(secret, strings.TrimSpace(code), time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to confirm two-factor authentication")
+	}
+
+	err = s.userRepo.EnableTOTP(ctx, userID, step, hashes)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if err != nil {
+		return nil, errors.New("failed to confirm two-factor authentication")
+	}
+
+	return codes, nil
+}
+
+// Disable turns off TOTP after checking a current code
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	if _, err := s.checkCode(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DisableTOTP(ctx, userID); err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a current code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if _, err := s.checkCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+	if err := s.userRepo.SetRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+
+	return codes, nil
+}
+
+// ==================================================
+// HELPERS
+// ==================================================
+
+// checkCode checks a TOTP or recovery code of a user with TOTP enabled and uses it up
+func (s *MFAService) checkCode(ctx context.Context, userID, code string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidMFAToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to verify code")
+	}
+	if !user.TOTP.Enabled {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	// Recovery codes are longer than TOTP codes
+	if len(code) > 6 {
+		err := s.userRepo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidMFACode
+		}
+		if err != nil {
+			return nil, errors.New("failed to verify code")
+		}
+		return user, nil
+	}
+
+	secret, err := s.decrypt(user.TOTP.Secret)
+	if err != nil {
+		return nil, errors.New("failed to verify code")
+	}
+	step, ok := utils.VerifyTOTP(secret, code, time.Now(), user.TOTP.LastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	// Record the step atomically so a concurrent request cannot reuse the code
+	err = s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidMFACode
+	}
+	if err != nil {
+		return nil, errors.New("failed to verify code")
+	}
+
+	return user, nil
+}
+
+// encrypt seals a TOTP secret as base64(nonce | ciphertext)
+func (s *MFAService) encrypt(secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a secret sealed by encrypt
+func (s *MFAService) decrypt(encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return "", errors.New("invalid encrypted secret")
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// generateRecoveryCodes returns new recovery codes ("xxxxx-xxxxx") and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns the stored hash of a recovery code, ignoring case and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return hashToken(normalized)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMFAServiceRecoveryCodeWorksOnce(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	userRepo := f.service.userRepo
+	mfa, err := NewMFAService(userRepo, f.service, f.jwt, MFAConfig{
+		Issuer:        "TestOps",
+		EncryptionKey: "test-key",
+		PendingTTL:    5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewMFAService: %v", err)
+	}
+
+	if _, err := mfa.Enroll(ctx, f.user.ID); err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if err := userRepo.EnableTOTP(ctx, f.user.ID, 0, hashes); err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+
+	verify := func(code string) error {
+		t.Helper()
+		user, err := userRepo.GetUserByID(ctx, f.user.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		result, err := mfa.Login(ctx, user, "password")
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		if result.MFAToken == "" {
+			t.Fatal("Login of a user with TOTP enabled returned no MFA pending token")
+		}
+		_, _, err = mfa.Verify(ctx, MFAVerifyRequest{MFAToken: result.MFAToken, Code: code})
+		return err
+	}
+
+	if err := verify(codes[0]); err != nil {
+		t.Fatalf("Verify with a recovery code: %v", err)
+	}
+	if err := verify(codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Verify with a used recovery code = %v, want ErrInvalidMFACode", err)
+	}
+	// Codes are accepted in upper case and without the dash
+	if err := verify(strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))); err != nil {
+		t.Errorf("Verify with a reformatted recovery code: %v", err)
+	}
+	if err := verify(codes[1]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Verify with a used recovery code = %v, want ErrInvalidMFACode", err)
+	}
+	if err := verify("aaaaa-aaaaa"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Verify with an unknown recovery code = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFAServicePendingTokenWorksOnce(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	mfa, err := NewMFAService(f.service.userRepo, f.service, f.jwt, MFAConfig{EncryptionKey: "test-key", PendingTTL: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("NewMFAService: %v", err)
+	}
+	if _, err := mfa.Enroll(ctx, f.user.ID); err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if err := f.service.userRepo.EnableTOTP(ctx, f.user.ID, 0, hashes); err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+
+	mfaToken, err := f.jwt.GenerateMFAToken(f.user.ID, "password", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateMFAToken: %v", err)
+	}
+	if _, _, err := mfa.Verify(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: codes[0]}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, _, err := mfa.Verify(ctx, MFAVerifyRequest{MFAToken: mfaToken, Code: codes[1]}); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("Verify with a used MFA token = %v, want ErrInvalidMFAToken", err)
+	}
+}
@@ -0,0 +1,414 @@
+package services
+
+/**
+ * OIDC Service
+ *
+ * Purpose: Log users in through OpenID Connect providers and link provider
+ * accounts to users
+ *
+ * Operations:
+ * - Providers: List the configured providers
+ * - LoginWithIDToken: Log in with an ID token the client got from the provider
+ * - StartLogin / FinishLogin: The authorization code flow with PKCE
+ * - Identities / LinkWithIDToken / StartLink / FinishLink / Unlink: Manage the
+ *   provider accounts linked to the caller
+ *
+ * A provider account (its "sub") logs in the user it is linked to. An
+ * account that is not linked yet is linked automatically only if the
+ * provider verified its email and the user with that email verified it too;
+ * if nobody has the email, a first login creates the user. Everything else
+ * has to be linked explicitly by the logged-in user. Users with two-factor
+ * authentication still need their code (see MFAService.Login).
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/oauth2"
+
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"backend/internal/repository"
+)
+
+var (
+	// ErrInvalidOIDCLogin is returned for unknown, expired or used login states
+	ErrInvalidOIDCLogin = errors.New("login expired or already completed - start again")
+
+	// ErrEmailNotVerified is returned when the provider has not verified the user's email
+	ErrEmailNotVerified = errors.New("the provider has not verified this email address")
+
+	// ErrAccountNotLinked is returned when the provider's email belongs to a user who never verified it
+	ErrAccountNotLinked = errors.New("an account with this email already exists - log in and link the provider from your account")
+
+	// ErrIdentityInUse is returned when linking a provider account that is linked to another user
+	ErrIdentityInUse = errors.New("this provider account is linked to another user")
+
+	// ErrIdentityExists is returned when linking a second account of the same provider
+	ErrIdentityExists = errors.New("an account of this provider is already linked - unlink it first")
+
+	// ErrIdentityNotFound is returned when unlinking a provider that is not linked
+	ErrIdentityNotFound = errors.New("no account of this provider is linked")
+
+	// ErrLastLoginMethod is returned when unlinking the only way a user can log in
+	ErrLastLoginMethod = errors.New("set a password or link another provider before unlinking this one")
+)
+
+// oidcLoginTTL is how long a user has to come back from the provider
+const oidcLoginTTL = 10 * time.Minute
+
+type OIDCService struct {
+	providers   *oidc.Registry
+	loginRepo   *repository.OIDCLoginRepository
+	userRepo    *repository.UserRepository
+	userService *UserService
+	mfaService  *MFAService
+	audit       *AuditService
+}
+
+// NewOIDCService creates a new OIDC service instance
+func NewOIDCService(providers *oidc.Registry, loginRepo *repository.OIDCLoginRepository, userRepo *repository.UserRepository, userService *UserService, mfaService *MFAService, audit *AuditService) *OIDCService {
+	return &OIDCService{
+		providers:   providers,
+		loginRepo:   loginRepo,
+		userRepo:    userRepo,
+		userService: userService,
+		mfaService:  mfaService,
+		audit:       audit,
+	}
+}
+
+// ProviderInfo describes a login provider to clients
+type ProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCIDTokenRequest represents a login with an ID token
+// credential is accepted for the Google sign-in button, which calls it that.
+type OIDCIDTokenRequest struct {
+	IDToken    string `json:"id_token"`
+	Credential string `json:"credential"`
+}
+
+// OIDCCallbackRequest represents the redirect back from the provider
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// Providers lists the configured providers
+func (s *OIDCService) Providers() []ProviderInfo {
+	var providers []ProviderInfo
+	for _, provider := range s.providers.List() {
+		providers = append(providers, ProviderInfo{
+			Name:        provider.Name(),
+			DisplayName: provider.DisplayName(),
+		})
+	}
+	return providers
+}
+
+// LoginWithIDToken logs in with an ID token issued by provider
+func (s *OIDCService) LoginWithIDToken(ctx context.Context, providerName string, req OIDCIDTokenRequest) (*LoginResult, *models.User, error) {
+	identity, err := s.verifyIDToken(ctx, providerName, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.login(ctx, identity)
+}
+
+// StartLogin returns the provider URL to send the user to
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (string, error) {
+	return s.start(ctx, providerName, "")
+}
+
+// FinishLogin exchanges the code the provider sent back and logs the user in
+func (s *OIDCService) FinishLogin(ctx context.Context, providerName string, req OIDCCallbackRequest) (*LoginResult, *models.User, error) {
+	identity, err := s.finish(ctx, providerName, req, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.login(ctx, identity)
+}
+
+// ==================================================
+// LINKED IDENTITIES
+// ==================================================
+
+// Identities returns the provider accounts linked to userID
+func (s *OIDCService) Identities(ctx context.Context, userID string) ([]models.Identity, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve identities")
+	}
+
+	if user.Identities == nil {
+		return []models.Identity{}, nil
+	}
+	return user.Identities, nil
+}
+
+// LinkWithIDToken links the provider account of an ID token to userID
+func (s *OIDCService) LinkWithIDToken(ctx context.Context, userID, providerName string, req OIDCIDTokenRequest) (*models.Identity, error) {
+	identity, err := s.verifyIDToken(ctx, providerName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.link(ctx, userID, identity, false)
+}
+
+// StartLink returns the provider URL to send userID to for linking their account
+func (s *OIDCService) StartLink(ctx context.Context, userID, providerName string) (string, error) {
+	return s.start(ctx, providerName, userID)
+}
+
+// FinishLink exchanges the code the provider sent back and links the account to userID
+// Only the user who started the link can finish it.
+func (s *OIDCService) FinishLink(ctx context.Context, userID, providerName string, req OIDCCallbackRequest) (*models.Identity, error) {
+	identity, err := s.finish(ctx, providerName, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.link(ctx, userID, identity, false)
+}
+
+// Unlink removes the provider account of providerName from userID
+// A user without a password must keep at least one linked account.
+func (s *OIDCService) Unlink(ctx context.Context, userID, providerName string) error {
+	identities, err := s.Identities(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var unlinked *models.Identity
+	for i := range identities {
+		if identities[i].Provider == providerName {
+			unlinked = &identities[i]
+		}
+	}
+	if unlinked == nil {
+		return ErrIdentityNotFound
+	}
+
+	err = s.userRepo.RemoveIdentity(ctx, userID, providerName)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrLastLoginMethod
+	}
+	if err != nil {
+		return errors.New("failed to unlink provider account")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditIdentityUnlinked,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     AuditSnapshot(unlinked),
+	})
+
+	return nil
+}
+
+// ==================================================
+// HELPERS
+// ==================================================
+
+// verifyIDToken returns the identity in an ID token issued by provider
+func (s *OIDCService) verifyIDToken(ctx context.Context, providerName string, req OIDCIDTokenRequest) (*oidc.Identity, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken := req.IDToken
+	if rawIDToken == "" {
+		rawIDToken = req.Credential
+	}
+	if rawIDToken == "" {
+		return nil, errors.New("id_token is required")
+	}
+
+	return provider.VerifyIDToken(ctx, rawIDToken)
+}
+
+// start begins an authorization code flow; it links the account to linkUserID, if set, instead of logging in
+func (s *OIDCService) start(ctx context.Context, providerName, linkUserID string) (string, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", errors.New("failed to start login")
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", errors.New("failed to start login")
+	}
+
+	login := &models.OIDCLogin{
+		ID:           state,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		UserID:       linkUserID,
+		ExpiresAt:    time.Now().Add(oidcLoginTTL),
+	}
+
+	url, err := provider.AuthCodeURL(ctx, login.ID, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return "", err
+	}
+	if err := s.loginRepo.Create(ctx, login); err != nil {
+		return "", errors.New("failed to start login")
+	}
+
+	return url, nil
+}
+
+// finish exchanges the code of an authorization code flow for the provider account
+// linkUserID must be the user the flow was started for, or empty for logins.
+func (s *OIDCService) finish(ctx context.Context, providerName string, req OIDCCallbackRequest, linkUserID string) (*oidc.Identity, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if req.Code == "" || req.State == "" {
+		return nil, errors.New("code and state are required")
+	}
+
+	login, err := s.loginRepo.Take(ctx, req.State, provider.Name(), time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidOIDCLogin
+	}
+	if err != nil {
+		return nil, errors.New("failed to complete login")
+	}
+	if login.UserID != linkUserID {
+		return nil, ErrInvalidOIDCLogin
+	}
+
+	return provider.Exchange(ctx, req.Code, login.CodeVerifier, login.Nonce)
+}
+
+// login logs in the user of a provider account, linking or creating them if needed
+func (s *OIDCService) login(ctx context.Context, identity *oidc.Identity) (*LoginResult, *models.User, error) {
+	user, err := s.userRepo.GetUserByIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil, errors.New("failed to complete login")
+	}
+
+	// Not linked yet: only a verified email may match or create a user
+	if user == nil {
+		if identity.Email == "" || !identity.EmailVerified {
+			return nil, nil, ErrEmailNotVerified
+		}
+
+		user, err = s.userRepo.GetUserByEmail(ctx, identity.Email)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			user, err = s.createUser(ctx, identity)
+			if err != nil {
+				return nil, nil, err
+			}
+		case err != nil:
+			return nil, nil, errors.New("failed to complete login")
+		case !user.EmailVerified:
+			// Whoever signed up with this email never proved they own it, so
+			// linking would hand them the provider account
+			return nil, nil, ErrAccountNotLinked
+		default:
+			linked, err := s.link(ctx, user.ID, identity, true)
+			if err != nil {
+				return nil, nil, err
+			}
+			user.Identities = append(user.Identities, *linked)
+		}
+	}
+
+	result, err := s.mfaService.Login(ctx, user, identity.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, user, nil
+}
+
+// createUser creates the user of a provider account on its first login
+func (s *OIDCService) createUser(ctx context.Context, identity *oidc.Identity) (*models.User, error) {
+	name := identity.Name
+	if strings.TrimSpace(name) == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	return s.userService.CreateOIDCUser(ctx, name, identity.Picture, models.Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: time.Now(),
+	})
+}
+
+// link links a provider account to userID; auto marks links made at login
+func (s *OIDCService) link(ctx context.Context, userID string, identity *oidc.Identity, auto bool) (*models.Identity, error) {
+	owner, err := s.userRepo.GetUserByIdentity(ctx, identity.Provider, identity.Subject)
+	switch {
+	case err == nil && owner.ID == userID:
+		return nil, ErrIdentityExists
+	case err == nil:
+		return nil, ErrIdentityInUse
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, errors.New("failed to link provider account")
+	}
+
+	linked := models.Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: time.Now(),
+	}
+
+	err = s.userRepo.AddIdentity(ctx, userID, linked)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrIdentityInUse
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrIdentityExists
+	}
+	if err != nil {
+		return nil, errors.New("failed to link provider account")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditIdentityLinked,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		After:      AuditSnapshot(linked),
+		Details:    map[string]interface{}{"automatic": auto},
+	})
+
+	return &linked, nil
+}
+
+// randomString returns 32 random bytes, URL-safe encoded
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
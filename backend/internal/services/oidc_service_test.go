@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"backend/internal/oidc/oidctest"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+// oidcFixture is an OIDCService with one provider, "stub", over a fresh database
+type oidcFixture struct {
+	*tokenFixture
+	oidc     *OIDCService
+	provider *oidctest.Provider
+	userRepo *repository.UserRepository
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+
+	f := newTokenFixture(t)
+	provider, server, err := oidctest.NewServer("testops")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	registry, err := oidc.Open(&utils.Config{
+		AppBaseURL: "http://app.example.com",
+		OIDCProviders: []utils.OIDCProviderConfig{{
+			Name:      "stub",
+			IssuerURL: provider.Issuer,
+			ClientID:  provider.ClientID,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	userRepo := f.service.userRepo
+	audit := NewAuditService(repository.NewAuditEventRepository(f.db))
+	mfa, err := NewMFAService(userRepo, f.service, f.jwt, MFAConfig{EncryptionKey: "test-key", PendingTTL: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("NewMFAService: %v", err)
+	}
+	service := NewOIDCService(registry, repository.NewOIDCLoginRepository(f.db), userRepo, NewUserService(userRepo, audit), mfa, audit)
+
+	return &oidcFixture{tokenFixture: f, oidc: service, provider: provider, userRepo: userRepo}
+}
+
+// login logs in with an ID token for the provider's current user
+func (f *oidcFixture) login(t *testing.T) (*models.User, error) {
+	t.Helper()
+
+	idToken, err := f.provider.IDToken("")
+	if err != nil {
+		t.Fatalf("IDToken: %v", err)
+	}
+	_, user, err := f.oidc.LoginWithIDToken(context.Background(), "stub", OIDCIDTokenRequest{IDToken: idToken})
+	return user, err
+}
+
+// assertNotLinked checks that the user with email has no linked provider account
+func (f *oidcFixture) assertNotLinked(t *testing.T, email string) {
+	t.Helper()
+
+	user, err := f.userRepo.GetUserByEmail(context.Background(), email)
+	if err != nil {
+		t.Fatalf("GetUserByEmail: %v", err)
+	}
+	if len(user.Identities) != 0 {
+		t.Errorf("%s has identities %+v, want none", email, user.Identities)
+	}
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.provider.Email = "carol@example.com"
+	f.provider.Name = "Carol"
+
+	user, err := f.login(t)
+	if err != nil {
+		t.Fatalf("LoginWithIDToken: %v", err)
+	}
+
+	stored, err := f.userRepo.GetUserByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if stored.Username != "Carol" || stored.Email != "carol@example.com" || !stored.EmailVerified || stored.Password != "" {
+		t.Errorf("created user = %+v, want Carol, carol@example.com, verified, without password", stored)
+	}
+	if stored.Role != models.RoleTester {
+		t.Errorf("created user role = %q, want %q", stored.Role, models.RoleTester)
+	}
+	if len(stored.Identities) != 1 || stored.Identities[0].Provider != "stub" || stored.Identities[0].Subject != "stub|carol@example.com" {
+		t.Errorf("created user identities = %+v, want the stub account", stored.Identities)
+	}
+
+	// The next login finds the user by the linked account
+	again, err := f.login(t)
+	if err != nil {
+		t.Fatalf("second LoginWithIDToken: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("second login user = %s, want %s", again.ID, user.ID)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidIDToken(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	claims := f.provider.Claims("")
+	claims["aud"] = "another-client"
+	idToken, err := f.provider.SignIDToken(claims)
+	if err != nil {
+		t.Fatalf("SignIDToken: %v", err)
+	}
+
+	_, _, err = f.oidc.LoginWithIDToken(context.Background(), "stub", OIDCIDTokenRequest{IDToken: idToken})
+	if !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("LoginWithIDToken = %v, want oidc.ErrInvalidIDToken", err)
+	}
+}
+
+func TestOIDCLoginDoesNotLinkUnverifiedEmails(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	// The provider has not verified the email: no user is matched or created
+	f.provider.Email = f.user.Email
+	f.provider.EmailVerified = false
+	if _, err := f.login(t); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("login with an unverified provider email = %v, want ErrEmailNotVerified", err)
+	}
+	f.assertNotLinked(t, f.user.Email)
+
+	f.provider.Email = "nobody@example.com"
+	if _, err := f.login(t); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("first login with an unverified provider email = %v, want ErrEmailNotVerified", err)
+	}
+	if _, err := f.userRepo.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("user created for an unverified provider email (lookup: %v)", err)
+	}
+
+	// The local user never verified the email: whoever signed up with it may not own it
+	f.provider.Email = f.user.Email
+	f.provider.EmailVerified = true
+	if _, err := f.login(t); !errors.Is(err, ErrAccountNotLinked) {
+		t.Errorf("login as a user with an unverified email = %v, want ErrAccountNotLinked", err)
+	}
+	f.assertNotLinked(t, f.user.Email)
+}
+
+func TestOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	dave := &models.User{Username: "dave", Email: "dave@example.com", Role: models.RoleTester, EmailVerified: true}
+	if err := f.userRepo.CreateUser(ctx, dave); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	f.provider.Email = dave.Email
+	user, err := f.login(t)
+	if err != nil {
+		t.Fatalf("LoginWithIDToken: %v", err)
+	}
+	if user.ID != dave.ID {
+		t.Errorf("login user = %s, want %s", user.ID, dave.ID)
+	}
+
+	stored, err := f.userRepo.GetUserByID(ctx, dave.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if len(stored.Identities) != 1 || stored.Identities[0].Subject != "stub|dave@example.com" {
+		t.Errorf("identities = %+v, want the stub account linked", stored.Identities)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+/**
+ * Personal Access Token Service
+ *
+ * Purpose: Handle business logic for personal access tokens
+ *
+ * Operations:
+ * - CreateToken: Mint a named, scoped, expiring token; the token is returned only here
+ * - ListTokens / RevokeToken: Manage the caller's tokens
+ * - Authenticate: Resolve a presented token to the claims of its owner
+ *
+ * A token acts as its owner, limited to its scopes and optionally to one
+ * project. Scopes can only be permissions the owner's role grants - their role
+ * in the pinned project, or their platform role for platform permissions - and
+ * roles are still checked on every request, so demoting a user also narrows
+ * their tokens.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// Token lifetime bounds, in days
+const (
+	defaultPersonalAccessTokenDays = 90
+	maxPersonalAccessTokenDays     = 365
+)
+
+// lastUsedResolution limits how often a token's last-used time is written
+const lastUsedResolution = time.Minute
+
+var (
+	// ErrPersonalAccessTokenNotFound is returned when a token does not exist or is not owned by the caller
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+	// ErrInvalidPersonalAccessToken is returned for unknown or expired tokens
+	ErrInvalidPersonalAccessToken = errors.New("invalid or expired personal access token")
+)
+
+type PersonalAccessTokenService struct {
+	tokenRepo      *repository.PersonalAccessTokenRepository
+	userRepo       *repository.UserRepository
+	roleService    *RoleService
+	projectService *ProjectService
+}
+
+// NewPersonalAccessTokenService creates a new personal access token service instance
+func NewPersonalAccessTokenService(tokenRepo *repository.PersonalAccessTokenRepository, userRepo *repository.UserRepository, roleService *RoleService, projectService *ProjectService) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		tokenRepo:      tokenRepo,
+		userRepo:       userRepo,
+		roleService:    roleService,
+		projectService: projectService,
+	}
+}
+
+// PersonalAccessTokenRequest represents the data needed to create a token
+type PersonalAccessTokenRequest struct {
+	Name          string              `json:"name"`
+	Scopes        []models.Permission `json:"scopes"`
+	ExpiresInDays int                 `json:"expires_in_days"` // defaults to 90, at most 365
+	ProjectID     string              `json:"project_id"`      // optional project to pin the token to
+}
+
+// CreateToken mints a new token for userID and returns it with its plaintext value
+func (s *PersonalAccessTokenService) CreateToken(ctx context.Context, userID string, req PersonalAccessTokenRequest) (*models.PersonalAccessToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return nil, "", errors.New("name must be at most 100 characters")
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, "", errors.New("at least one scope is required")
+	}
+	platformRole, err := s.roleService.CurrentRole(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	// Without a project, project scopes are checked per request against the
+	// role in whichever project the request selects
+	projectRole := models.RoleAdmin
+	if req.ProjectID != "" {
+		member, err := s.projectService.ResolveMember(ctx, userID, req.ProjectID)
+		if err != nil {
+			return nil, "", err
+		}
+		projectRole = member.Role
+	}
+	for _, scope := range req.Scopes {
+		if !models.IsValidPermission(scope) {
+			return nil, "", errors.New("unknown scope " + string(scope))
+		}
+		role := projectRole
+		if models.IsPlatformPermission(scope) {
+			role = platformRole
+		}
+		if !models.RoleHasPermission(role, scope) {
+			return nil, "", errors.New("scope " + string(scope) + " is not granted by your role")
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultPersonalAccessTokenDays
+	}
+	if days < 1 || days > maxPersonalAccessTokenDays {
+		return nil, "", errors.New("expires_in_days must be between 1 and 365")
+	}
+
+	plaintext, err := generatePersonalAccessToken()
+	if err != nil {
+		return nil, "", errors.New("failed to create personal access token")
+	}
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(plaintext),
+		Hint:      plaintext[len(plaintext)-4:],
+		Scopes:    req.Scopes,
+		ProjectID: req.ProjectID,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, "", errors.New("failed to create personal access token")
+	}
+
+	return token, plaintext, nil
+}
+
+// ListTokens returns every token owned by userID, without their values
+func (s *PersonalAccessTokenService) ListTokens(ctx context.Context, userID string) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve personal access tokens")
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken deletes a token owned by userID; it stops working immediately
+func (s *PersonalAccessTokenService) RevokeToken(ctx context.Context, userID, tokenID string) error {
+	err := s.tokenRepo.Delete(ctx, tokenID, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrPersonalAccessTokenNotFound
+	}
+	if err != nil {
+		return errors.New("failed to revoke personal access token")
+	}
+
+	return nil
+}
+
+// Authenticate returns the claims of the owner of a presented token, limited to its scopes
+func (s *PersonalAccessTokenService) Authenticate(ctx context.Context, plaintext string) (*TokenClaims, error) {
+	now := time.Now()
+
+	token, err := s.tokenRepo.GetByHash(ctx, hashToken(plaintext))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to verify personal access token")
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to verify personal access token")
+	}
+
+	if err := s.tokenRepo.TouchLastUsed(ctx, token.ID, AuditActorFromContext(ctx).IP, now, lastUsedResolution); err != nil {
+		log.Printf("Failed to record use of personal access token %s: %v", token.ID, err)
+	}
+
+	return &TokenClaims{
+		UserID:                user.ID,
+		Email:                 user.Email,
+		Username:              user.Username,
+		Role:                  user.Role,
+		Scopes:                token.Scopes,
+		ProjectID:             token.ProjectID,
+		PersonalAccessTokenID: token.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(token.ExpiresAt),
+		},
+	}, nil
+}
+
+// IsPersonalAccessToken reports whether a bearer token is a personal access token rather than a JWT
+func IsPersonalAccessToken(bearer string) bool {
+	return strings.HasPrefix(bearer, models.PersonalAccessTokenPrefix)
+}
+
+// generatePersonalAccessToken returns a new random token with the personal access token prefix
+func generatePersonalAccessToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return models.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
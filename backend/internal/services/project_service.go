@@ -0,0 +1,572 @@
+package services
+
+/**
+ * Project Service
+ *
+ * Purpose: Handle business logic for organizations, projects and memberships
+ *
+ * Operations:
+ * - ResolveMember: Find the caller's membership of the active project
+ * - CreateOrganization / ListOrganizations: Manage the caller's organizations
+ * - ListOrganizationMembers / SetOrganizationMember / RemoveOrganizationMember:
+ *   Manage who belongs to an organization (owners only)
+ * - CreateProject / ListProjects: Manage organization projects
+ * - ListProjectMembers / SetProjectMember / RemoveProjectMember: Manage who
+ *   may do what in a project
+ * - RemoveUser: Take a deleted user out of every organization and project
+ *
+ * Projects are the tenants: tests, suites, runs, results, schedules and
+ * workers belong to one and are only visible to its members. Every user has a
+ * personal project (ID = user ID) that is used when a request names no
+ * project; it is created on first use and cannot be shared. Memberships are
+ * cached like platform roles, so changes apply without waiting for tokens to
+ * expire.
+ */
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+var (
+	// ErrProjectNotFound is returned when a project does not exist or the caller is not a member
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrOrganizationNotFound is returned when an organization does not exist or the caller is not a member
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrNotOrganizationOwner is returned when a non-owner tries to manage an organization
+	ErrNotOrganizationOwner = errors.New("only organization owners can do this")
+	// ErrPersonalProject is returned when trying to share a personal project
+	ErrPersonalProject = errors.New("personal projects cannot be shared")
+	// ErrLastAdmin is returned when a change would leave a project without an admin or an organization without an owner
+	ErrLastAdmin = errors.New("the last admin or owner cannot be removed or demoted")
+)
+
+type ProjectService struct {
+	projectRepo *repository.ProjectRepository
+	orgRepo     *repository.OrganizationRepository
+	userRepo    *repository.UserRepository
+	members     *ttlCache[*models.ProjectMember]
+}
+
+// NewProjectService creates a new project service instance that caches memberships for cacheTTL
+func NewProjectService(projectRepo *repository.ProjectRepository, orgRepo *repository.OrganizationRepository, userRepo *repository.UserRepository, cacheTTL time.Duration) *ProjectService {
+	return &ProjectService{
+		projectRepo: projectRepo,
+		orgRepo:     orgRepo,
+		userRepo:    userRepo,
+		members:     newTTLCache[*models.ProjectMember](cacheTTL),
+	}
+}
+
+// ProjectRequest represents the data needed to create an organization or a project
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// MemberRequest represents the data needed to add a member or change their role
+type MemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// ProjectWithRole is a project together with the caller's role in it
+type ProjectWithRole struct {
+	models.Project `bson:",inline"`
+	Role           string `json:"role"`
+}
+
+// ==================================================
+// ACTIVE PROJECT
+// ==================================================
+
+// ResolveMember returns userID's membership of projectID
+// An empty projectID, or the user's own ID, selects their personal project,
+// in which they are always admin.
+func (s *ProjectService) ResolveMember(ctx context.Context, userID, projectID string) (*models.ProjectMember, error) {
+	if projectID == "" {
+		projectID = userID
+	}
+
+	key := projectID + ":" + userID
+	if member, ok := s.members.Get(key); ok {
+		return member, nil
+	}
+
+	var member *models.ProjectMember
+	if projectID == userID {
+		if err := s.projectRepo.EnsurePersonal(ctx, userID, "Personal"); err != nil {
+			return nil, errors.New("failed to retrieve project")
+		}
+		member = &models.ProjectMember{ID: key, ProjectID: projectID, UserID: userID, Role: models.RoleAdmin}
+	} else {
+		var err error
+		member, err = s.projectRepo.GetMember(ctx, projectID, userID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrProjectNotFound
+		}
+		if err != nil {
+			return nil, errors.New("failed to retrieve project")
+		}
+	}
+
+	s.members.Set(key, member)
+	return member, nil
+}
+
+// ==================================================
+// ORGANIZATIONS
+// ==================================================
+
+// CreateOrganization creates an organization owned by userID
+func (s *ProjectService) CreateOrganization(ctx context.Context, userID string, req ProjectRequest) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	org := &models.Organization{Name: name, CreatedBy: userID}
+	if err := s.orgRepo.Create(ctx, org); err != nil {
+		return nil, errors.New("failed to create organization")
+	}
+
+	owner := &models.OrganizationMember{OrganizationID: org.ID, UserID: userID, Role: models.OrgRoleOwner}
+	if err := s.orgRepo.SetMember(ctx, owner); err != nil {
+		return nil, errors.New("failed to create organization")
+	}
+
+	return org, nil
+}
+
+// ListOrganizations returns every organization userID belongs to
+func (s *ProjectService) ListOrganizations(ctx context.Context, userID string) ([]models.Organization, error) {
+	memberships, err := s.orgRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve organizations")
+	}
+
+	ids := make([]string, len(memberships))
+	for i, membership := range memberships {
+		ids[i] = membership.OrganizationID
+	}
+
+	orgs, err := s.orgRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, errors.New("failed to retrieve organizations")
+	}
+
+	return orgs, nil
+}
+
+// ListOrganizationMembers returns the members of an organization userID belongs to
+func (s *ProjectService) ListOrganizationMembers(ctx context.Context, userID, orgID string) ([]models.OrganizationMember, error) {
+	if _, err := s.organizationMember(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve organization members")
+	}
+
+	return members, nil
+}
+
+// SetOrganizationMember adds the user with req.Email to an organization owned by actorID, or changes their role
+func (s *ProjectService) SetOrganizationMember(ctx context.Context, actorID, orgID string, req MemberRequest) (*models.OrganizationMember, error) {
+	if err := s.requireOwner(ctx, orgID, actorID); err != nil {
+		return nil, err
+	}
+	if req.Role != models.OrgRoleOwner && req.Role != models.OrgRoleMember {
+		return nil, errors.New("role must be owner or member")
+	}
+
+	user, err := s.userByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Role != models.OrgRoleOwner {
+		if err := s.keepAnOwner(ctx, orgID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &models.OrganizationMember{OrganizationID: orgID, UserID: user.ID, Role: req.Role}
+	if err := s.orgRepo.SetMember(ctx, member); err != nil {
+		return nil, errors.New("failed to update organization member")
+	}
+
+	return s.orgRepo.GetMember(ctx, orgID, user.ID)
+}
+
+// RemoveOrganizationMember removes userID from an organization owned by actorID and from all its projects
+// It fails with ErrLastAdmin if that would leave the organization without an
+// owner or one of its projects without an admin; nothing is removed then.
+func (s *ProjectService) RemoveOrganizationMember(ctx context.Context, actorID, orgID, userID string) error {
+	if err := s.requireOwner(ctx, orgID, actorID); err != nil {
+		return err
+	}
+	if err := s.keepAnOwner(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	projects, err := s.projectRepo.ListByOrganization(ctx, orgID)
+	if err != nil {
+		return errors.New("failed to remove organization member")
+	}
+	for _, project := range projects {
+		member, err := s.projectRepo.GetMember(ctx, project.ID, userID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return errors.New("failed to remove organization member")
+		}
+		if member.Role != models.RoleAdmin {
+			continue
+		}
+		if err := s.keepAnAdmin(ctx, project.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	for _, project := range projects {
+		err := s.projectRepo.RemoveMember(ctx, project.ID, userID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("failed to remove organization member")
+		}
+		s.members.Delete(project.ID + ":" + userID)
+	}
+
+	err = s.orgRepo.RemoveMember(ctx, orgID, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to remove organization member")
+	}
+
+	return nil
+}
+
+// ==================================================
+// PROJECTS
+// ==================================================
+
+// CreateProject creates a project in an organization owned by actorID, who becomes its admin
+func (s *ProjectService) CreateProject(ctx context.Context, actorID, orgID string, req ProjectRequest) (*models.Project, error) {
+	if err := s.requireOwner(ctx, orgID, actorID); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	project := &models.Project{OrganizationID: orgID, Name: name, CreatedBy: actorID}
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return nil, errors.New("failed to create project")
+	}
+
+	admin := &models.ProjectMember{ProjectID: project.ID, UserID: actorID, Role: models.RoleAdmin}
+	if err := s.projectRepo.SetMember(ctx, admin); err != nil {
+		return nil, errors.New("failed to create project")
+	}
+
+	return project, nil
+}
+
+// ListProjects returns every project userID can use with their role, personal project first
+func (s *ProjectService) ListProjects(ctx context.Context, userID string) ([]ProjectWithRole, error) {
+	if _, err := s.ResolveMember(ctx, userID, ""); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.projectRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve projects")
+	}
+
+	roles := map[string]string{userID: models.RoleAdmin}
+	ids := []string{userID}
+	for _, membership := range memberships {
+		roles[membership.ProjectID] = membership.Role
+		ids = append(ids, membership.ProjectID)
+	}
+
+	projects, err := s.projectRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, errors.New("failed to retrieve projects")
+	}
+
+	result := []ProjectWithRole{}
+	for _, project := range projects {
+		entry := ProjectWithRole{Project: project, Role: roles[project.ID]}
+		if project.Personal {
+			result = append([]ProjectWithRole{entry}, result...)
+		} else {
+			result = append(result, entry)
+		}
+	}
+
+	return result, nil
+}
+
+// GetProject returns a project the caller is a member of, with their role
+func (s *ProjectService) GetProject(ctx context.Context, member *models.ProjectMember) (*ProjectWithRole, error) {
+	project, err := s.projectRepo.GetByID(ctx, member.ProjectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve project")
+	}
+
+	return &ProjectWithRole{Project: *project, Role: member.Role}, nil
+}
+
+// ListProjectMembers returns the members of a project
+func (s *ProjectService) ListProjectMembers(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	project, err := s.organizationProject(ctx, projectID)
+	if errors.Is(err, ErrPersonalProject) {
+		return []models.ProjectMember{{ID: projectID + ":" + project.CreatedBy, ProjectID: projectID, UserID: project.CreatedBy, Role: models.RoleAdmin, CreatedAt: project.CreatedAt}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.projectRepo.ListMembers(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve project members")
+	}
+
+	return members, nil
+}
+
+// SetProjectMember adds the user with req.Email to a project or changes their role
+// The user must belong to the project's organization.
+func (s *ProjectService) SetProjectMember(ctx context.Context, projectID string, req MemberRequest) (*models.ProjectMember, error) {
+	project, err := s.organizationProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.IsValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.userByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.organizationMember(ctx, project.OrganizationID, user.ID); err != nil {
+		return nil, errors.New("user is not a member of the project's organization")
+	}
+
+	if req.Role != models.RoleAdmin {
+		if err := s.keepAnAdmin(ctx, projectID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &models.ProjectMember{ProjectID: projectID, UserID: user.ID, Role: req.Role}
+	if err := s.projectRepo.SetMember(ctx, member); err != nil {
+		return nil, errors.New("failed to update project member")
+	}
+	s.members.Delete(projectID + ":" + user.ID)
+
+	return s.projectRepo.GetMember(ctx, projectID, user.ID)
+}
+
+// RemoveProjectMember removes userID from a project
+func (s *ProjectService) RemoveProjectMember(ctx context.Context, projectID, userID string) error {
+	if _, err := s.organizationProject(ctx, projectID); err != nil {
+		return err
+	}
+	if err := s.keepAnAdmin(ctx, projectID, userID); err != nil {
+		return err
+	}
+
+	err := s.projectRepo.RemoveMember(ctx, projectID, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to remove project member")
+	}
+	s.members.Delete(projectID + ":" + userID)
+
+	return nil
+}
+
+// ==================================================
+// HELPERS
+// ==================================================
+
+// organizationProject returns a project, or ErrPersonalProject (with the project) for personal ones
+func (s *ProjectService) organizationProject(ctx context.Context, projectID string) (*models.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve project")
+	}
+	if project.Personal {
+		return project, ErrPersonalProject
+	}
+
+	return project, nil
+}
+
+// organizationMember returns userID's membership of an organization
+func (s *ProjectService) organizationMember(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error) {
+	member, err := s.orgRepo.GetMember(ctx, orgID, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve organization")
+	}
+
+	return member, nil
+}
+
+// CheckRemoveUser fails with ErrLastAdmin if removing userID from everything
+// would leave an organization without an owner or a project without an admin
+func (s *ProjectService) CheckRemoveUser(ctx context.Context, userID string) error {
+	orgMemberships, projectMemberships, err := s.memberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.keepAdmins(ctx, userID, orgMemberships, projectMemberships)
+}
+
+// RemoveUser removes userID from every organization and project, before the user is deleted
+// It fails with ErrLastAdmin if that would leave an organization without an
+// owner or a project without an admin; nothing is removed then. Memberships
+// already removed are skipped, so it can be retried.
+func (s *ProjectService) RemoveUser(ctx context.Context, userID string) error {
+	orgMemberships, projectMemberships, err := s.memberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.keepAdmins(ctx, userID, orgMemberships, projectMemberships); err != nil {
+		return err
+	}
+
+	for _, member := range projectMemberships {
+		err := s.projectRepo.RemoveMember(ctx, member.ProjectID, userID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("failed to remove memberships")
+		}
+		s.members.Delete(member.ProjectID + ":" + userID)
+	}
+	for _, member := range orgMemberships {
+		err := s.orgRepo.RemoveMember(ctx, member.OrganizationID, userID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("failed to remove memberships")
+		}
+	}
+
+	return nil
+}
+
+// memberships returns every organization and project membership of userID
+func (s *ProjectService) memberships(ctx context.Context, userID string) ([]models.OrganizationMember, []models.ProjectMember, error) {
+	orgMemberships, err := s.orgRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.New("failed to retrieve memberships")
+	}
+	projectMemberships, err := s.projectRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.New("failed to retrieve memberships")
+	}
+
+	return orgMemberships, projectMemberships, nil
+}
+
+// keepAdmins fails with ErrLastAdmin if userID is the last owner or admin of any of their memberships
+func (s *ProjectService) keepAdmins(ctx context.Context, userID string, orgMemberships []models.OrganizationMember, projectMemberships []models.ProjectMember) error {
+	for _, member := range orgMemberships {
+		if member.Role != models.OrgRoleOwner {
+			continue
+		}
+		if err := s.keepAnOwner(ctx, member.OrganizationID, userID); err != nil {
+			return err
+		}
+	}
+	for _, member := range projectMemberships {
+		if member.Role != models.RoleAdmin {
+			continue
+		}
+		if err := s.keepAnAdmin(ctx, member.ProjectID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requireOwner checks that userID owns an organization
+func (s *ProjectService) requireOwner(ctx context.Context, orgID, userID string) error {
+	member, err := s.organizationMember(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.OrgRoleOwner {
+		return ErrNotOrganizationOwner
+	}
+
+	return nil
+}
+
+// keepAnOwner fails if userID is the only owner of an organization
+func (s *ProjectService) keepAnOwner(ctx context.Context, orgID, userID string) error {
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return errors.New("failed to retrieve organization members")
+	}
+
+	for _, member := range members {
+		if member.Role == models.OrgRoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+// keepAnAdmin fails if userID is the only admin of a project
+func (s *ProjectService) keepAnAdmin(ctx context.Context, projectID, userID string) error {
+	members, err := s.projectRepo.ListMembers(ctx, projectID)
+	if err != nil {
+		return errors.New("failed to retrieve project members")
+	}
+
+	for _, member := range members {
+		if member.Role == models.RoleAdmin && member.UserID != userID {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+// userByEmail looks up the user to add as a member
+func (s *ProjectService) userByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve user")
+	}
+
+	return user, nil
+}
@@ -1,24 +1,201 @@
 package services
 
+/**
+ * Result Service
+ *
+ * Purpose: Handle business logic for test results and their artifacts
+ *
+ * Operations:
+ * - StoreArtifact: Stream a video or screenshot straight to the artifact store
+ * - SaveResult: Record a run's result and finish the run
+ * - GetResults / GetResultByID: Read the results of the project's runs
+ *
+ * Artifacts are stored under the key runs/<run id>/<kind><ext>. Results keep
+ * only the key; download links are presigned each time a result is read.
+ */
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/storage"
+)
+
+// Artifact kinds accepted by StoreArtifact
+const (
+	ArtifactVideo      = "video"
+	ArtifactScreenshot = "screenshot"
+)
+
+// ErrResultNotFound is returned when a result does not exist or is not in the caller's project
+var ErrResultNotFound = errors.New("result not found")
+
+// artifactExtensions lists the file extensions accepted for each artifact kind;
+// the first entry is used when the upload has no usable file name
+var artifactExtensions = map[string][]string{
+	ArtifactVideo:      {".mp4", ".webm", ".avi", ".mkv"},
+	ArtifactScreenshot: {".png", ".jpg", ".jpeg"},
+}
+
 type ResultService struct {
-	// TODO: Add repository dependencies
+	resultRepo *repository.ResultRepository
+	runService *RunService
+	artifacts  storage.ArtifactStore
+	urlTTL     time.Duration
 }
 
-func NewResultService() *ResultService {
-	return &ResultService{}
+// NewResultService creates a new result service; urlTTL is how long artifact download links stay valid
+func NewResultService(resultRepo *repository.ResultRepository, runService *RunService, artifacts storage.ArtifactStore, urlTTL time.Duration) *ResultService {
+	return &ResultService{
+		resultRepo: resultRepo,
+		runService: runService,
+		artifacts:  artifacts,
+		urlTTL:     urlTTL,
+	}
 }
 
-func (s *ResultService) SaveResult(testID, status, videoPath, screenshotPath string) error {
-	// TODO: Implement save result logic
-	return nil
+// ResultRequest represents the result JSON a worker uploads
+type ResultRequest struct {
+	Status   string  `json:"status"` // passed, failed or error ("success" is accepted for passed)
+	Logs     string  `json:"logs"`
+	Duration float64 `json:"duration"` // in seconds
+	Error    string  `json:"error"`
 }
 
-func (s *ResultService) GetResults(testID string) ([]interface{}, error) {
-	// TODO: Implement get results logic
-	return nil, nil
+// BeginUpload checks that workerID is executing the run in projectID before anything is stored
+func (s *ResultService) BeginUpload(ctx context.Context, projectID, runID, workerID string) (*models.TestRun, error) {
+	return s.runService.GetRunForWorker(ctx, projectID, runID, workerID)
 }
 
-func (s *ResultService) GetResultByID(resultID string) (interface{}, error) {
-	// TODO: Implement get result by ID logic
-	return nil, nil
+// StoreArtifact streams an uploaded file to the artifact store and returns its key
+func (s *ResultService) StoreArtifact(ctx context.Context, runID, kind, filename string, content io.Reader) (string, error) {
+	extensions, ok := artifactExtensions[kind]
+	if !ok {
+		return "", invalidRequest("unknown artifact kind")
+	}
+
+	ext := strings.ToLower(path.Ext(filename))
+	if !slices.Contains(extensions, ext) {
+		ext = extensions[0]
+	}
+
+	key := path.Join("runs", runID, kind+ext)
+	if err := s.artifacts.Put(ctx, key, content, -1, mime.TypeByExtension(ext)); err != nil {
+		return "", errors.New("failed to store artifact: " + err.Error())
+	}
+
+	return key, nil
+}
+
+// DiscardArtifact removes an artifact stored for an upload that did not complete
+func (s *ResultService) DiscardArtifact(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.artifacts.Delete(ctx, key); err != nil {
+		log.Printf("Failed to discard artifact %s: %v", key, err)
+	}
+}
+
+// SaveResult records the result of a run and moves the run to its final status
+// The result is stored first, so a failed insert leaves the run running and
+// the upload can be retried; if the run cannot be finished the result is removed again
+func (s *ResultService) SaveResult(ctx context.Context, run *models.TestRun, req ResultRequest, videoKey, screenshotKey string) (*models.Result, error) {
+	if req.Status == "success" {
+		req.Status = models.RunStatusPassed
+	}
+	switch req.Status {
+	case models.RunStatusPassed, models.RunStatusFailed, models.RunStatusError:
+	default:
+		return nil, invalidRequest("status must be passed, failed or error")
+	}
+
+	result := &models.Result{
+		RunID:         run.ID,
+		TestID:        run.TestID,
+		ProjectID:     run.ProjectID,
+		UserID:        run.UserID,
+		WorkerID:      run.WorkerID,
+		Status:        req.Status,
+		VideoKey:      videoKey,
+		ScreenshotKey: screenshotKey,
+		Logs:          req.Logs,
+		Duration:      req.Duration,
+	}
+
+	if err := s.resultRepo.Create(ctx, result); err != nil {
+		return nil, errors.New("failed to save result")
+	}
+
+	if err := s.runService.FinishRun(ctx, run.ProjectID, run.ID, run.WorkerID, req.Status, req.Error); err != nil {
+		if delErr := s.resultRepo.Delete(context.WithoutCancel(ctx), result.ID); delErr != nil {
+			log.Printf("Failed to remove result %s of unfinished run %s: %v", result.ID, run.ID, delErr)
+		}
+		return nil, err
+	}
+
+	s.presign(ctx, result)
+	return result, nil
+}
+
+// GetResults returns the results of a run in projectID
+func (s *ResultService) GetResults(ctx context.Context, projectID, runID string) ([]models.Result, error) {
+	if _, err := s.runService.GetRun(ctx, projectID, runID); err != nil {
+		return nil, err
+	}
+
+	results, err := s.resultRepo.GetByRunID(ctx, runID, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve results")
+	}
+
+	for i := range results {
+		s.presign(ctx, &results[i])
+	}
+	return results, nil
+}
+
+// GetResultByID returns a single result in projectID
+func (s *ResultService) GetResultByID(ctx context.Context, projectID, resultID string) (*models.Result, error) {
+	result, err := s.resultRepo.GetByID(ctx, resultID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrResultNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve result")
+	}
+
+	s.presign(ctx, result)
+	return result, nil
+}
+
+// presign fills in short-lived download links for a result's artifacts
+// A link that cannot be created is left empty rather than failing the read
+func (s *ResultService) presign(ctx context.Context, result *models.Result) {
+	result.VideoURL = s.presignKey(ctx, result.VideoKey)
+	result.ScreenshotURL = s.presignKey(ctx, result.ScreenshotKey)
+}
+
+func (s *ResultService) presignKey(ctx context.Context, key string) string {
+	if key == "" {
+		return ""
+	}
+
+	url, err := s.artifacts.Presign(ctx, key, s.urlTTL)
+	if err != nil {
+		log.Printf("Failed to presign artifact %s: %v", key, err)
+		return ""
+	}
+	return url
 }
@@ -0,0 +1,114 @@
+package services
+
+/**
+ * Role Service
+ *
+ * Purpose: Manage users' platform roles
+ *
+ * Operations:
+ * - CurrentRole: Look up a user's current platform role
+ * - SetUserRole: Change a user's platform role
+ *
+ * The platform role grants platform permissions such as users:admin; what a
+ * user may do inside a project is decided by their project role (see
+ * ProjectService).
+ *
+ * Access tokens carry the role the user had when they were issued, but
+ * permissions are always checked against the role stored in the database.
+ * Lookups are cached for a short time, so a role change takes effect within
+ * that time on every replica (immediately on the one that made the change)
+ * without waiting for tokens to expire.
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+var (
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidRole is returned for roles that are not in models.RolePermissions
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrOwnRole is returned when a user tries to change their own role
+	ErrOwnRole = errors.New("you cannot change your own role")
+)
+
+type RoleService struct {
+	userRepo *repository.UserRepository
+	audit    *AuditService
+	roles    *ttlCache[string]
+}
+
+// NewRoleService creates a new role service instance that caches roles for cacheTTL
+func NewRoleService(userRepo *repository.UserRepository, audit *AuditService, cacheTTL time.Duration) *RoleService {
+	return &RoleService{
+		userRepo: userRepo,
+		audit:    audit,
+		roles:    newTTLCache[string](cacheTTL),
+	}
+}
+
+// CurrentRole returns the role userID has now
+func (s *RoleService) CurrentRole(ctx context.Context, userID string) (string, error) {
+	if role, ok := s.roles.Get(userID); ok {
+		return role, nil
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", errors.New("failed to retrieve user role")
+	}
+
+	s.roles.Set(userID, user.Role)
+
+	return user.Role, nil
+}
+
+// SetUserRole changes the role of userID on behalf of actorID
+// Users cannot change their own role, so the last admin cannot lock everyone out.
+func (s *RoleService) SetUserRole(ctx context.Context, actorID, userID, role string) error {
+	if !models.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if actorID == userID {
+		return ErrOwnRole
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to update user role")
+	}
+
+	err = s.userRepo.UpdateUserRole(ctx, userID, role)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to update user role")
+	}
+
+	s.roles.Delete(userID)
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditRoleChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]interface{}{"role": user.Role},
+		After:      map[string]interface{}{"role": role},
+	})
+
+	return nil
+}
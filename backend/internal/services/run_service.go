@@ -0,0 +1,378 @@
+package services
+
+/**
+ * Run Service
+ *
+ * Purpose: Handle business logic for test runs
+ *
+ * Operations:
+ * - CreateRun: Snapshot a test into a new run and enqueue it for the runners
+ * - GetRun / ListRuns: Read the project's runs
+ * - CancelRun: Cancel a run that has not finished yet and drop its queue job
+ * - StartRun: Mark a run as picked up by a worker
+ * - RequeueRun: Put a run whose worker went offline back in the queue, or
+ *   mark it as errored once its job used up its delivery attempts
+ * - ReleaseJob: Give a job a worker will not run back to the queue
+ * - GetRunForWorker / FinishRun: Let the executing worker report the outcome
+ */
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/queue"
+	"backend/internal/repository"
+)
+
+var (
+	// ErrRunNotFound is returned when a run does not exist or is not in the caller's project
+	ErrRunNotFound = errors.New("run not found")
+
+	// ErrInvalidRunTransition is returned when a run cannot move to the requested status
+	ErrInvalidRunTransition = errors.New("run cannot change to the requested status")
+
+	// ErrRunNotOwned is returned when a worker acts on a run it is not executing
+	ErrRunNotOwned = errors.New("run is not being executed by this worker")
+)
+
+// runTransitions lists, for each target status, the statuses a run may move from
+var runTransitions = map[string][]string{
+	models.RunStatusQueued:    {models.RunStatusRunning},
+	models.RunStatusRunning:   {models.RunStatusQueued},
+	models.RunStatusPassed:    {models.RunStatusRunning},
+	models.RunStatusFailed:    {models.RunStatusRunning},
+	models.RunStatusError:     {models.RunStatusQueued, models.RunStatusRunning},
+	models.RunStatusCancelled: {models.RunStatusQueued, models.RunStatusRunning},
+}
+
+// Default run options, matching runner/src/job_parser.py
+const (
+	defaultRunBrowser = "chrome"
+	defaultRunTimeout = 300
+)
+
+type RunService struct {
+	runRepo       *repository.TestRunRepository
+	testService   *TestService
+	workerService *WorkerService
+}
+
+// NewRunService creates a new run service instance
+func NewRunService(runRepo *repository.TestRunRepository, testService *TestService, workerService *WorkerService) *RunService {
+	return &RunService{
+		runRepo:       runRepo,
+		testService:   testService,
+		workerService: workerService,
+	}
+}
+
+// RunRequest holds the optional execution settings for a new run
+type RunRequest struct {
+	Browser  string `json:"browser"`
+	Headless bool   `json:"headless"`
+	Timeout  int    `json:"timeout"` // in seconds
+}
+
+// CreateRun snapshots a test in projectID into a new queued run started by userID and enqueues it
+func (s *RunService) CreateRun(ctx context.Context, projectID, userID, testID string, req RunRequest) (*models.TestRun, error) {
+	test, err := s.testService.GetTestByID(ctx, projectID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+
+	run, err := s.createRun(ctx, userID, test, req, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// normalize validates the request and fills in the default browser and timeout
+func (req *RunRequest) normalize() error {
+	if req.Browser == "" {
+		req.Browser = defaultRunBrowser
+	}
+	if req.Browser != "chrome" && req.Browser != "firefox" {
+		return errors.New("browser must be chrome or firefox")
+	}
+	if req.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if req.Timeout == 0 {
+		req.Timeout = defaultRunTimeout
+	}
+	return nil
+}
+
+// createRun stores and enqueues a run of test, started by userID, with an already normalized request
+// The run belongs to the test's project. suiteRunID links the run to the suite run that launched it, if any. If the
+// run was stored but could not be enqueued it is returned, marked as errored,
+// together with the error.
+func (s *RunService) createRun(ctx context.Context, userID string, test *models.Test, req RunRequest, suiteRunID string) (*models.TestRun, error) {
+	run := &models.TestRun{
+		TestID:     test.ID,
+		SuiteRunID: suiteRunID,
+		ProjectID:  test.ProjectID,
+		UserID:     userID,
+		TestName:   test.Name,
+		Script:     test.Script,
+		Browser:    req.Browser,
+		Headless:   req.Headless,
+		Timeout:    req.Timeout,
+		Status:     models.RunStatusQueued,
+	}
+
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		return nil, errors.New("failed to create run")
+	}
+
+	if err := s.enqueue(ctx, run); err != nil {
+		run.Status = models.RunStatusError
+		run.Error = err.Error()
+		return run, err
+	}
+
+	return run, nil
+}
+
+// enqueue hands a queued run to the job queue, marking it as errored if that fails
+func (s *RunService) enqueue(ctx context.Context, run *models.TestRun) error {
+	jobID, err := s.workerService.EnqueueJob(ctx, run)
+	if err != nil {
+		if err := s.transition(ctx, run.ID, models.RunStatusError, bson.M{"error": err.Error()}); err != nil {
+			log.Printf("Failed to mark run %s as errored: %v", run.ID, err)
+		}
+		return err
+	}
+
+	run.JobID = jobID
+	if err := s.runRepo.SetJobID(ctx, run.ID, jobID); err != nil {
+		log.Printf("Failed to record job %s for run %s: %v", jobID, run.ID, err)
+	}
+
+	return nil
+}
+
+// GetRun returns a single run in projectID
+func (s *RunService) GetRun(ctx context.Context, projectID, runID string) (*models.TestRun, error) {
+	run, err := s.runRepo.GetByID(ctx, runID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve run")
+	}
+
+	return run, nil
+}
+
+// ListRuns returns the run history of a test in projectID
+func (s *RunService) ListRuns(ctx context.Context, projectID, testID string) ([]models.TestRun, error) {
+	if _, err := s.testService.GetTestByID(ctx, projectID, testID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.runRepo.ListByTest(ctx, testID, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve runs")
+	}
+
+	return runs, nil
+}
+
+// CancelRun cancels a queued or running run in projectID
+// Its job is removed from the queue, whether it is waiting or leased, so no
+// runner picks it up again
+func (s *RunService) CancelRun(ctx context.Context, projectID, runID string) (*models.TestRun, error) {
+	run, err := s.GetRun(ctx, projectID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transition(ctx, runID, models.RunStatusCancelled, nil); err != nil {
+		return nil, err
+	}
+
+	if run.JobID != "" {
+		err := s.workerService.RemoveJob(ctx, projectID, run.JobID)
+		if err != nil && !errors.Is(err, queue.ErrJobNotFound) {
+			log.Printf("Failed to remove job %s of cancelled run %s: %v", run.JobID, runID, err)
+		}
+	}
+
+	return s.GetRun(ctx, projectID, runID)
+}
+
+// StartRun marks a queued run in projectID as running on workerID
+// Returns ErrInvalidRunTransition if the run was cancelled, finished or another
+// worker already started it; workerID's lease on the run's job is then
+// acknowledged, so the stale delivery is not handed out again
+func (s *RunService) StartRun(ctx context.Context, projectID, runID, workerID string) error {
+	run, err := s.GetRun(ctx, projectID, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status == models.RunStatusRunning && run.WorkerID == workerID {
+		return nil
+	}
+
+	err = s.transition(ctx, runID, models.RunStatusRunning, bson.M{"worker_id": workerID})
+	if !errors.Is(err, ErrInvalidRunTransition) {
+		return err
+	}
+
+	if run.JobID != "" {
+		ackErr := s.workerService.AckJob(ctx, projectID, workerID, run.JobID)
+		if ackErr != nil && !errors.Is(ackErr, queue.ErrJobNotLeased) {
+			log.Printf("Failed to ack job %s of run %s that cannot be started: %v", run.JobID, runID, ackErr)
+		}
+	}
+
+	return err
+}
+
+// RequeueRun puts a run that workerID was executing back in the queue
+// The worker's lease on the job is released so exactly one new delivery happens;
+// if the lease is already gone the run is enqueued as a new job
+func (s *RunService) RequeueRun(ctx context.Context, runID, workerID string) error {
+	run, err := s.runRepo.FindByID(ctx, runID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRunNotFound
+	}
+	if err != nil {
+		return errors.New("failed to retrieve run")
+	}
+	if run.Status != models.RunStatusRunning || run.WorkerID != workerID {
+		return nil
+	}
+
+	err = s.transition(ctx, runID, models.RunStatusQueued, bson.M{"worker_id": "", "started_at": nil})
+	if err != nil {
+		return err
+	}
+
+	err = s.workerService.RequeueJob(ctx, run.ProjectID, workerID, run.JobID)
+	if errors.Is(err, queue.ErrJobNotLeased) {
+		return s.enqueue(ctx, run)
+	}
+	if errors.Is(err, queue.ErrJobDeadLettered) {
+		return s.transition(ctx, runID, models.RunStatusError, bson.M{"error": "run was not completed after too many deliveries"})
+	}
+	if err != nil {
+		return errors.New("failed to requeue job")
+	}
+
+	return nil
+}
+
+// ReleaseJob gives a job of projectID that workerID leased but will not run back to the queue
+// If the job used up its delivery attempts its run is marked as errored
+func (s *RunService) ReleaseJob(ctx context.Context, projectID, workerID, jobID string) error {
+	err := s.workerService.RequeueJob(ctx, projectID, workerID, jobID)
+	if !errors.Is(err, queue.ErrJobDeadLettered) {
+		return err
+	}
+
+	run, err := s.runRepo.GetByJobID(ctx, jobID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to retrieve run")
+	}
+
+	err = s.transition(ctx, run.ID, models.RunStatusError, bson.M{"error": "run was not completed after too many deliveries"})
+	if err != nil && !errors.Is(err, ErrInvalidRunTransition) {
+		return err
+	}
+
+	return nil
+}
+
+// GetRunForWorker returns a run in projectID only while workerID is executing it
+func (s *RunService) GetRunForWorker(ctx context.Context, projectID, runID, workerID string) (*models.TestRun, error) {
+	run, err := s.runRepo.GetByID(ctx, runID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve run")
+	}
+	if run.Status != models.RunStatusRunning || run.WorkerID != workerID {
+		return nil, ErrRunNotOwned
+	}
+
+	return run, nil
+}
+
+// FinishRun records the outcome reported by the worker executing the run
+// and acknowledges its queue job so it is never delivered again
+func (s *RunService) FinishRun(ctx context.Context, projectID, runID, workerID, status, errorMessage string) error {
+	run, err := s.GetRunForWorker(ctx, projectID, runID, workerID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.RunStatusPassed, models.RunStatusFailed, models.RunStatusError:
+	default:
+		return invalidRequest("status must be passed, failed or error")
+	}
+
+	set := bson.M{}
+	if errorMessage != "" {
+		set["error"] = errorMessage
+	}
+	if err := s.transition(ctx, runID, status, set); err != nil {
+		return err
+	}
+
+	// The run is finished either way; a lost lease only means the job was handed
+	// to another worker, which cannot start a finished run
+	if err := s.workerService.AckJob(ctx, run.ProjectID, workerID, run.JobID); err != nil {
+		log.Printf("Failed to ack job %s for run %s: %v", run.JobID, runID, err)
+	}
+
+	return nil
+}
+
+// transition moves a run to status if the state machine allows it from the current status
+// Terminal statuses also record the finish time
+func (s *RunService) transition(ctx context.Context, runID, status string, set bson.M) error {
+	from, ok := runTransitions[status]
+	if !ok {
+		return ErrInvalidRunTransition
+	}
+
+	if set == nil {
+		set = bson.M{}
+	}
+	now := time.Now()
+	switch status {
+	case models.RunStatusRunning:
+		set["started_at"] = now
+	case models.RunStatusPassed, models.RunStatusFailed, models.RunStatusError, models.RunStatusCancelled:
+		set["finished_at"] = now
+	}
+
+	err := s.runRepo.UpdateStatus(ctx, runID, from, status, set)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidRunTransition
+	}
+	if err != nil {
+		return errors.New("failed to update run status")
+	}
+
+	return nil
+}
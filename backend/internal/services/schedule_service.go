@@ -0,0 +1,386 @@
+package services
+
+/**
+ * Schedule Service
+ *
+ * Purpose: Handle business logic for scheduled runs
+ *
+ * Operations:
+ * - CreateSchedule / GetAllSchedules / GetScheduleByID / UpdateSchedule / DeleteSchedule:
+ *   Manage the project's schedules
+ * - FireDue: Start the runs of every schedule that is due (called by the Scheduler)
+ *
+ * Scheduled runs are started through RunService.CreateRun and
+ * SuiteService.RunSuite, exactly like manual runs, on behalf of the
+ * schedule's creator. Schedules that can never fire again (an invalid cron
+ * expression or timezone, or a creator who was disabled, deleted or lost
+ * access to the project) are disabled with the reason as their last error.
+ */
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// maxDueSchedules is the most schedules fired in one scheduler tick
+const maxDueSchedules = 100
+
+// ErrScheduleNotFound is returned when a schedule does not exist or is not in the caller's project
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// cronParser accepts standard 5-field expressions and descriptors such as @daily
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type ScheduleService struct {
+	scheduleRepo   *repository.ScheduleRepository
+	testService    *TestService
+	runService     *RunService
+	suiteService   *SuiteService
+	projectService *ProjectService
+	userAdmin      *UserAdminService
+}
+
+// NewScheduleService creates a new schedule service instance
+func NewScheduleService(scheduleRepo *repository.ScheduleRepository, testService *TestService, runService *RunService, suiteService *SuiteService, projectService *ProjectService, userAdmin *UserAdminService) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo:   scheduleRepo,
+		testService:    testService,
+		runService:     runService,
+		suiteService:   suiteService,
+		projectService: projectService,
+		userAdmin:      userAdmin,
+	}
+}
+
+// ScheduleRequest represents the data needed to create a schedule
+type ScheduleRequest struct {
+	Name       string `json:"name"`
+	Cron       string `json:"cron"`
+	Timezone   string `json:"timezone"` // defaults to UTC
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Browser    string `json:"browser"`
+	Headless   bool   `json:"headless"`
+	Timeout    int    `json:"timeout"`
+	Enabled    *bool  `json:"enabled"` // defaults to true
+}
+
+// ScheduleUpdateRequest represents a partial update; nil fields are left unchanged
+type ScheduleUpdateRequest struct {
+	Name       *string `json:"name"`
+	Cron       *string `json:"cron"`
+	Timezone   *string `json:"timezone"`
+	TargetType *string `json:"target_type"`
+	TargetID   *string `json:"target_id"`
+	Browser    *string `json:"browser"`
+	Headless   *bool   `json:"headless"`
+	Timeout    *int    `json:"timeout"`
+	Enabled    *bool   `json:"enabled"`
+}
+
+// CreateSchedule validates input and stores a new schedule in projectID, created by userID
+func (s *ScheduleService) CreateSchedule(ctx context.Context, projectID, userID string, req ScheduleRequest) (*models.Schedule, error) {
+	schedule := &models.Schedule{
+		Name:       strings.TrimSpace(req.Name),
+		Cron:       strings.TrimSpace(req.Cron),
+		Timezone:   req.Timezone,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Browser:    req.Browser,
+		Headless:   req.Headless,
+		Timeout:    req.Timeout,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		ProjectID:  projectID,
+		UserID:     userID,
+	}
+
+	if err := s.validate(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, errors.New("failed to create schedule")
+	}
+
+	return schedule, nil
+}
+
+// GetAllSchedules returns every schedule in projectID
+func (s *ScheduleService) GetAllSchedules(ctx context.Context, projectID string) ([]models.Schedule, error) {
+	schedules, err := s.scheduleRepo.GetAll(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve schedules")
+	}
+
+	return schedules, nil
+}
+
+// GetScheduleByID returns a single schedule in projectID
+func (s *ScheduleService) GetScheduleByID(ctx context.Context, projectID, scheduleID string) (*models.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, scheduleID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve schedule")
+	}
+
+	return schedule, nil
+}
+
+// UpdateSchedule applies a partial update to a schedule in projectID and returns the new version
+// The next fire time is recomputed from now
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, projectID, scheduleID string, req ScheduleUpdateRequest) (*models.Schedule, error) {
+	schedule, err := s.GetScheduleByID(ctx, projectID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		schedule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Cron != nil {
+		schedule.Cron = strings.TrimSpace(*req.Cron)
+	}
+	if req.Timezone != nil {
+		schedule.Timezone = *req.Timezone
+	}
+	if req.TargetType != nil {
+		schedule.TargetType = *req.TargetType
+	}
+	if req.TargetID != nil {
+		schedule.TargetID = *req.TargetID
+	}
+	if req.Browser != nil {
+		schedule.Browser = *req.Browser
+	}
+	if req.Headless != nil {
+		schedule.Headless = *req.Headless
+	}
+	if req.Timeout != nil {
+		schedule.Timeout = *req.Timeout
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	if err := s.validate(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	updates := bson.M{
+		"name":         schedule.Name,
+		"cron":         schedule.Cron,
+		"timezone":     schedule.Timezone,
+		"target_type":  schedule.TargetType,
+		"target_id":    schedule.TargetID,
+		"browser":      schedule.Browser,
+		"headless":     schedule.Headless,
+		"timeout":      schedule.Timeout,
+		"enabled":      schedule.Enabled,
+		"next_fire_at": schedule.NextFireAt,
+	}
+
+	err = s.scheduleRepo.Update(ctx, scheduleID, projectID, updates)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to update schedule")
+	}
+
+	return s.GetScheduleByID(ctx, projectID, scheduleID)
+}
+
+// DeleteSchedule removes a schedule in projectID; runs it already started are kept
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, projectID, scheduleID string) error {
+	err := s.scheduleRepo.Delete(ctx, scheduleID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrScheduleNotFound
+	}
+	if err != nil {
+		return errors.New("failed to delete schedule")
+	}
+
+	return nil
+}
+
+// FireDue starts the runs of every enabled schedule whose next fire time has passed
+// Each schedule is claimed before it fires, so on several replicas it fires once.
+// Fire times missed while no backend was running are skipped, not caught up.
+func (s *ScheduleService) FireDue(ctx context.Context, now time.Time) {
+	due, err := s.scheduleRepo.GetDue(ctx, now, maxDueSchedules)
+	if err != nil {
+		log.Printf("Scheduler: failed to list due schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range due {
+		next, err := nextFireTime(schedule.Cron, schedule.Timezone, now)
+		if err != nil {
+			s.disable(ctx, &schedule, err.Error())
+			continue
+		}
+
+		reason, err := s.ownerLost(ctx, &schedule)
+		if err != nil {
+			log.Printf("Scheduler: failed to check the creator of schedule %s: %v", schedule.ID, err)
+			continue
+		}
+		if reason != "" {
+			s.disable(ctx, &schedule, reason)
+			continue
+		}
+
+		err = s.scheduleRepo.Claim(ctx, schedule.ID, *schedule.NextFireAt, next, now)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Another replica fired it, or it was edited in the meantime
+			continue
+		}
+		if err != nil {
+			log.Printf("Scheduler: failed to claim schedule %s: %v", schedule.ID, err)
+			continue
+		}
+
+		runID, err := s.fire(ctx, &schedule)
+		errorMessage := ""
+		if err != nil {
+			errorMessage = err.Error()
+			log.Printf("Scheduler: schedule %s failed to start a run: %v", schedule.ID, err)
+		} else {
+			log.Printf("Scheduler: schedule %s started run %s", schedule.ID, runID)
+		}
+
+		if err := s.scheduleRepo.RecordFire(ctx, schedule.ID, runID, errorMessage); err != nil {
+			log.Printf("Scheduler: failed to record fire of schedule %s: %v", schedule.ID, err)
+		}
+	}
+}
+
+// ownerLost returns why the schedule's creator can no longer start its runs, or "" if they still can
+func (s *ScheduleService) ownerLost(ctx context.Context, schedule *models.Schedule) (string, error) {
+	active, err := s.userAdmin.IsActive(ctx, schedule.UserID)
+	if err != nil {
+		return "", err
+	}
+	if !active {
+		return "the schedule's creator was disabled or deleted", nil
+	}
+
+	member, err := s.projectService.ResolveMember(ctx, schedule.UserID, schedule.ProjectID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return "the schedule's creator is no longer a member of the project", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !models.RoleHasPermission(member.Role, models.PermRunsCreate) {
+		return "the schedule's creator may no longer start runs", nil
+	}
+
+	return "", nil
+}
+
+// disable turns off a schedule that can never fire again and records why
+func (s *ScheduleService) disable(ctx context.Context, schedule *models.Schedule, reason string) {
+	log.Printf("Scheduler: disabling schedule %s: %s", schedule.ID, reason)
+	if err := s.scheduleRepo.Disable(ctx, schedule.ID, reason); err != nil {
+		log.Printf("Scheduler: failed to disable schedule %s: %v", schedule.ID, err)
+	}
+}
+
+// fire starts a run of the schedule's target on behalf of its creator and returns its ID
+func (s *ScheduleService) fire(ctx context.Context, schedule *models.Schedule) (string, error) {
+	switch schedule.TargetType {
+	case models.ScheduleTargetTest:
+		run, err := s.runService.CreateRun(ctx, schedule.ProjectID, schedule.UserID, schedule.TargetID, RunRequest{
+			Browser:  schedule.Browser,
+			Headless: schedule.Headless,
+			Timeout:  schedule.Timeout,
+		})
+		if err != nil {
+			return "", err
+		}
+		return run.ID, nil
+	case models.ScheduleTargetSuite:
+		suiteRun, err := s.suiteService.RunSuite(ctx, schedule.ProjectID, schedule.UserID, schedule.TargetID, SuiteRunRequest{})
+		if err != nil {
+			return "", err
+		}
+		return suiteRun.ID, nil
+	}
+	return "", errors.New("unknown schedule target")
+}
+
+// validate checks a schedule and sets its next fire time (nil when disabled)
+func (s *ScheduleService) validate(ctx context.Context, schedule *models.Schedule) error {
+	if schedule.Name == "" {
+		return errors.New("name is required")
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+
+	next, err := nextFireTime(schedule.Cron, schedule.Timezone, time.Now())
+	if err != nil {
+		return err
+	}
+
+	switch schedule.TargetType {
+	case models.ScheduleTargetTest:
+		if _, err := s.testService.GetTestByID(ctx, schedule.ProjectID, schedule.TargetID); err != nil {
+			return err
+		}
+		req := RunRequest{Browser: schedule.Browser, Timeout: schedule.Timeout}
+		if err := req.normalize(); err != nil {
+			return err
+		}
+	case models.ScheduleTargetSuite:
+		if _, err := s.suiteService.GetSuiteByID(ctx, schedule.ProjectID, schedule.TargetID); err != nil {
+			return err
+		}
+	default:
+		return errors.New("target_type must be test or suite")
+	}
+
+	schedule.NextFireAt = nil
+	if schedule.Enabled {
+		schedule.NextFireAt = &next
+	}
+
+	return nil
+}
+
+// nextFireTime returns the first time after after that the cron expression matches in timezone
+func nextFireTime(expression, timezone string, after time.Time) (time.Time, error) {
+	if expression == "" {
+		return time.Time{}, errors.New("cron is required")
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, errors.New("unknown timezone " + timezone)
+	}
+
+	schedule, err := cronParser.Parse(expression)
+	if err != nil {
+		return time.Time{}, errors.New("invalid cron expression: " + err.Error())
+	}
+
+	next := schedule.Next(after.In(location))
+	if next.IsZero() {
+		return time.Time{}, errors.New("cron expression never fires")
+	}
+
+	return next.UTC(), nil
+}
@@ -0,0 +1,46 @@
+package services
+
+/**
+ * Scheduler
+ *
+ * Purpose: Start scheduled runs from inside the API process
+ *
+ * Periodically asks the schedule service to fire every due schedule. Any
+ * number of backend replicas may run a scheduler at the same time; each
+ * schedule is claimed with a conditional update before it fires.
+ */
+
+import (
+	"context"
+	"time"
+)
+
+type Scheduler struct {
+	scheduleService *ScheduleService
+	interval        time.Duration
+}
+
+// NewScheduler creates a scheduler that checks for due schedules every interval
+func NewScheduler(scheduleService *ScheduleService, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		scheduleService: scheduleService,
+		interval:        interval,
+	}
+}
+
+// Start runs the scheduler in the background until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.scheduleService.FireDue(ctx, now)
+			}
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+package services
+
+/**
+ * Session Service
+ *
+ * Purpose: Show users where they are logged in and let them sign out devices
+ *
+ * Operations:
+ * - ListSessions: A user's login sessions and personal access tokens
+ * - RevokeSession: End one of them
+ *
+ * Login sessions come from TokenService, personal access tokens from
+ * PersonalAccessTokenService; tokens are listed with method "token" and
+ * their name as the device.
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/models"
+)
+
+type SessionService struct {
+	tokenService *TokenService
+	patService   *PersonalAccessTokenService
+	audit        *AuditService
+}
+
+// NewSessionService creates a new session service instance
+func NewSessionService(tokenService *TokenService, patService *PersonalAccessTokenService, audit *AuditService) *SessionService {
+	return &SessionService{
+		tokenService: tokenService,
+		patService:   patService,
+		audit:        audit,
+	}
+}
+
+// ListSessions returns the active sessions of userID, login sessions first
+// The session currentSessionID is marked as current.
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]models.Session, error) {
+	sessions, err := s.tokenService.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	tokens, err := s.patService.ListTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		if !now.Before(token.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, models.Session{
+			ID:         token.ID,
+			UserID:     token.UserID,
+			Method:     models.SessionMethodToken,
+			Device:     token.Name,
+			IP:         token.LastUsedIP,
+			CreatedAt:  token.CreatedAt,
+			LastSeenAt: token.LastUsedAt,
+			ExpiresAt:  token.ExpiresAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends a login session or revokes a personal access token of userID
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	err := s.tokenService.RevokeSession(ctx, userID, sessionID)
+	if err == nil {
+		s.audit.Record(ctx, models.AuditEvent{
+			Action:     models.AuditSessionRevoked,
+			TargetType: models.AuditTargetSession,
+			TargetID:   sessionID,
+		})
+		return nil
+	}
+	if !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+
+	err = s.patService.RevokeToken(ctx, userID, sessionID)
+	if errors.Is(err, ErrPersonalAccessTokenNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditTokenRevoked,
+		TargetType: models.AuditTargetToken,
+		TargetID:   sessionID,
+	})
+
+	return nil
+}
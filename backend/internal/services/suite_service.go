@@ -0,0 +1,412 @@
+package services
+
+/**
+ * Suite Service
+ *
+ * Purpose: Handle business logic for test suites and suite runs
+ *
+ * Operations:
+ * - CreateSuite / GetAllSuites / GetSuiteByID / UpdateSuite / DeleteSuite:
+ *   Manage the project's suites (ordered lists of the project's tests)
+ * - RunSuite: Launch one run per member test, grouped under a suite run
+ * - GetSuiteRun / ListSuiteRuns: Read suite runs with their aggregated status
+ * - CancelSuiteRun: Cancel every member run that has not finished yet
+ *
+ * A suite run's status is aggregated from its member runs each time it is
+ * read, and stored once the last member run has finished.
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// maxSuiteTests is the most tests a single suite may contain
+const maxSuiteTests = 500
+
+var (
+	// ErrSuiteNotFound is returned when a suite does not exist or is not in the caller's project
+	ErrSuiteNotFound = errors.New("suite not found")
+
+	// ErrSuiteRunNotFound is returned when a suite run does not exist or is not in the caller's project
+	ErrSuiteRunNotFound = errors.New("suite run not found")
+)
+
+type SuiteService struct {
+	suiteRepo    *repository.SuiteRepository
+	suiteRunRepo *repository.SuiteRunRepository
+	runRepo      *repository.TestRunRepository
+	testService  *TestService
+	runService   *RunService
+}
+
+// NewSuiteService creates a new suite service instance
+func NewSuiteService(suiteRepo *repository.SuiteRepository, suiteRunRepo *repository.SuiteRunRepository, runRepo *repository.TestRunRepository, testService *TestService, runService *RunService) *SuiteService {
+	return &SuiteService{
+		suiteRepo:    suiteRepo,
+		suiteRunRepo: suiteRunRepo,
+		runRepo:      runRepo,
+		testService:  testService,
+		runService:   runService,
+	}
+}
+
+// SuiteRequest represents the data needed to create a suite
+type SuiteRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	TestIDs     []string `json:"test_ids"`
+	Browser     string   `json:"browser"`
+	Headless    bool     `json:"headless"`
+	Timeout     int      `json:"timeout"` // in seconds
+}
+
+// SuiteUpdateRequest represents a partial update; nil fields are left unchanged
+type SuiteUpdateRequest struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	TestIDs     *[]string `json:"test_ids"`
+	Browser     *string   `json:"browser"`
+	Headless    *bool     `json:"headless"`
+	Timeout     *int      `json:"timeout"`
+}
+
+// SuiteRunRequest overrides the suite's defaults for one suite run; nil fields use the suite's value
+type SuiteRunRequest struct {
+	Browser  *string `json:"browser"`
+	Headless *bool   `json:"headless"`
+	Timeout  *int    `json:"timeout"`
+}
+
+// CreateSuite validates input and stores a new suite in projectID, created by userID
+func (s *SuiteService) CreateSuite(ctx context.Context, projectID, userID string, req SuiteRequest) (*models.Suite, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := s.validateTests(ctx, projectID, req.TestIDs); err != nil {
+		return nil, err
+	}
+	if err := validateSuiteDefaults(req.Browser, req.Timeout); err != nil {
+		return nil, err
+	}
+
+	suite := &models.Suite{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		TestIDs:     req.TestIDs,
+		Browser:     req.Browser,
+		Headless:    req.Headless,
+		Timeout:     req.Timeout,
+		ProjectID:   projectID,
+		UserID:      userID,
+	}
+
+	if err := s.suiteRepo.Create(ctx, suite); err != nil {
+		return nil, errors.New("failed to create suite")
+	}
+
+	return suite, nil
+}
+
+// GetAllSuites returns every suite in projectID
+func (s *SuiteService) GetAllSuites(ctx context.Context, projectID string) ([]models.Suite, error) {
+	suites, err := s.suiteRepo.GetAll(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve suites")
+	}
+
+	return suites, nil
+}
+
+// GetSuiteByID returns a single suite in projectID
+func (s *SuiteService) GetSuiteByID(ctx context.Context, projectID, suiteID string) (*models.Suite, error) {
+	suite, err := s.suiteRepo.GetByID(ctx, suiteID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSuiteNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve suite")
+	}
+
+	return suite, nil
+}
+
+// UpdateSuite applies a partial update to a suite in projectID and returns the new version
+func (s *SuiteService) UpdateSuite(ctx context.Context, projectID, suiteID string, req SuiteUpdateRequest) (*models.Suite, error) {
+	suite, err := s.GetSuiteByID(ctx, projectID, suiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := bson.M{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.TestIDs != nil {
+		if err := s.validateTests(ctx, projectID, *req.TestIDs); err != nil {
+			return nil, err
+		}
+		updates["test_ids"] = *req.TestIDs
+	}
+	if req.Browser != nil {
+		suite.Browser = *req.Browser
+		updates["browser"] = *req.Browser
+	}
+	if req.Headless != nil {
+		updates["headless"] = *req.Headless
+	}
+	if req.Timeout != nil {
+		suite.Timeout = *req.Timeout
+		updates["timeout"] = *req.Timeout
+	}
+	if err := validateSuiteDefaults(suite.Browser, suite.Timeout); err != nil {
+		return nil, err
+	}
+
+	err = s.suiteRepo.Update(ctx, suiteID, projectID, updates)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSuiteNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to update suite")
+	}
+
+	return s.GetSuiteByID(ctx, projectID, suiteID)
+}
+
+// DeleteSuite removes a suite in projectID; its past suite runs are kept
+func (s *SuiteService) DeleteSuite(ctx context.Context, projectID, suiteID string) error {
+	err := s.suiteRepo.Delete(ctx, suiteID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrSuiteNotFound
+	}
+	if err != nil {
+		return errors.New("failed to delete suite")
+	}
+
+	return nil
+}
+
+// RunSuite launches one run per member test of a suite in projectID, on behalf of userID
+// Every member test must still exist; a member whose run cannot be enqueued
+// is recorded as an errored run and counted in the aggregate
+func (s *SuiteService) RunSuite(ctx context.Context, projectID, userID, suiteID string, req SuiteRunRequest) (*models.SuiteRun, error) {
+	suite, err := s.GetSuiteByID(ctx, projectID, suiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	runReq := RunRequest{Browser: suite.Browser, Headless: suite.Headless, Timeout: suite.Timeout}
+	if req.Browser != nil {
+		runReq.Browser = *req.Browser
+	}
+	if req.Headless != nil {
+		runReq.Headless = *req.Headless
+	}
+	if req.Timeout != nil {
+		runReq.Timeout = *req.Timeout
+	}
+	if err := runReq.normalize(); err != nil {
+		return nil, err
+	}
+
+	tests := make([]*models.Test, len(suite.TestIDs))
+	for i, testID := range suite.TestIDs {
+		test, err := s.testService.GetTestByID(ctx, projectID, testID)
+		if err != nil {
+			return nil, fmt.Errorf("suite member %s: %w", testID, err)
+		}
+		tests[i] = test
+	}
+
+	suiteRun := &models.SuiteRun{
+		SuiteID:   suite.ID,
+		ProjectID: projectID,
+		UserID:    userID,
+		SuiteName: suite.Name,
+		RunIDs:    []string{},
+		Status:    models.SuiteRunStatusRunning,
+	}
+	if err := s.suiteRunRepo.Create(ctx, suiteRun); err != nil {
+		return nil, errors.New("failed to create suite run")
+	}
+
+	for _, test := range tests {
+		run, err := s.runService.createRun(ctx, userID, test, runReq, suiteRun.ID)
+		if run == nil {
+			log.Printf("Failed to create run of test %s for suite run %s: %v", test.ID, suiteRun.ID, err)
+			continue
+		}
+		suiteRun.RunIDs = append(suiteRun.RunIDs, run.ID)
+	}
+
+	if len(suiteRun.RunIDs) == 0 {
+		s.suiteRunRepo.Finish(ctx, suiteRun.ID, models.SuiteRunStatusFailed, models.RunSummary{}, time.Now())
+		return nil, errors.New("failed to create suite run")
+	}
+
+	if err := s.suiteRunRepo.SetRunIDs(ctx, suiteRun.ID, suiteRun.RunIDs); err != nil {
+		return nil, errors.New("failed to create suite run")
+	}
+
+	return s.GetSuiteRun(ctx, projectID, suiteRun.ID)
+}
+
+// GetSuiteRun returns a suite run in projectID together with its member runs
+func (s *SuiteService) GetSuiteRun(ctx context.Context, projectID, suiteRunID string) (*models.SuiteRun, error) {
+	suiteRun, err := s.suiteRunRepo.GetByID(ctx, suiteRunID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSuiteRunNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve suite run")
+	}
+
+	if err := s.aggregate(ctx, suiteRun, true); err != nil {
+		return nil, err
+	}
+
+	return suiteRun, nil
+}
+
+// ListSuiteRuns returns the run history of a suite in projectID
+func (s *SuiteService) ListSuiteRuns(ctx context.Context, projectID, suiteID string) ([]models.SuiteRun, error) {
+	if _, err := s.GetSuiteByID(ctx, projectID, suiteID); err != nil {
+		return nil, err
+	}
+
+	suiteRuns, err := s.suiteRunRepo.ListBySuite(ctx, suiteID, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve suite runs")
+	}
+
+	for i := range suiteRuns {
+		if err := s.aggregate(ctx, &suiteRuns[i], false); err != nil {
+			return nil, err
+		}
+	}
+
+	return suiteRuns, nil
+}
+
+// CancelSuiteRun cancels every member run of a suite run in projectID that has not finished
+func (s *SuiteService) CancelSuiteRun(ctx context.Context, projectID, suiteRunID string) (*models.SuiteRun, error) {
+	suiteRun, err := s.GetSuiteRun(ctx, projectID, suiteRunID)
+	if err != nil {
+		return nil, err
+	}
+	if suiteRun.Status != models.SuiteRunStatusRunning {
+		return nil, ErrInvalidRunTransition
+	}
+
+	for _, run := range suiteRun.Runs {
+		if run.IsFinished() {
+			continue
+		}
+		// A member may finish between the read and the cancel; that is fine
+		_, err := s.runService.CancelRun(ctx, projectID, run.ID)
+		if err != nil && !errors.Is(err, ErrInvalidRunTransition) {
+			return nil, err
+		}
+	}
+
+	return s.GetSuiteRun(ctx, projectID, suiteRunID)
+}
+
+// aggregate computes the status and summary of a suite run that is still running
+// from its member runs, and stores them once every member run has finished
+// withRuns also attaches the member runs, in suite order
+func (s *SuiteService) aggregate(ctx context.Context, suiteRun *models.SuiteRun, withRuns bool) error {
+	if suiteRun.Status != models.SuiteRunStatusRunning && !withRuns {
+		return nil
+	}
+
+	runs, err := s.runRepo.ListBySuiteRun(ctx, suiteRun.ID, suiteRun.ProjectID)
+	if err != nil {
+		return errors.New("failed to retrieve suite run")
+	}
+
+	byID := make(map[string]models.TestRun, len(runs))
+	for _, run := range runs {
+		byID[run.ID] = run
+	}
+
+	ordered := make([]models.TestRun, 0, len(runs))
+	for _, runID := range suiteRun.RunIDs {
+		if run, ok := byID[runID]; ok {
+			ordered = append(ordered, run)
+		}
+	}
+	if withRuns {
+		suiteRun.Runs = ordered
+	}
+
+	if suiteRun.Status != models.SuiteRunStatusRunning {
+		return nil
+	}
+
+	// Until RunSuite has recorded the member runs, more may still be created
+	if len(suiteRun.RunIDs) == 0 {
+		return nil
+	}
+
+	summary := models.RunSummary{}
+	var finishedAt time.Time
+	for _, run := range ordered {
+		summary.Add(run.Status)
+		if run.FinishedAt != nil && run.FinishedAt.After(finishedAt) {
+			finishedAt = *run.FinishedAt
+		}
+	}
+	suiteRun.Summary = summary
+	suiteRun.Status = summary.Status()
+
+	if suiteRun.Status != models.SuiteRunStatusRunning {
+		suiteRun.FinishedAt = &finishedAt
+		if err := s.suiteRunRepo.Finish(ctx, suiteRun.ID, suiteRun.Status, summary, finishedAt); err != nil {
+			log.Printf("Failed to store the status of suite run %s: %v", suiteRun.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTests checks that a suite lists between 1 and maxSuiteTests of the tests in projectID
+func (s *SuiteService) validateTests(ctx context.Context, projectID string, testIDs []string) error {
+	if len(testIDs) == 0 {
+		return errors.New("a suite needs at least one test")
+	}
+	if len(testIDs) > maxSuiteTests {
+		return fmt.Errorf("a suite can have at most %d tests", maxSuiteTests)
+	}
+
+	for _, testID := range testIDs {
+		if _, err := s.testService.GetTestByID(ctx, projectID, testID); err != nil {
+			return fmt.Errorf("suite member %s: %w", testID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateSuiteDefaults checks a suite's default browser and timeout; empty values use the run defaults
+func validateSuiteDefaults(browser string, timeout int) error {
+	req := RunRequest{Browser: browser, Timeout: timeout}
+	return req.normalize()
+}
@@ -1,34 +1,185 @@
 package services
 
+/**
+ * Test Service
+ *
+ * Purpose: Handle business logic for test scripts
+ *
+ * Operations:
+ * - CreateTest: Validate and store a new test in the active project
+ * - GetAllTests / GetTestByID: Read the project's tests
+ * - UpdateTest / DeleteTest: Modify the project's tests
+ *
+ * All operations take the active project's ID and never touch tests that
+ * belong to another project. Changes are recorded in the audit log.
+ */
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// ErrTestNotFound is returned when a test does not exist or is not in the caller's project
+var ErrTestNotFound = errors.New("test not found")
+
 type TestService struct {
-	// TODO: Add repository dependencies
+	testRepo *repository.TestRepository
+	audit    *AuditService
 }
 
-func NewTestService() *TestService {
-	return &TestService{}
+// NewTestService creates a new test service instance
+func NewTestService(testRepo *repository.TestRepository, audit *AuditService) *TestService {
+	return &TestService{
+		testRepo: testRepo,
+		audit:    audit,
+	}
 }
 
-func (s *TestService) CreateTest(name, script string, userID string) error {
-	// TODO: Implement test creation logic
-	return nil
+// TestRequest represents the data needed to create a test
+type TestRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Script      string `json:"script"`
 }
 
-func (s *TestService) GetAllTests(userID string) ([]interface{}, error) {
-	// TODO: Implement get all tests logic
-	return nil, nil
+// TestUpdateRequest represents a partial update; nil fields are left unchanged
+type TestUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Script      *string `json:"script"`
 }
 
-func (s *TestService) GetTestByID(testID string) (interface{}, error) {
-	// TODO: Implement get test by ID logic
-	return nil, nil
+// CreateTest validates input and stores a new test in projectID, created by userID
+func (s *TestService) CreateTest(ctx context.Context, projectID, userID string, req TestRequest) (*models.Test, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if strings.TrimSpace(req.Script) == "" {
+		return nil, errors.New("script is required")
+	}
+
+	test := &models.Test{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Script:      req.Script,
+		ProjectID:   projectID,
+		UserID:      userID,
+	}
+
+	if err := s.testRepo.Create(ctx, test); err != nil {
+		return nil, errors.New("failed to create test")
+	}
+
+	s.recordTest(ctx, models.AuditTestCreated, test.ID, projectID, nil, test)
+
+	return test, nil
 }
 
-func (s *TestService) UpdateTest(testID, name, script string) error {
-	// TODO: Implement update test logic
-	return nil
+// GetAllTests returns every test in projectID
+func (s *TestService) GetAllTests(ctx context.Context, projectID string) ([]models.Test, error) {
+	tests, err := s.testRepo.GetAll(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve tests")
+	}
+
+	return tests, nil
 }
 
-func (s *TestService) DeleteTest(testID string) error {
-	// TODO: Implement delete test logic
+// GetTestByID returns a single test in projectID
+func (s *TestService) GetTestByID(ctx context.Context, projectID, testID string) (*models.Test, error) {
+	test, err := s.testRepo.GetByID(ctx, testID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTestNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve test")
+	}
+
+	return test, nil
+}
+
+// UpdateTest applies a partial update to a test in projectID and returns the new version
+func (s *TestService) UpdateTest(ctx context.Context, projectID, testID string, req TestUpdateRequest) (*models.Test, error) {
+	updates := bson.M{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Script != nil {
+		if strings.TrimSpace(*req.Script) == "" {
+			return nil, errors.New("script cannot be empty")
+		}
+		updates["script"] = *req.Script
+	}
+
+	before, err := s.GetTestByID(ctx, projectID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.testRepo.Update(ctx, testID, projectID, updates)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTestNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to update test")
+	}
+
+	test, err := s.GetTestByID(ctx, projectID, testID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordTest(ctx, models.AuditTestUpdated, testID, projectID, before, test)
+
+	return test, nil
+}
+
+// DeleteTest removes a test in projectID
+func (s *TestService) DeleteTest(ctx context.Context, projectID, testID string) error {
+	before, err := s.GetTestByID(ctx, projectID, testID)
+	if err != nil {
+		return err
+	}
+
+	err = s.testRepo.Delete(ctx, testID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrTestNotFound
+	}
+	if err != nil {
+		return errors.New("failed to delete test")
+	}
+
+	s.recordTest(ctx, models.AuditTestDeleted, testID, projectID, before, nil)
+
 	return nil
 }
+
+// recordTest audits a change to a test; before or after is nil for creations and deletions
+func (s *TestService) recordTest(ctx context.Context, action, testID, projectID string, before, after *models.Test) {
+	event := models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetTest,
+		TargetID:   testID,
+		ProjectID:  projectID,
+	}
+	if before != nil {
+		event.Before = AuditSnapshot(before)
+	}
+	if after != nil {
+		event.After = AuditSnapshot(after)
+	}
+	s.audit.Record(ctx, event)
+}
@@ -0,0 +1,339 @@
+package services
+
+/**
+ * Token Service
+ *
+ * Purpose: Issue, refresh and revoke login sessions
+ *
+ * Operations:
+ * - IssueTokens: Start a login session with an access token and a refresh token
+ * - Refresh: Exchange a refresh token for a new token pair
+ * - Logout: End the session of an access token
+ * - LogoutAll: End every session of a user
+ * - ListSessions / RevokeSession: Show and end a user's sessions
+ * - IsSessionActive: Check an access token's session (AuthMiddleware)
+ * - IsRevoked: Check whether an access token has been revoked
+ *
+ * A login session is a family of refresh tokens. Every refresh uses up the
+ * presented token and issues the next one of the family. If a used token is
+ * presented again it was stolen (or replayed), so the whole family is revoked
+ * and every access token issued for it stops working.
+ *
+ * Each session also has a record with its login method, device and where it
+ * was last seen. AuthMiddleware checks it on every request through a cache,
+ * so a session revoked on another replica stops working within the cache time.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, used, revoked or expired refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	// ErrAccountDisabled is returned when issuing tokens to a disabled user
+	ErrAccountDisabled = errors.New("this account has been disabled")
+
+	// ErrSessionNotFound is returned for unknown sessions and sessions of other users
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type TokenService struct {
+	tokenRepo   *repository.TokenRepository
+	sessionRepo *repository.SessionRepository
+	userRepo    *repository.UserRepository
+	jwtService  *JWTService
+	refreshTTL  time.Duration
+	sessions    *ttlCache[*models.Session]
+}
+
+// NewTokenService creates a new token service instance
+// Refresh tokens are valid for refreshTTL after they are issued; session
+// records are cached for cacheTTL.
+func NewTokenService(tokenRepo *repository.TokenRepository, sessionRepo *repository.SessionRepository, userRepo *repository.UserRepository, jwtService *JWTService, refreshTTL, cacheTTL time.Duration) *TokenService {
+	return &TokenService{
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		jwtService:  jwtService,
+		refreshTTL:  refreshTTL,
+		sessions:    newTTLCache[*models.Session](cacheTTL),
+	}
+}
+
+// TokenPair is returned to the client after login and on every refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // seconds until the access token expires
+}
+
+// IssueTokens starts a new login session for user, who logged in with method
+// The device and IP of the session are taken from the request (see WithAuditActor).
+func (s *TokenService) IssueTokens(ctx context.Context, user *models.User, method string) (*TokenPair, error) {
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	now := time.Now()
+	client := AuditActorFromContext(ctx)
+	session := &models.Session{
+		ID:         primitive.NewObjectID().Hex(),
+		UserID:     user.ID,
+		Method:     method,
+		Device:     utils.DescribeUserAgent(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: &now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return s.issue(ctx, user, session.ID)
+}
+
+// Refresh uses up a refresh token and returns a new token pair for the same session
+// The user is reloaded, so role changes show up in the new access token.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	id := hashToken(refreshToken)
+
+	token, err := s.tokenRepo.UseRefreshToken(ctx, id, now)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		s.detectReuse(ctx, id, now)
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+
+	tokens, err := s.issue(ctx, user, token.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.Extend(ctx, token.FamilyID, user.ID, now, now.Add(s.refreshTTL)); err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the session of an access token, including the access token itself
+func (s *TokenService) Logout(ctx context.Context, claims *TokenClaims) error {
+	now := time.Now()
+
+	if claims.SessionID != "" {
+		if err := s.revokeFamily(ctx, claims.SessionID, now); err != nil {
+			return errors.New("failed to log out")
+		}
+	}
+
+	// Tokens issued before token IDs were introduced cannot be revoked individually
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.tokenRepo.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return errors.New("failed to log out")
+		}
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session of a user, e.g. after their password was reset
+func (s *TokenService) LogoutAll(ctx context.Context, userID string) error {
+	now := time.Now()
+
+	families, err := s.tokenRepo.ActiveFamilies(ctx, userID, now)
+	if err != nil {
+		return errors.New("failed to log out")
+	}
+
+	for _, familyID := range families {
+		if err := s.revokeFamily(ctx, familyID, now); err != nil {
+			return errors.New("failed to log out")
+		}
+	}
+
+	return nil
+}
+
+// ListSessions returns the login sessions of userID that can still be used, newest first
+func (s *TokenService) ListSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActive(ctx, userID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to retrieve sessions")
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends login session sessionID of userID
+func (s *TokenService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	now := time.Now()
+
+	session, err := s.sessionRepo.Get(ctx, sessionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return errors.New("failed to revoke session")
+	}
+	if session.UserID != userID || !session.Active(now) {
+		return ErrSessionNotFound
+	}
+
+	if err := s.revokeFamily(ctx, sessionID, now); err != nil {
+		return errors.New("failed to revoke session")
+	}
+
+	return nil
+}
+
+// IsSessionActive reports whether the login session of an access token can still be used
+// It also records that the session was seen, at most once per cache time.
+// Tokens without a recorded session (issued before sessions were recorded)
+// fall back to IsRevoked.
+func (s *TokenService) IsSessionActive(ctx context.Context, claims *TokenClaims) (bool, error) {
+	if claims.SessionID == "" {
+		revoked, err := s.IsRevoked(ctx, claims)
+		return !revoked, err
+	}
+
+	session, ok := s.sessions.Get(claims.SessionID)
+	if !ok {
+		var err error
+		session, err = s.sessionRepo.Touch(ctx, claims.SessionID, AuditActorFromContext(ctx).IP, time.Now())
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			session, err = nil, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		s.sessions.Set(claims.SessionID, session)
+	}
+
+	if session == nil {
+		revoked, err := s.IsRevoked(ctx, claims)
+		return !revoked, err
+	}
+
+	return session.Active(time.Now()), nil
+}
+
+// IsRevoked reports whether an access token or its session has been revoked
+func (s *TokenService) IsRevoked(ctx context.Context, claims *TokenClaims) (bool, error) {
+	var ids []string
+	if claims.ID != "" {
+		ids = append(ids, claims.ID)
+	}
+	if claims.SessionID != "" {
+		ids = append(ids, claims.SessionID)
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	return s.tokenRepo.IsRevoked(ctx, ids...)
+}
+
+// issue creates an access token and the next refresh token of session familyID
+// Disabled users get no tokens, so they can neither log in nor refresh.
+func (s *TokenService) issue(ctx context.Context, user *models.User, familyID string) (*TokenPair, error) {
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	accessToken, err := s.jwtService.GenerateToken(user.ID, user.Email, user.Username, user.Role, familyID)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	err = s.tokenRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		ID:        hashToken(refreshToken),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtService.TTL().Seconds()),
+	}, nil
+}
+
+// detectReuse revokes the session of a refresh token that was already used
+func (s *TokenService) detectReuse(ctx context.Context, id string, now time.Time) {
+	token, err := s.tokenRepo.FindRefreshToken(ctx, id)
+	if err != nil || token.UsedAt == nil || token.RevokedAt != nil {
+		return
+	}
+
+	s.revokeFamily(ctx, token.FamilyID, now)
+}
+
+// revokeFamily revokes every refresh token of a session and every access token issued for it
+func (s *TokenService) revokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	if err := s.tokenRepo.RevokeFamily(ctx, familyID, now); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Revoke(ctx, familyID, now); err != nil {
+		return err
+	}
+	s.sessions.Delete(familyID)
+
+	// Access tokens of the session expire at the latest one TTL from now
+	return s.tokenRepo.Revoke(ctx, familyID, now.Add(s.jwtService.TTL()))
+}
+
+// generateRefreshToken returns a random, URL-safe refresh token
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the hex SHA-256 under which a token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
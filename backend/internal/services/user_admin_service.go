@@ -0,0 +1,276 @@
+package services
+
+/**
+ * User Admin Service
+ *
+ * Purpose: Let admins manage user accounts
+ *
+ * Operations:
+ * - ListUsers / GetUser: Search and page through users
+ * - SetDisabled: Disable or re-enable an account
+ * - ForcePasswordReset: Clear a password and email a reset link
+ * - DeleteUser: Delete an account with its sessions, tokens and memberships
+ * - IsActive: Whether a user may still use their tokens (AuthMiddleware)
+ *
+ * Disabling ends every session at once; personal access tokens and access
+ * tokens without a session are refused by AuthMiddleware. Its lookups are
+ * cached like roles, so on other replicas they stop working within the cache
+ * time. Admins cannot disable, reset or delete their own account. Roles are
+ * changed with RoleService.
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// ErrOwnAccount is returned when an admin tries to disable, reset or delete their own account
+var ErrOwnAccount = errors.New("you cannot do this to your own account")
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+// UserListQuery selects a page of users
+type UserListQuery struct {
+	Search   string // part of the username or email
+	Role     string
+	Disabled *bool
+	Page     int // from 1
+	Limit    int // default 50, at most 200
+}
+
+// UserPage is one page of ListUsers
+type UserPage struct {
+	Users []models.User `json:"users"`
+	Total int64         `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+}
+
+type UserAdminService struct {
+	userRepo       *repository.UserRepository
+	patRepo        *repository.PersonalAccessTokenRepository
+	tokenService   *TokenService
+	accountService *AccountService
+	projectService *ProjectService
+	audit          *AuditService
+	active         *ttlCache[bool]
+}
+
+// NewUserAdminService creates a new user admin service instance that caches account status for cacheTTL
+func NewUserAdminService(userRepo *repository.UserRepository, patRepo *repository.PersonalAccessTokenRepository, tokenService *TokenService, accountService *AccountService, projectService *ProjectService, audit *AuditService, cacheTTL time.Duration) *UserAdminService {
+	return &UserAdminService{
+		userRepo:       userRepo,
+		patRepo:        patRepo,
+		tokenService:   tokenService,
+		accountService: accountService,
+		projectService: projectService,
+		audit:          audit,
+		active:         newTTLCache[bool](cacheTTL),
+	}
+}
+
+// ListUsers returns a page of matching users, newest first
+func (s *UserAdminService) ListUsers(ctx context.Context, query UserListQuery) (*UserPage, error) {
+	if query.Role != "" && !models.IsValidRole(query.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+
+	filter := repository.UserFilter{Search: query.Search, Role: query.Role, Disabled: query.Disabled}
+	users, total, err := s.userRepo.ListUsers(ctx, filter, int64((page-1)*limit), int64(limit))
+	if err != nil {
+		return nil, errors.New("failed to retrieve users")
+	}
+
+	return &UserPage{Users: users, Total: total, Page: page, Limit: limit}, nil
+}
+
+// GetUser returns a single user
+func (s *UserAdminService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve user")
+	}
+
+	return user, nil
+}
+
+// SetDisabled disables or re-enables userID on behalf of actorID
+// Disabling ends every session of the user.
+func (s *UserAdminService) SetDisabled(ctx context.Context, actorID, userID string, disabled bool) (*models.User, error) {
+	if actorID == userID {
+		return nil, ErrOwnAccount
+	}
+
+	before, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.SetDisabled(ctx, userID, disabled)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to update user")
+	}
+	s.active.Delete(userID)
+
+	if disabled {
+		if err := s.tokenService.LogoutAll(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := models.AuditUserEnabled
+	if disabled {
+		action = models.AuditUserDisabled
+	}
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]interface{}{"disabled": before.Disabled},
+		After:      map[string]interface{}{"disabled": user.Disabled},
+	})
+
+	return user, nil
+}
+
+// ForcePasswordReset clears userID's password, ends their sessions and emails them a reset link
+// Until they use the link they can only log in through an OpenID Connect provider.
+func (s *UserAdminService) ForcePasswordReset(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return ErrOwnAccount
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetPasswordByID(ctx, userID, ""); err != nil {
+		return errors.New("failed to reset password")
+	}
+	if err := s.tokenService.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditPasswordCleared,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]interface{}{"has_password": user.Password != ""},
+		After:      map[string]interface{}{"has_password": false},
+	})
+
+	return s.accountService.SendForcedPasswordReset(ctx, user)
+}
+
+// DeleteUser deletes userID on behalf of actorID
+// Their sessions, personal access tokens and memberships go with them; the
+// tests, runs and other data in their projects stay. Users who are the last
+// owner of an organization or admin of a project cannot be deleted
+// (ErrLastAdmin) until someone else is. The account is disabled before
+// anything is removed, so a deletion that fails halfway leaves a disabled
+// user whose deletion can simply be retried.
+func (s *UserAdminService) DeleteUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return ErrOwnAccount
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.projectService.CheckRemoveUser(ctx, userID); err != nil {
+		return err
+	}
+
+	err = s.userRepo.SetDisabled(ctx, userID, true)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to delete user")
+	}
+	s.active.Delete(userID)
+
+	if err := s.tokenService.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.patRepo.DeleteByUser(ctx, userID); err != nil {
+		return errors.New("failed to delete user")
+	}
+	if err := s.projectService.RemoveUser(ctx, userID); err != nil {
+		return err
+	}
+
+	err = s.userRepo.DeleteUser(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return errors.New("failed to delete user")
+	}
+	s.active.Delete(userID)
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditUserDeleted,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     AuditSnapshot(user),
+	})
+
+	return nil
+}
+
+// IsActive reports whether userID exists and is not disabled
+func (s *UserAdminService) IsActive(ctx context.Context, userID string) (bool, error) {
+	if active, ok := s.active.Get(userID); ok {
+		return active, nil
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		s.active.Set(userID, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	active := !user.Disabled
+	s.active.Set(userID, active)
+
+	return active, nil
+}
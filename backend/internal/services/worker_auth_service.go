@@ -0,0 +1,298 @@
+package services
+
+/**
+ * Worker Auth Service
+ *
+ * Purpose: Registration and credentials of runners (workers)
+ *
+ * Operations:
+ * - CreateRegistrationToken: Issue a one-time token for a runner; the token is returned only here
+ * - ListRegistrationTokens / RevokeRegistrationToken: Manage a project's tokens
+ * - RegisterWorker: Exchange a registration token for a worker and its credential
+ * - Authenticate: Resolve a presented credential to its worker
+ * - RevokeCredential: Stop a worker's credential from working
+ *
+ * A registration token registers one runner in the project it was issued
+ * for. The runner gets a long-lived credential bound to its worker ID; the
+ * worker-only routes accept nothing else, and user tokens are never accepted
+ * there. Only SHA-256 hashes of tokens and credentials are stored.
+ * Tokens and credentials stop working once the user who issued the token
+ * is disabled, deleted or no longer a member of the project.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// Registration token lifetime bounds, in hours
+const (
+	defaultRegistrationTokenHours = 24
+	maxRegistrationTokenHours     = 30 * 24
+)
+
+var (
+	// ErrInvalidRegistrationToken is returned for unknown, used or expired registration tokens
+	ErrInvalidRegistrationToken = errors.New("invalid, used or expired registration token")
+
+	// ErrInvalidWorkerCredential is returned for unknown or revoked worker credentials
+	ErrInvalidWorkerCredential = errors.New("invalid or revoked worker credential")
+
+	// ErrRegistrationTokenNotFound is returned when a token does not exist or belongs to another project
+	ErrRegistrationTokenNotFound = errors.New("registration token not found")
+)
+
+// RegistrationTokenRequest represents an admin's request for a registration token
+type RegistrationTokenRequest struct {
+	Name           string `json:"name"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+// RegisterRequest represents the data a runner sends when it registers
+type RegisterRequest struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Capabilities []string `json:"capabilities"`
+}
+
+type WorkerAuthService struct {
+	tokenRepo      *repository.WorkerRegistrationTokenRepository
+	workerRepo     *repository.WorkerRepository
+	projectService *ProjectService
+	userAdmin      *UserAdminService
+	audit          *AuditService
+}
+
+// NewWorkerAuthService creates a new worker auth service instance
+func NewWorkerAuthService(tokenRepo *repository.WorkerRegistrationTokenRepository, workerRepo *repository.WorkerRepository, projectService *ProjectService, userAdmin *UserAdminService, audit *AuditService) *WorkerAuthService {
+	return &WorkerAuthService{
+		tokenRepo:      tokenRepo,
+		workerRepo:     workerRepo,
+		projectService: projectService,
+		userAdmin:      userAdmin,
+		audit:          audit,
+	}
+}
+
+// CreateRegistrationToken issues a token for registering one runner in projectID on behalf of userID
+// It returns the token with its plaintext value.
+func (s *WorkerAuthService) CreateRegistrationToken(ctx context.Context, projectID, userID string, req RegistrationTokenRequest) (*models.WorkerRegistrationToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if len(name) > 100 {
+		return nil, "", invalidRequest("name must be at most 100 characters")
+	}
+
+	hours := req.ExpiresInHours
+	if hours == 0 {
+		hours = defaultRegistrationTokenHours
+	}
+	if hours < 1 || hours > maxRegistrationTokenHours {
+		return nil, "", invalidRequest("expires_in_hours must be between 1 and 720")
+	}
+
+	plaintext, err := generateWorkerSecret(models.WorkerRegistrationTokenPrefix)
+	if err != nil {
+		return nil, "", errors.New("failed to create registration token")
+	}
+
+	token := &models.WorkerRegistrationToken{
+		ProjectID: projectID,
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(plaintext),
+		Hint:      plaintext[len(plaintext)-4:],
+		ExpiresAt: time.Now().Add(time.Duration(hours) * time.Hour),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, "", errors.New("failed to create registration token")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditWorkerTokenCreated,
+		TargetType: models.AuditTargetWorker,
+		ProjectID:  projectID,
+		After:      AuditSnapshot(token),
+	})
+
+	return token, plaintext, nil
+}
+
+// ListRegistrationTokens returns the unexpired registration tokens of projectID, without their values
+func (s *WorkerAuthService) ListRegistrationTokens(ctx context.Context, projectID string) ([]models.WorkerRegistrationToken, error) {
+	tokens, err := s.tokenRepo.ListByProject(ctx, projectID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to retrieve registration tokens")
+	}
+
+	return tokens, nil
+}
+
+// RevokeRegistrationToken deletes a registration token of projectID
+// Workers that already registered with it keep their credential.
+func (s *WorkerAuthService) RevokeRegistrationToken(ctx context.Context, projectID, tokenID string) error {
+	err := s.tokenRepo.Delete(ctx, tokenID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRegistrationTokenNotFound
+	}
+	if err != nil {
+		return errors.New("failed to revoke registration token")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditWorkerTokenRevoked,
+		TargetType: models.AuditTargetWorker,
+		ProjectID:  projectID,
+		Details:    map[string]interface{}{"registration_token_id": tokenID},
+	})
+
+	return nil
+}
+
+// RegisterWorker uses up a registration token and registers a new idle worker in its project
+// It returns the worker with its credential, which is shown only here.
+func (s *WorkerAuthService) RegisterWorker(ctx context.Context, registrationToken string, req RegisterRequest) (*models.Worker, string, error) {
+	if !strings.HasPrefix(registrationToken, models.WorkerRegistrationTokenPrefix) {
+		return nil, "", ErrInvalidRegistrationToken
+	}
+	// Validate before the token is used up
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, "", invalidRequest("name is required")
+	}
+	if req.Capabilities == nil {
+		req.Capabilities = []string{}
+	}
+
+	token, err := s.tokenRepo.Use(ctx, hashToken(registrationToken), time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, "", ErrInvalidRegistrationToken
+	}
+	if err != nil {
+		return nil, "", errors.New("failed to register worker")
+	}
+	if err := s.checkIssuer(ctx, token.UserID, token.ProjectID); err != nil {
+		if errors.Is(err, ErrInvalidWorkerCredential) {
+			return nil, "", ErrInvalidRegistrationToken
+		}
+		return nil, "", errors.New("failed to register worker")
+	}
+
+	credential, err := generateWorkerSecret(models.WorkerCredentialPrefix)
+	if err != nil {
+		return nil, "", errors.New("failed to register worker")
+	}
+
+	worker := &models.Worker{
+		Name:           strings.TrimSpace(req.Name),
+		Version:        req.Version,
+		Capabilities:   req.Capabilities,
+		ProjectID:      token.ProjectID,
+		UserID:         token.UserID,
+		Status:         models.WorkerStatusIdle,
+		CredentialHash: hashToken(credential),
+		CredentialHint: credential[len(credential)-4:],
+	}
+	if err := s.workerRepo.Create(ctx, worker); err != nil {
+		return nil, "", errors.New("failed to register worker")
+	}
+	if err := s.tokenRepo.SetWorker(ctx, token.ID, worker.ID); err != nil {
+		return nil, "", errors.New("failed to register worker")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditWorkerRegistered,
+		TargetType: models.AuditTargetWorker,
+		TargetID:   worker.ID,
+		ProjectID:  worker.ProjectID,
+		After:      AuditSnapshot(worker),
+		Details:    map[string]interface{}{"registration_token_id": token.ID, "issued_by": token.UserID},
+	})
+
+	return worker, credential, nil
+}
+
+// Authenticate returns the worker holding a presented credential
+// Credentials whose issuing user has lost access to the project are refused.
+func (s *WorkerAuthService) Authenticate(ctx context.Context, credential string) (*models.Worker, error) {
+	if !IsWorkerCredential(credential) {
+		return nil, ErrInvalidWorkerCredential
+	}
+
+	worker, err := s.workerRepo.GetByCredentialHash(ctx, hashToken(credential))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidWorkerCredential
+	}
+	if err != nil {
+		return nil, errors.New("failed to verify worker credential")
+	}
+	if err := s.checkIssuer(ctx, worker.UserID, worker.ProjectID); err != nil {
+		return nil, err
+	}
+
+	return worker, nil
+}
+
+// checkIssuer returns ErrInvalidWorkerCredential unless userID is active and still a member of projectID
+// Both lookups are cached, so this is cheap on every runner request.
+func (s *WorkerAuthService) checkIssuer(ctx context.Context, userID, projectID string) error {
+	active, err := s.userAdmin.IsActive(ctx, userID)
+	if err != nil {
+		return errors.New("failed to verify worker credential")
+	}
+	if !active {
+		return ErrInvalidWorkerCredential
+	}
+
+	_, err = s.projectService.ResolveMember(ctx, userID, projectID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return ErrInvalidWorkerCredential
+	}
+	if err != nil {
+		return errors.New("failed to verify worker credential")
+	}
+
+	return nil
+}
+
+// RevokeCredential stops the credential of a worker in projectID from working
+// The runner has to register again with a new registration token.
+func (s *WorkerAuthService) RevokeCredential(ctx context.Context, projectID, workerID string) error {
+	err := s.workerRepo.RevokeCredential(ctx, workerID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrWorkerNotFound
+	}
+	if err != nil {
+		return errors.New("failed to revoke worker credential")
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:     models.AuditWorkerCredentialRevoked,
+		TargetType: models.AuditTargetWorker,
+		TargetID:   workerID,
+		ProjectID:  projectID,
+	})
+
+	return nil
+}
+
+// IsWorkerCredential reports whether a bearer token is a worker credential
+func IsWorkerCredential(bearer string) bool {
+	return strings.HasPrefix(bearer, models.WorkerCredentialPrefix)
+}
+
+// generateWorkerSecret returns a new random registration token or credential with prefix
+func generateWorkerSecret(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,91 @@
+package services
+
+/**
+ * Worker Reaper
+ *
+ * Purpose: Detect runners that stopped sending heartbeats
+ *
+ * Periodically marks workers offline once they have been silent for
+ * longer than the configured threshold, and puts the run they were
+ * executing back in the queue. Marking a worker offline is a conditional
+ * update, so when several backend replicas run a reaper only one of them
+ * re-queues any given run.
+ */
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/repository"
+)
+
+type WorkerReaper struct {
+	workerRepo   *repository.WorkerRepository
+	runService   *RunService
+	offlineAfter time.Duration
+}
+
+// NewWorkerReaper creates a reaper that marks workers offline after offlineAfter of silence
+func NewWorkerReaper(workerRepo *repository.WorkerRepository, runService *RunService, offlineAfter time.Duration) *WorkerReaper {
+	return &WorkerReaper{
+		workerRepo:   workerRepo,
+		runService:   runService,
+		offlineAfter: offlineAfter,
+	}
+}
+
+// Start runs the reaper in the background until ctx is cancelled
+func (r *WorkerReaper) Start(ctx context.Context) {
+	// Check a few times per threshold so a dead worker is noticed promptly
+	ticker := time.NewTicker(r.offlineAfter / 3)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.reap(ctx)
+			}
+		}
+	}()
+}
+
+// reap marks every silent worker offline and re-queues its current run
+func (r *WorkerReaper) reap(ctx context.Context) {
+	cutoff := time.Now().Add(-r.offlineAfter)
+
+	workers, err := r.workerRepo.GetSilentSince(ctx, cutoff)
+	if err != nil {
+		log.Printf("Worker reaper: failed to list silent workers: %v", err)
+		return
+	}
+
+	for _, silent := range workers {
+		worker, err := r.workerRepo.MarkOffline(ctx, silent.ID, cutoff)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Pinged in the meantime, or another replica got there first
+			continue
+		}
+		if err != nil {
+			log.Printf("Worker reaper: failed to mark worker %s offline: %v", silent.ID, err)
+			continue
+		}
+
+		log.Printf("Worker reaper: worker %s (%s) marked offline", worker.ID, worker.Name)
+
+		if worker.CurrentJob == "" {
+			continue
+		}
+		if err := r.runService.RequeueRun(ctx, worker.CurrentJob, worker.ID); err != nil {
+			log.Printf("Worker reaper: failed to re-queue run %s: %v", worker.CurrentJob, err)
+			continue
+		}
+		log.Printf("Worker reaper: re-queued run %s from worker %s", worker.CurrentJob, worker.ID)
+	}
+}
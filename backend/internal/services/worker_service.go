@@ -1,19 +1,297 @@
 package services
 
+/**
+ * Worker Service
+ *
+ * Purpose: Handle business logic for runners (workers) and the job queue
+ *
+ * Operations:
+ * - EnqueueJob / LeaseJob: Hand runs to the runners through the job queue
+ * - AckJob / RequeueJob / RemoveJob: Finish, give back or drop a queued job
+ * - Heartbeat / ReportStatus: Keep track of which runners are alive and busy;
+ *   a heartbeat also renews the lease on the job of the run being executed
+ * - GetWorkers / GetWorkerStatus: List registered runners
+ *
+ * Every project has its own job queue, and a runner is registered in one
+ * project and only leases that project's jobs. Runners register and
+ * authenticate through WorkerAuthService.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+	"backend/internal/queue"
+	"backend/internal/repository"
+)
+
+// TestJobsQueue prefixes the per-project queues the Python runners consume test jobs from
+const TestJobsQueue = "test_jobs"
+
+// MaxLeaseWait caps how long a lease request may block waiting for a job
+// It must stay below the write timeout of any proxy in front of the backend
+const MaxLeaseWait = 30 * time.Second
+
+var (
+	// ErrEnqueueFailed is returned when a job could not be handed to the queue
+	ErrEnqueueFailed = errors.New("failed to enqueue job")
+
+	// ErrWorkerNotFound is returned when a worker does not exist or is not in the caller's project
+	ErrWorkerNotFound = errors.New("worker not found")
+
+	// ErrJobNotLeased is returned when a worker acks or gives back a job it does not hold the lease on
+	ErrJobNotLeased = queue.ErrJobNotLeased
+
+	// ErrInvalidRequest matches (with errors.Is) the validation errors of the
+	// runner-facing services, so handlers can tell them from server faults
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// invalidRequestError is a validation error; its message is shown to the caller as is
+type invalidRequestError struct {
+	message string
+}
+
+func (e *invalidRequestError) Error() string {
+	return e.message
+}
+
+func (e *invalidRequestError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// invalidRequest returns a validation error matching ErrInvalidRequest
+func invalidRequest(message string) error {
+	return &invalidRequestError{message: message}
+}
+
+// TestJob is the payload the runners expect (see runner/src/job_parser.py)
+type TestJob struct {
+	RunID     string `json:"run_id"`
+	TestID    string `json:"test_id"`
+	ProjectID string `json:"project_id"`
+	UserID    string `json:"user_id"`
+	Script    string `json:"script"`
+	Browser   string `json:"browser"`
+	Headless  bool   `json:"headless"`
+	Timeout   int    `json:"timeout"`
+}
+
+// LeasedJob is a job handed to a worker over HTTP
+type LeasedJob struct {
+	JobID    string  `json:"job_id"`
+	Attempts int     `json:"attempts"`
+	Job      TestJob `json:"job"`
+}
+
 type WorkerService struct {
-	// TODO: Add queue dependencies
+	queues     *queue.Pool
+	workerRepo *repository.WorkerRepository
+	runRepo    *repository.TestRunRepository
 }
 
-func NewWorkerService() *WorkerService {
-	return &WorkerService{}
+func NewWorkerService(queues *queue.Pool, workerRepo *repository.WorkerRepository, runRepo *repository.TestRunRepository) *WorkerService {
+	return &WorkerService{
+		queues:     queues,
+		workerRepo: workerRepo,
+		runRepo:    runRepo,
+	}
+}
+
+// projectQueue returns the test job queue of a project
+func (s *WorkerService) projectQueue(ctx context.Context, projectID string) (queue.JobQueue, error) {
+	return s.queues.Queue(ctx, TestJobsQueue+":"+projectID)
+}
+
+// EnqueueJob hands a run to its project's runners and returns the queue job ID
+func (s *WorkerService) EnqueueJob(ctx context.Context, run *models.TestRun) (string, error) {
+	q, err := s.projectQueue(ctx, run.ProjectID)
+	if err != nil {
+		return "", ErrEnqueueFailed
+	}
+
+	job := TestJob{
+		RunID:     run.ID,
+		TestID:    run.TestID,
+		ProjectID: run.ProjectID,
+		UserID:    run.UserID,
+		Script:    run.Script,
+		Browser:   run.Browser,
+		Headless:  run.Headless,
+		Timeout:   run.Timeout,
+	}
+
+	jobID, err := q.Enqueue(ctx, job)
+	if err != nil {
+		return "", ErrEnqueueFailed
+	}
+
+	return jobID, nil
 }
 
-func (s *WorkerService) EnqueueJob(testID, script string) error {
-	// TODO: Implement job enqueue logic to Redis
+// LeaseJob hands the next queued job of projectID to a worker, waiting up to wait for one
+// Returns nil, nil if no job became available in time
+func (s *WorkerService) LeaseJob(ctx context.Context, projectID, workerID string, wait time.Duration) (*LeasedJob, error) {
+	if _, err := s.GetWorkerStatus(ctx, projectID, workerID); err != nil {
+		return nil, err
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > MaxLeaseWait {
+		wait = MaxLeaseWait
+	}
+
+	q, err := s.projectQueue(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to lease job")
+	}
+
+	job, err := q.Lease(ctx, workerID, wait)
+	if err != nil {
+		return nil, errors.New("failed to lease job")
+	}
+	if job == nil {
+		return nil, nil
+	}
+
+	leased := &LeasedJob{JobID: job.ID, Attempts: job.Attempts}
+	if err := json.Unmarshal(job.Payload, &leased.Job); err != nil {
+		return nil, errors.New("failed to decode job")
+	}
+
+	return leased, nil
+}
+
+// AckJob marks a job of projectID leased by workerID as done
+// Returns queue.ErrJobNotLeased if the worker no longer holds the lease
+func (s *WorkerService) AckJob(ctx context.Context, projectID, workerID, jobID string) error {
+	q, err := s.projectQueue(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return q.Ack(ctx, workerID, jobID)
+}
+
+// RequeueJob gives a job of projectID leased by workerID back to the queue for another worker
+// Returns queue.ErrJobNotLeased if the worker no longer holds the lease
+func (s *WorkerService) RequeueJob(ctx context.Context, projectID, workerID, jobID string) error {
+	q, err := s.projectQueue(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return q.Nack(ctx, workerID, jobID)
+}
+
+// RemoveJob drops a job of projectID from the queue, whoever holds the lease
+// Returns queue.ErrJobNotFound if the job is no longer queued
+func (s *WorkerService) RemoveJob(ctx context.Context, projectID, jobID string) error {
+	q, err := s.projectQueue(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return q.Remove(ctx, jobID)
+}
+
+// Heartbeat records that a worker in projectID is still alive
+// and renews its lease on the job of the run it is executing, so runs
+// longer than the queue's visibility timeout are not handed to another worker
+func (s *WorkerService) Heartbeat(ctx context.Context, projectID, workerID string) error {
+	err := s.workerRepo.UpdatePing(ctx, workerID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrWorkerNotFound
+	}
+	if err != nil {
+		return errors.New("failed to record heartbeat")
+	}
+
+	worker, err := s.GetWorkerStatus(ctx, projectID, workerID)
+	if err != nil {
+		return err
+	}
+	if worker.CurrentJob != "" {
+		s.extendLease(ctx, worker)
+	}
+
 	return nil
 }
 
-func (s *WorkerService) GetWorkerStatus() ([]interface{}, error) {
-	// TODO: Implement get worker status logic
-	return nil, nil
+// extendLease renews worker's lease on the job of its current run
+// Failures are only logged: the heartbeat itself was recorded
+func (s *WorkerService) extendLease(ctx context.Context, worker *models.Worker) {
+	run, err := s.runRepo.GetByID(ctx, worker.CurrentJob, worker.ProjectID)
+	if err != nil {
+		log.Printf("Failed to load run %s of worker %s: %v", worker.CurrentJob, worker.ID, err)
+		return
+	}
+	if run.Status != models.RunStatusRunning || run.WorkerID != worker.ID || run.JobID == "" {
+		return
+	}
+
+	q, err := s.projectQueue(ctx, worker.ProjectID)
+	if err == nil {
+		err = q.Extend(ctx, worker.ID, run.JobID)
+	}
+	if err != nil {
+		log.Printf("Failed to extend the lease on job %s of run %s: %v", run.JobID, run.ID, err)
+	}
+}
+
+// StatusRequest represents a worker's status report
+type StatusRequest struct {
+	Status     string `json:"status"`      // idle or busy
+	CurrentJob string `json:"current_job"` // run ID, required when busy
+}
+
+// ReportStatus records whether a worker in projectID is idle or busy
+func (s *WorkerService) ReportStatus(ctx context.Context, projectID, workerID string, req StatusRequest) error {
+	switch req.Status {
+	case models.WorkerStatusIdle:
+		req.CurrentJob = ""
+	case models.WorkerStatusBusy:
+		if req.CurrentJob == "" {
+			return invalidRequest("current_job is required when busy")
+		}
+	default:
+		return invalidRequest("status must be idle or busy")
+	}
+
+	err := s.workerRepo.UpdateStatus(ctx, workerID, projectID, req.Status, req.CurrentJob)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrWorkerNotFound
+	}
+	if err != nil {
+		return errors.New("failed to update worker status")
+	}
+
+	return nil
+}
+
+// GetWorkers returns every worker registered in projectID
+func (s *WorkerService) GetWorkers(ctx context.Context, projectID string) ([]models.Worker, error) {
+	workers, err := s.workerRepo.GetAll(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workers")
+	}
+
+	return workers, nil
+}
+
+// GetWorkerStatus returns a single worker in projectID
+func (s *WorkerService) GetWorkerStatus(ctx context.Context, projectID, workerID string) (*models.Worker, error) {
+	worker, err := s.workerRepo.GetByID(ctx, workerID, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrWorkerNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve worker")
+	}
+
+	return worker, nil
 }
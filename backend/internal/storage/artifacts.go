@@ -0,0 +1,91 @@
+package storage
+
+/**
+ * Artifact Storage
+ *
+ * Purpose: Store recorded videos and screenshots
+ *
+ * Artifacts are addressed by opaque keys such as "runs/<run id>/video.mp4".
+ * Documents only ever store the key, never a driver-specific path or URL,
+ * so artifacts can be moved between drivers without rewriting documents.
+ *
+ * Drivers (selected with ARTIFACT_STORE):
+ * - local: LocalStore, files under a directory on this machine (default)
+ * - s3:    S3Store, any S3-compatible object store (AWS S3, MinIO, ...)
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/internal/utils"
+)
+
+// ErrArtifactNotFound is returned when no artifact exists under a key
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// ErrInvalidKey is returned for keys that are empty or try to escape the store
+var ErrInvalidKey = errors.New("invalid artifact key")
+
+// ArtifactInfo describes a stored artifact
+type ArtifactInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ArtifactStore stores artifacts under opaque keys
+type ArtifactStore interface {
+	// Put streams content into the store under key, replacing any existing artifact
+	// size may be -1 when unknown; content is never buffered whole in memory
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+
+	// Get opens an artifact for reading; the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, *ArtifactInfo, error)
+
+	// Stat returns an artifact's metadata without reading it
+	Stat(ctx context.Context, key string) (*ArtifactInfo, error)
+
+	// Delete removes an artifact; deleting a missing artifact is not an error
+	Delete(ctx context.Context, key string) error
+
+	// Presign returns a URL that allows downloading the artifact without credentials until ttl passes
+	Presign(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// OpenArtifactStore creates the artifact store selected by cfg.ArtifactStore
+func OpenArtifactStore(ctx context.Context, cfg *utils.Config) (ArtifactStore, error) {
+	switch cfg.ArtifactStore {
+	case "local":
+		return NewLocalStore(cfg.ArtifactsDir, cfg.ArtifactPublicURL, cfg.ArtifactSigningKey)
+	case "s3":
+		return NewS3Store(ctx, S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown artifact store %q", cfg.ArtifactStore)
+	}
+}
+
+// validateKey rejects keys that are empty, absolute or contain ".." segments
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"runs/abc/video.mp4", true},
+		{"runs/abc/screenshots/step 1.png", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"runs/../../etc/passwd", false},
+		{"..", false},
+		{"runs/./video.mp4", false},
+		{"runs//video.mp4", false},
+		{"runs/abc/", false},
+		{"runs\\..\\secret", false},
+		{"runs/abc\\video.mp4", false},
+	}
+
+	for _, tt := range tests {
+		err := validateKey(tt.key)
+		if tt.valid && err != nil {
+			t.Errorf("validateKey(%q) = %v, want nil", tt.key, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("validateKey(%q) = %v, want ErrInvalidKey", tt.key, err)
+		}
+	}
+}
+
+// testArtifactStore runs the behaviour every ArtifactStore driver must share
+// fetch downloads a presigned URL and returns its body
+func testArtifactStore(t *testing.T, store ArtifactStore, fetch func(t *testing.T, url string) string) {
+	ctx := context.Background()
+	const key = "runs/run-1/screenshots/step-1.png"
+	const content = "not really a screenshot"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), -1, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Key != key || info.Size != int64(len(content)) || info.ContentType != "image/png" {
+		t.Errorf("Stat = %+v", info)
+	}
+
+	reader, info, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(body) != content || info.Size != int64(len(content)) {
+		t.Errorf("Get = %q (%d bytes), want %q", body, info.Size, content)
+	}
+
+	url, err := store.Presign(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("Presign: %v", err)
+	}
+	if got := fetch(t, url); got != content {
+		t.Errorf("presigned download = %q, want %q", got, content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("second Delete = %v, want nil", err)
+	}
+	if _, err := store.Stat(ctx, key); !errors.Is(err, ErrArtifactNotFound) {
+		t.Errorf("Stat after Delete = %v, want ErrArtifactNotFound", err)
+	}
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrArtifactNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrArtifactNotFound", err)
+	}
+
+	for _, bad := range []string{"../escape", "/absolute", "runs\\escape"} {
+		if err := store.Put(ctx, bad, strings.NewReader(content), -1, "image/png"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+/**
+ * Local Artifact Store
+ *
+ * Purpose: Keep artifacts as files under a directory on this machine
+ *
+ * Presigned URLs point back at this backend (GET /api/artifacts/{key}) and
+ * carry an expiry and an HMAC signature that VerifySignature checks, so the
+ * browser can play a video without sending a JWT.
+ */
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature is returned for presigned URLs that are tampered with or expired
+var ErrInvalidSignature = errors.New("invalid or expired artifact link")
+
+type LocalStore struct {
+	root       string
+	publicURL  string
+	signingKey []byte
+}
+
+// NewLocalStore creates a store rooted at dir
+// publicURL is the externally reachable base URL of this backend, used in presigned links
+func NewLocalStore(dir, publicURL, signingKey string) (*LocalStore, error) {
+	if signingKey == "" {
+		return nil, errors.New("artifact signing key is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		root:       dir,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		signingKey: []byte(signingKey),
+	}, nil
+}
+
+// Put writes content to a temporary file and renames it into place, so a
+// failed upload never leaves a truncated artifact behind
+// The content type is not stored; it is derived from the key's extension
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+// Get opens an artifact for reading
+// The returned reader is an *os.File, so callers may seek in it
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *ArtifactInfo, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, s.info(key, stat), nil
+}
+
+// Stat returns an artifact's metadata without reading it
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ArtifactInfo, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.info(key, stat), nil
+}
+
+// Delete removes an artifact; deleting a missing artifact is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Presign returns a link to GET /api/artifacts/{key} that is valid until ttl passes
+func (s *LocalStore) Presign(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+
+	var escaped []string
+	for _, segment := range strings.Split(key, "/") {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+
+	return s.publicURL + "/api/artifacts/" + strings.Join(escaped, "/") + "?" + query.Encode(), nil
+}
+
+// VerifySignature checks the expires and signature parameters of a presigned link
+func (s *LocalStore) VerifySignature(key, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file under the root directory
+func (s *LocalStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStore) info(key string, stat fs.FileInfo) *ArtifactInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &ArtifactInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentType,
+		LastModified: stat.ModTime(),
+	}
+}
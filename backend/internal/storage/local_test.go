@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	store, err := NewLocalStore(t.TempDir(), "http://backend.test/", "test-signing-key")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return store
+}
+
+func TestLocalStore(t *testing.T) {
+	store := newTestLocalStore(t)
+
+	// Serve the link the way GET /api/artifacts/{key} does
+	testArtifactStore(t, store, func(t *testing.T, link string) string {
+		key, expires, signature := parseLocalLink(t, link)
+		if err := store.VerifySignature(key, expires, signature); err != nil {
+			t.Fatalf("VerifySignature: %v", err)
+		}
+
+		reader, _, err := store.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		defer reader.Close()
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(body)
+	})
+}
+
+func TestNewLocalStoreRequiresSigningKey(t *testing.T) {
+	if _, err := NewLocalStore(t.TempDir(), "http://backend.test", ""); err == nil {
+		t.Fatal("NewLocalStore accepted an empty signing key")
+	}
+}
+
+func TestLocalStoreVerifySignature(t *testing.T) {
+	store := newTestLocalStore(t)
+	link, err := store.Presign(context.Background(), "runs/run-1/video.mp4", time.Minute)
+	if err != nil {
+		t.Fatalf("Presign: %v", err)
+	}
+	key, expires, signature := parseLocalLink(t, link)
+
+	if key != "runs/run-1/video.mp4" {
+		t.Fatalf("link key = %q", key)
+	}
+	if err := store.VerifySignature(key, expires, signature); err != nil {
+		t.Fatalf("VerifySignature of a fresh link: %v", err)
+	}
+
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	other, err := NewLocalStore(t.TempDir(), "http://backend.test", "another-signing-key")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	tests := []struct {
+		name                    string
+		key, expires, signature string
+	}{
+		{"expired", key, expired, store.sign(key, expired)},
+		{"extended expiry", key, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10), signature},
+		{"other key", "runs/run-2/video.mp4", expires, signature},
+		{"tampered signature", key, expires, strings.Repeat("0", len(signature))},
+		{"signed with another signing key", key, expires, other.sign(key, expires)},
+		{"missing signature", key, expires, ""},
+		{"malformed expiry", key, "soon", signature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.VerifySignature(tt.key, tt.expires, tt.signature)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySignature = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+// parseLocalLink splits a presigned local link into its key, expiry and signature
+func parseLocalLink(t *testing.T, link string) (string, string, string) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse %q: %v", link, err)
+	}
+	key, ok := strings.CutPrefix(u.Path, "/api/artifacts/")
+	if !ok || u.Host != "backend.test" {
+		t.Fatalf("link %q does not point at /api/artifacts on the public URL", link)
+	}
+	return key, u.Query().Get("expires"), u.Query().Get("signature")
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestS3Store needs an S3-compatible server; set TEST_S3_ENDPOINT to run it, e.g.
+// docker run -p 9000:9000 minio/minio server /data
+// TEST_S3_ENDPOINT=localhost:9000 TEST_S3_ACCESS_KEY=minioadmin TEST_S3_SECRET_KEY=minioadmin go test ./internal/storage
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT not set")
+	}
+
+	ctx := context.Background()
+	store, err := NewS3Store(ctx, S3Config{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    "artifacts-test-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		AccessKey: os.Getenv("TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("TEST_S3_SECRET_KEY"),
+		UseSSL:    os.Getenv("TEST_S3_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	t.Cleanup(func() { store.client.RemoveBucket(ctx, store.bucket) })
+
+	testArtifactStore(t, store, func(t *testing.T, url string) string {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET presigned URL: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET presigned URL: %s", resp.Status)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(body)
+	})
+}
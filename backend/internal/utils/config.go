@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -10,16 +15,307 @@ type Config struct {
 	RedisURL      string
 	JWTSecret     string
 	Environment   string
+
+	// JWT signing keys; see services/jwt_keys.go
+	JWTAlgorithm              string
+	JWTKeyID                  string
+	JWTPrivateKeyFile         string
+	JWTPreviousSecrets        string
+	JWTPreviousPublicKeyFiles string
+
+	// AccessTokenTTL is how long a JWT access token is valid
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL is how long a login session may go without refreshing
+	RefreshTokenTTL time.Duration
+
+	// RoleCacheTTL is how long a user's role is cached; role changes take up to this long to apply
+	RoleCacheTTL time.Duration
+
+	// QueueBackend selects the job queue implementation: redis, mongo or memory
+	QueueBackend string
+
+	// QueueVisibilityTimeout is how long a runner may hold a job without acking it
+	// before the job is handed to another runner
+	QueueVisibilityTimeout time.Duration
+
+	// QueueMaxAttempts is how many times a job is delivered without an ack
+	// before it is moved to the queue's dead-letter list
+	QueueMaxAttempts int
+
+	// WorkerOfflineAfter is how long a worker may go without a heartbeat
+	// before it is marked offline and its current run is re-queued
+	WorkerOfflineAfter time.Duration
+
+	// SchedulerInterval is how often the scheduler checks for due schedules
+	SchedulerInterval time.Duration
+
+	// ArtifactStore selects where videos and screenshots are stored: local or s3
+	ArtifactStore string
+
+	// ArtifactsDir is where the local artifact store keeps its files
+	ArtifactsDir string
+
+	// ArtifactPublicURL is this backend's external base URL, used in local artifact links
+	ArtifactPublicURL string
+
+	// ArtifactSigningKey signs local artifact links; required outside development
+	ArtifactSigningKey string
+
+	// ArtifactURLTTL is how long an artifact link handed to a client stays valid
+	ArtifactURLTTL time.Duration
+
+	// S3 settings for the s3 artifact store (AWS S3, MinIO, ...)
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+
+	// MaxUploadSize caps a single result upload, artifacts included, in bytes
+	MaxUploadSize int64
+
+	// Mailer selects how email is sent: smtp, file or memory
+	Mailer string
+
+	// MailFrom is the sender address of every email
+	MailFrom string
+
+	// MailDir is where the file mailer writes messages
+	MailDir string
+
+	// SMTP settings for the smtp mailer
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// AppBaseURL is the frontend's external base URL, used in emailed links
+	AppBaseURL string
+
+	// EmailTokenSecret signs email verification and password reset links
+	EmailTokenSecret string
+
+	// EmailVerificationTTL and PasswordResetTTL are how long each kind of emailed link stays valid
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+
+	// RequireEmailVerification stops users from logging in until their email is verified
+	RequireEmailVerification bool
+
+	// MFAIssuer is the account name authenticator apps show for TOTP
+	MFAIssuer string
+
+	// MFAEncryptionKey encrypts stored TOTP secrets
+	MFAEncryptionKey string
+
+	// MFAPendingTTL is how long a user has to enter their code after the password
+	MFAPendingTTL time.Duration
+
+	// OIDCProviders are the OpenID Connect login providers (OIDC_PROVIDERS)
+	OIDCProviders []OIDCProviderConfig
+
+	// LoginMaxFailures is how many failed logins an account gets before backoff starts;
+	// LoginIPMaxFailures is the same for a client IP, which may be shared
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+
+	// LoginBackoff is the first lock after too many failures; it doubles with
+	// every further failure up to LoginLockout
+	LoginBackoff time.Duration
+	LoginLockout time.Duration
+
+	// LoginFailureWindow is how long failures are remembered after the last one
+	LoginFailureWindow time.Duration
+
+	// TrustProxyHeaders takes client IPs from X-Forwarded-For (set it only behind a reverse proxy)
+	TrustProxyHeaders bool
+}
+
+// OIDCProviderConfig configures one OpenID Connect login provider
+// Settings are read from OIDC_<NAME>_*; unset fields fall back to the
+// provider's preset, if any (see oidc/presets.go).
+type OIDCProviderConfig struct {
+	Name         string   // used in URLs: /api/auth/oidc/<name>/...
+	DisplayName  string   // shown on the login button
+	IssuerURL    string   // discovery at <issuer>/.well-known/openid-configuration
+	ClientID     string   // expected ID token audience
+	ClientSecret string   // empty for public clients (PKCE only)
+	Scopes       []string // requested in the authorization code flow
+	RedirectURL  string   // frontend page the provider redirects back to
+
+	// Claim mapping onto models.User
+	EmailClaim         string
+	EmailVerifiedClaim string
+	NameClaim          string
+	PictureClaim       string
+}
+
+// developmentSecretPrefix starts the stand-in secrets used in development
+// when a secret is not set; Validate refuses them in any other environment
+const developmentSecretPrefix = "insecure-development-"
+
+// placeholderSecrets are sample values from older configs and docs, refused outside development
+var placeholderSecrets = map[string]bool{
+	"your-artifact-signing-key":                           true,
+	"your-secret-key":                                     true,
+	"your-super-secret-jwt-key-change-this-in-production": true,
+	"change-this-in-production":                           true,
+	"your-email-token-secret":                             true,
+	"your-mfa-encryption-key":                             true,
 }
 
 func LoadConfig() *Config {
+	environment := getEnv("ENVIRONMENT", "production")
+
 	return &Config{
 		Port:          getEnv("PORT", "8080"),
 		DatabaseURL:   getEnv("DATABASE_URL", ""),
 		RedisURL:      getEnv("REDIS_URL", "redis://localhost:6379"),
-		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
-		Environment:   getEnv("ENVIRONMENT", "development"),
+		JWTSecret:     jwtSecret(environment),
+		Environment:   environment,
+
+		JWTAlgorithm:              getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyID:                  getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKeyFile:         getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPreviousSecrets:        getEnv("JWT_PREVIOUS_SECRETS", ""),
+		JWTPreviousPublicKeyFiles: getEnv("JWT_PREVIOUS_PUBLIC_KEY_FILES", ""),
+		AccessTokenTTL:            getDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:           getDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RoleCacheTTL:              getDurationEnv("ROLE_CACHE_TTL", 30*time.Second),
+
+		QueueBackend:           getEnv("QUEUE_BACKEND", "redis"),
+		QueueVisibilityTimeout: getDurationEnv("QUEUE_VISIBILITY_TIMEOUT", 10*time.Minute),
+		QueueMaxAttempts:       getPositiveIntEnv("QUEUE_MAX_ATTEMPTS", 5),
+		WorkerOfflineAfter:     getDurationEnv("WORKER_OFFLINE_AFTER", 90*time.Second),
+		SchedulerInterval:      getDurationEnv("SCHEDULER_INTERVAL", 15*time.Second),
+		ArtifactStore:          getEnv("ARTIFACT_STORE", "local"),
+		ArtifactsDir:           getEnv("ARTIFACTS_DIR", "./artifacts"),
+		ArtifactPublicURL:      getEnv("ARTIFACT_PUBLIC_URL", "http://localhost:8080"),
+		ArtifactSigningKey:     getSecretEnv("ARTIFACT_SIGNING_KEY", environment),
+		ArtifactURLTTL:         getDurationEnv("ARTIFACT_URL_TTL", 15*time.Minute),
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", "testops-artifacts"),
+		S3AccessKey:            getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:            getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:               getEnv("S3_USE_SSL", "true") == "true",
+		MaxUploadSize:          getInt64Env("MAX_UPLOAD_SIZE", 2<<30),
+
+		Mailer:                   getEnv("MAILER", "file"),
+		MailFrom:                 getEnv("MAIL_FROM", "TestOps <no-reply@testops.local>"),
+		MailDir:                  getEnv("MAIL_DIR", "./mail"),
+		SMTPHost:                 getEnv("SMTP_HOST", ""),
+		SMTPPort:                 int(getInt64Env("SMTP_PORT", 587)),
+		SMTPUsername:             getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:             getEnv("SMTP_PASSWORD", ""),
+		AppBaseURL:               getEnv("APP_BASE_URL", "http://localhost:3456"),
+		EmailTokenSecret:         getSecretEnv("EMAIL_TOKEN_SECRET", environment),
+		EmailVerificationTTL:     getDurationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		PasswordResetTTL:         getDurationEnv("PASSWORD_RESET_TTL", time.Hour),
+		RequireEmailVerification: getEnv("REQUIRE_EMAIL_VERIFICATION", "false") == "true",
+
+		MFAIssuer:        getEnv("MFA_ISSUER", "TestOps"),
+		MFAEncryptionKey: getSecretEnv("MFA_ENCRYPTION_KEY", environment),
+		MFAPendingTTL:    getDurationEnv("MFA_PENDING_TTL", 5*time.Minute),
+
+		OIDCProviders: loadOIDCProviders(getEnv("OIDC_PROVIDERS", "google")),
+
+		LoginMaxFailures:   int(getInt64Env("LOGIN_MAX_FAILURES", 5)),
+		LoginIPMaxFailures: int(getInt64Env("LOGIN_IP_MAX_FAILURES", 50)),
+		LoginBackoff:       getDurationEnv("LOGIN_BACKOFF", 30*time.Second),
+		LoginLockout:       getDurationEnv("LOGIN_LOCKOUT", time.Hour),
+		LoginFailureWindow: getDurationEnv("LOGIN_FAILURE_WINDOW", time.Hour),
+		TrustProxyHeaders:  getEnv("TRUST_PROXY_HEADERS", "false") == "true",
+	}
+}
+
+// IsDevelopment reports whether ENVIRONMENT=development, which allows missing secrets
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == "development"
+}
+
+// Validate refuses configurations that are unsafe to run
+// Outside development every secret in use must be set, and not to a sample value.
+func (c *Config) Validate() error {
+	if c.IsDevelopment() {
+		return nil
+	}
+
+	var secrets []secretSetting
+	switch c.JWTAlgorithm {
+	case "", "HS256":
+		secrets = append(secrets, secretSetting{"JWT_SECRET", c.JWTSecret})
+	default:
+		// An asymmetric key stands in for the shared secret
+		if c.JWTPrivateKeyFile == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY_FILE must be set for JWT_ALGORITHM=%s", c.JWTAlgorithm)
+		}
 	}
+	for _, secret := range strings.Split(c.JWTPreviousSecrets, ",") {
+		// Tokens signed with a previous secret still verify, so it must be secret too
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secretSetting{"JWT_PREVIOUS_SECRETS", secret})
+		}
+	}
+	secrets = append(secrets,
+		secretSetting{"EMAIL_TOKEN_SECRET", c.EmailTokenSecret},
+		secretSetting{"MFA_ENCRYPTION_KEY", c.MFAEncryptionKey},
+	)
+	if c.ArtifactStore == "local" {
+		secrets = append(secrets, secretSetting{"ARTIFACT_SIGNING_KEY", c.ArtifactSigningKey})
+	}
+
+	for _, secret := range secrets {
+		if !isSecret(secret.value) {
+			return fmt.Errorf("%s must be set to a secret value unless ENVIRONMENT=development", secret.key)
+		}
+	}
+	return nil
+}
+
+// secretSetting is a secret Validate checks, with the variable it is read from
+type secretSetting struct {
+	key   string
+	value string
+}
+
+// isSecret reports whether value is set and is not a placeholder
+func isSecret(value string) bool {
+	return value != "" && !placeholderSecrets[value] && !strings.HasPrefix(value, developmentSecretPrefix)
+}
+
+// loadOIDCProviders reads OIDC_<NAME>_* for each name of a comma-separated list
+func loadOIDCProviders(names string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		var scopes []string
+		if value := os.Getenv(prefix + "SCOPES"); value != "" {
+			scopes = strings.Fields(strings.ReplaceAll(value, ",", " "))
+		}
+
+		providers = append(providers, OIDCProviderConfig{
+			Name:               name,
+			DisplayName:        os.Getenv(prefix + "DISPLAY_NAME"),
+			IssuerURL:          os.Getenv(prefix + "ISSUER"),
+			ClientID:           os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:       os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:             scopes,
+			RedirectURL:        os.Getenv(prefix + "REDIRECT_URL"),
+			EmailClaim:         os.Getenv(prefix + "EMAIL_CLAIM"),
+			EmailVerifiedClaim: os.Getenv(prefix + "EMAIL_VERIFIED_CLAIM"),
+			NameClaim:          os.Getenv(prefix + "NAME_CLAIM"),
+			PictureClaim:       os.Getenv(prefix + "PICTURE_CLAIM"),
+		})
+	}
+	return providers
 }
 
 func getEnv(key, defaultValue string) string {
@@ -29,3 +325,60 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getSecretEnv reads a secret; in development a missing one gets a fixed stand-in
+// so a local stack works without setup. Validate refuses the stand-ins elsewhere.
+func getSecretEnv(key, environment string) string {
+	value := os.Getenv(key)
+	if value == "" && environment == "development" {
+		return developmentSecretPrefix + strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+	}
+	return value
+}
+
+// jwtSecret reads JWT_SECRET; only HS256 needs it, so it has no stand-in when
+// an asymmetric JWT_ALGORITHM is configured
+func jwtSecret(environment string) string {
+	if algorithm := getEnv("JWT_ALGORITHM", "HS256"); algorithm != "HS256" {
+		return os.Getenv("JWT_SECRET")
+	}
+	return getSecretEnv("JWT_SECRET", environment)
+}
+
+// getDurationEnv parses a Go duration string such as "90s" or "10m"
+// Every duration setting is a TTL, timeout or interval, so values that are
+// not positive (which would make time.NewTicker panic) fall back to the default.
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Ignoring %s=%q: expected a positive duration, using %s", key, raw, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+func getInt64Env(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getPositiveIntEnv parses a count that must be at least 1, falling back to the default otherwise
+func getPositiveIntEnv(key string, defaultValue int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		log.Printf("Ignoring %s=%q: expected a positive number, using %d", key, raw, defaultValue)
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSecretEnv(t *testing.T) {
+	t.Setenv("TEST_SECRET", "")
+	if got := getSecretEnv("TEST_SECRET", "production"); got != "" {
+		t.Errorf("unset secret outside development = %q, want empty", got)
+	}
+	if got := getSecretEnv("TEST_SECRET", "development"); !strings.HasPrefix(got, developmentSecretPrefix) {
+		t.Errorf("unset secret in development = %q, want a development stand-in", got)
+	}
+
+	t.Setenv("TEST_SECRET", "s3cret")
+	if got := getSecretEnv("TEST_SECRET", "development"); got != "s3cret" {
+		t.Errorf("set secret = %q, want s3cret", got)
+	}
+}
+
+func TestValidateArtifactSigningKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		store       string
+		key         string
+		wantErr     bool
+	}{
+		{"set", "production", "local", "a-real-secret", false},
+		{"unset", "production", "local", "", true},
+		{"sample value", "production", "local", "your-artifact-signing-key", true},
+		{"development stand-in", "production", "local", developmentSecretPrefix + "artifact-signing-key", true},
+		{"unset in development", "development", "local", "", false},
+		{"unused by s3", "production", "s3", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Environment = tt.environment
+			cfg.ArtifactStore = tt.store
+			cfg.ArtifactSigningKey = tt.key
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "ARTIFACT_SIGNING_KEY") {
+				t.Errorf("error %q does not name ARTIFACT_SIGNING_KEY", err)
+			}
+		})
+	}
+}
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"set", func(cfg *Config) {}, ""},
+		{"unset", func(cfg *Config) { cfg.JWTSecret = "" }, "JWT_SECRET"},
+		{"sample value", func(cfg *Config) { cfg.JWTSecret = "your-secret-key" }, "JWT_SECRET"},
+		{"sample value from .env", func(cfg *Config) { cfg.JWTSecret = "your-super-secret-jwt-key-change-this-in-production" }, "JWT_SECRET"},
+		{"development stand-in", func(cfg *Config) { cfg.JWTSecret = developmentSecretPrefix + "jwt-secret" }, "JWT_SECRET"},
+		{"sample previous secret", func(cfg *Config) { cfg.JWTPreviousSecrets = "old-secret, your-secret-key" }, "JWT_PREVIOUS_SECRETS"},
+		{"previous secrets", func(cfg *Config) { cfg.JWTPreviousSecrets = "old-secret,older-secret" }, ""},
+		{"asymmetric key instead", func(cfg *Config) {
+			cfg.JWTAlgorithm = "EdDSA"
+			cfg.JWTSecret = ""
+			cfg.JWTPrivateKeyFile = "/keys/jwt.pem"
+		}, ""},
+		{"asymmetric without a key file", func(cfg *Config) {
+			cfg.JWTAlgorithm = "RS256"
+			cfg.JWTSecret = "a-real-secret"
+		}, "JWT_PRIVATE_KEY_FILE"},
+		{"unset in development", func(cfg *Config) {
+			cfg.Environment = "development"
+			cfg.JWTSecret = ""
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Validate() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEmailTokenSecret(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		secret      string
+		wantErr     bool
+	}{
+		{"set", "production", "a-real-secret", false},
+		{"unset", "production", "", true},
+		{"sample value", "production", "your-email-token-secret", true},
+		{"development stand-in", "production", developmentSecretPrefix + "email-token-secret", true},
+		{"unset in development", "development", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Environment = tt.environment
+			cfg.EmailTokenSecret = tt.secret
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "EMAIL_TOKEN_SECRET") {
+				t.Errorf("error %q does not name EMAIL_TOKEN_SECRET", err)
+			}
+		})
+	}
+}
+
+func TestValidateMFAEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		key         string
+		wantErr     bool
+	}{
+		{"set", "production", "a-real-secret", false},
+		{"unset", "production", "", true},
+		{"sample value", "production", "your-mfa-encryption-key", true},
+		{"development stand-in", "production", developmentSecretPrefix + "mfa-encryption-key", true},
+		{"unset in development", "development", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Environment = tt.environment
+			cfg.MFAEncryptionKey = tt.key
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "MFA_ENCRYPTION_KEY") {
+				t.Errorf("error %q does not name MFA_ENCRYPTION_KEY", err)
+			}
+		})
+	}
+}
+
+// validConfig returns a production configuration that passes Validate
+func validConfig() *Config {
+	return &Config{
+		Environment:        "production",
+		JWTAlgorithm:       "HS256",
+		JWTSecret:          "jwt-secret",
+		EmailTokenSecret:   "email-token-secret",
+		MFAEncryptionKey:   "mfa-encryption-key",
+		ArtifactStore:      "local",
+		ArtifactSigningKey: "artifact-secret",
+	}
+}
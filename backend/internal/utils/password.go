@@ -0,0 +1,113 @@
+package utils
+
+/**
+ * Password Hashing
+ *
+ * Purpose: Hash and verify user passwords
+ *
+ * Passwords are hashed with argon2id and stored in the PHC string format:
+ *
+ *   $argon2id$v=19$m=65536,t=3,p=2$<base64 salt>$<base64 hash>
+ *
+ * The algorithm, version and cost parameters travel with every hash, so the
+ * parameters can be raised later: VerifyPassword reports when a stored hash
+ * was made with weaker settings and should be replaced. A stored value that
+ * is not a hash is a legacy plaintext password; it still verifies, but is
+ * always reported as needing a rehash.
+ */
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Current argon2id parameters for new hashes
+const (
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Time    = 3
+	argon2Threads = 2
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+const argon2Prefix = "$argon2id$"
+
+// ErrInvalidPasswordHash is returned for stored hashes that cannot be parsed
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// HashPassword hashes a password with argon2id using the current parameters
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// IsPasswordHash reports whether a stored password is a hash rather than legacy plaintext
+func IsPasswordHash(stored string) bool {
+	return strings.HasPrefix(stored, argon2Prefix)
+}
+
+// VerifyPassword checks password against a stored hash or legacy plaintext password
+// needsRehash is true when the password matched but the stored value should be
+// replaced with HashPassword(password). An empty stored value never matches.
+func VerifyPassword(stored, password string) (match bool, needsRehash bool, err error) {
+	if stored == "" {
+		return false, false, nil
+	}
+
+	if !IsPasswordHash(stored) {
+		match = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return match, match, nil
+	}
+
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, hash
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return false, false, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrInvalidPasswordHash
+	}
+
+	var memory, time uint32
+	var threads uint8
+	// argon2 panics on zero rounds or parallelism
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || time == 0 || threads == 0 {
+		return false, false, ErrInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrInvalidPasswordHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+
+	needsRehash = memory != argon2Memory || time != argon2Time || threads != argon2Threads ||
+		len(salt) != argon2SaltLen || len(key) != argon2KeyLen
+	return true, needsRehash, nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !IsPasswordHash(hash) {
+		t.Fatalf("hash %q is not recognised as a hash", hash)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Errorf("hash %q does not carry the current parameters", hash)
+	}
+
+	other, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password are equal; the salt is not random")
+	}
+
+	match, needsRehash, err := VerifyPassword(hash, "correct horse")
+	if err != nil || !match || needsRehash {
+		t.Errorf("VerifyPassword(right password) = %v, %v, %v; want true, false, nil", match, needsRehash, err)
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	current, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	weaker := testHash("secret", 8*1024, 1, 1, 16, 32)
+
+	tests := []struct {
+		name            string
+		stored          string
+		password        string
+		wantMatch       bool
+		wantNeedsRehash bool
+	}{
+		{"current hash", current, "secret", true, false},
+		{"wrong password", current, "Secret", false, false},
+		{"empty password", current, "", false, false},
+		{"weaker parameters", weaker, "secret", true, true},
+		{"weaker parameters, wrong password", weaker, "nope", false, false},
+		{"legacy plaintext", "secret", "secret", true, true},
+		{"legacy plaintext, wrong password", "secret", "secrets", false, false},
+		{"empty stored value", "", "", false, false},
+		{"empty stored value, any password", "", "secret", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := VerifyPassword(tt.stored, tt.password)
+			if err != nil {
+				t.Fatalf("VerifyPassword: %v", err)
+			}
+			if match != tt.wantMatch || needsRehash != tt.wantNeedsRehash {
+				t.Errorf("VerifyPassword = %v, %v; want %v, %v", match, needsRehash, tt.wantMatch, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordMalformedHash(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+
+	tests := []struct {
+		name   string
+		stored string
+	}{
+		{"too few parts", "$argon2id$v=19$m=65536,t=3,p=2$" + salt},
+		{"too many parts", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + key + "$extra"},
+		{"bad version", "$argon2id$v=16$m=65536,t=3,p=2$" + salt + "$" + key},
+		{"unparsable version", "$argon2id$version$m=65536,t=3,p=2$" + salt + "$" + key},
+		{"unparsable parameters", "$argon2id$v=19$m=lots$" + salt + "$" + key},
+		{"zero rounds", "$argon2id$v=19$m=65536,t=0,p=2$" + salt + "$" + key},
+		{"zero parallelism", "$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key},
+		{"bad salt base64", "$argon2id$v=19$m=65536,t=3,p=2$not*base64$" + key},
+		{"bad hash base64", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$not*base64"},
+		{"empty hash", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := VerifyPassword(tt.stored, "secret")
+			if !errors.Is(err, ErrInvalidPasswordHash) {
+				t.Errorf("VerifyPassword = %v, want ErrInvalidPasswordHash", err)
+			}
+			if match || needsRehash {
+				t.Errorf("a malformed hash matched (%v, %v)", match, needsRehash)
+			}
+		})
+	}
+}
+
+// testHash builds a PHC string with the given argon2id parameters
+func testHash(password string, memory, time uint32, threads uint8, saltLen, keyLen int) string {
+	salt := []byte(strings.Repeat("s", saltLen))
+	key := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(keyLen))
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, memory, time, threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
@@ -0,0 +1,74 @@
+package utils
+
+/**
+ * User Agents
+ *
+ * Purpose: Describe the device behind a User-Agent header, e.g. "Chrome on macOS"
+ *
+ * Only the common browsers and systems are recognised, by the same substring
+ * checks browsers use to tell each other apart. Anything else is described by
+ * the first product token of the header ("curl", "python-requests").
+ */
+
+import "strings"
+
+// userAgentMatch maps a User-Agent substring to a name; the first match wins
+type userAgentMatch struct {
+	token string
+	name  string
+}
+
+// Order matters: Edge and Opera also claim to be Chrome, Chrome claims to be Safari
+var browsers = []userAgentMatch{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// Order matters: Android and iOS user agents also mention Linux and Mac OS X
+var systems = []userAgentMatch{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DescribeUserAgent returns a short, human-readable description of a User-Agent header
+func DescribeUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := matchUserAgent(userAgent, browsers)
+	system := matchUserAgent(userAgent, systems)
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	// Scripts and CLIs: "curl/8.4.0" -> "curl"
+	product, _, _ := strings.Cut(userAgent, " ")
+	product, _, _ = strings.Cut(product, "/")
+	return product
+}
+
+func matchUserAgent(userAgent string, matches []userAgentMatch) string {
+	for _, match := range matches {
+		if strings.Contains(userAgent, match.token) {
+			return match.name
+		}
+	}
+	return ""
+}
@@ -45,6 +45,372 @@ db.users.createIndex(
   { "created_at": -1 }
 );
 
+// Index on linked login provider accounts - each can belong to one user only
+print('Creating unique index on identities...');
+db.users.createIndex(
+  { "identities.provider": 1, "identities.subject": 1 },
+  { unique: true, partialFilterExpression: { "identities.subject": { $exists: true } } }
+);
+
+// ==================================================
+// TESTS COLLECTION SETUP
+// ==================================================
+
+// Create tests collection
+print('Creating tests collection...');
+db.createCollection('tests');
+
+// Index on project_id + created_at for listing a project's tests (tests are always scoped by project)
+print('Creating index on tests project_id...');
+db.tests.createIndex(
+  { "project_id": 1, "created_at": -1 }
+);
+
+// ==================================================
+// TEST RUNS COLLECTION SETUP
+// ==================================================
+
+// Create test_runs collection
+print('Creating test_runs collection...');
+db.createCollection('test_runs');
+
+// Index on test_id + project_id + queued_at for a test's run history
+print('Creating index on test_runs test_id...');
+db.test_runs.createIndex(
+  { "test_id": 1, "project_id": 1, "queued_at": -1 }
+);
+
+// ==================================================
+// WORKERS COLLECTION SETUP
+// ==================================================
+
+// Create workers collection
+print('Creating workers collection...');
+db.createCollection('workers');
+
+// Index on status + last_ping for the offline reaper
+print('Creating index on workers status...');
+db.workers.createIndex(
+  { "status": 1, "last_ping": 1 }
+);
+
+// Index on project_id + name for listing a project's workers
+print('Creating index on workers project_id...');
+db.workers.createIndex(
+  { "project_id": 1, "name": 1 }
+);
+
+// Unique index on credential_hash - runners authenticate with their worker credential
+print('Creating unique index on workers credential_hash...');
+db.workers.createIndex(
+  { "credential_hash": 1 },
+  { unique: true, partialFilterExpression: { "credential_hash": { $exists: true } } }
+);
+
+// ==================================================
+// WORKER REGISTRATION TOKENS COLLECTION SETUP
+// ==================================================
+
+// Create worker_registration_tokens collection (one-time tokens a runner registers with)
+print('Creating worker_registration_tokens collection...');
+db.createCollection('worker_registration_tokens');
+
+// Unique index on token_hash for looking up a presented token
+print('Creating unique index on worker_registration_tokens token_hash...');
+db.worker_registration_tokens.createIndex(
+  { "token_hash": 1 },
+  { unique: true }
+);
+
+// Index for listing a project's tokens
+print('Creating index on worker_registration_tokens project_id...');
+db.worker_registration_tokens.createIndex({ "project_id": 1, "created_at": -1 });
+
+// TTL index - tokens are removed once they expire
+print('Creating TTL index on worker_registration_tokens expires_at...');
+db.worker_registration_tokens.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// ==================================================
+// RESULTS COLLECTION SETUP
+// ==================================================
+
+// Create results collection
+print('Creating results collection...');
+db.createCollection('results');
+
+// Index on run_id + project_id for a run's results
+print('Creating index on results run_id...');
+db.results.createIndex(
+  { "run_id": 1, "project_id": 1, "created_at": -1 }
+);
+
+// ==================================================
+// RUN LOGS COLLECTION SETUP
+// ==================================================
+
+// Create run_logs collection
+print('Creating run_logs collection...');
+db.createCollection('run_logs');
+
+// Unique index on run_id + seq - one document per log line
+print('Creating unique index on run_logs run_id + seq...');
+db.run_logs.createIndex(
+  { "run_id": 1, "seq": 1 },
+  { unique: true }
+);
+
+// ==================================================
+// SUITES COLLECTION SETUP
+// ==================================================
+
+// Create suites collection
+print('Creating suites collection...');
+db.createCollection('suites');
+
+// Index on project_id for listing a project's suites
+print('Creating index on suites project_id...');
+db.suites.createIndex(
+  { "project_id": 1, "created_at": -1 }
+);
+
+// ==================================================
+// SUITE RUNS COLLECTION SETUP
+// ==================================================
+
+// Create suite_runs collection
+print('Creating suite_runs collection...');
+db.createCollection('suite_runs');
+
+// Index on suite_id + project_id for a suite's run history
+print('Creating index on suite_runs suite_id...');
+db.suite_runs.createIndex(
+  { "suite_id": 1, "project_id": 1, "queued_at": -1 }
+);
+
+// Index on test_runs suite_run_id for a suite run's member runs
+print('Creating index on test_runs suite_run_id...');
+db.test_runs.createIndex(
+  { "suite_run_id": 1 },
+  { sparse: true }
+);
+
+// ==================================================
+// SCHEDULES COLLECTION SETUP
+// ==================================================
+
+// Create schedules collection
+print('Creating schedules collection...');
+db.createCollection('schedules');
+
+// Index on project_id for listing a project's schedules
+print('Creating index on schedules project_id...');
+db.schedules.createIndex(
+  { "project_id": 1, "created_at": -1 }
+);
+
+// Index on enabled + next_fire_at for the scheduler's due query
+print('Creating index on schedules next_fire_at...');
+db.schedules.createIndex(
+  { "enabled": 1, "next_fire_at": 1 }
+);
+
+// ==================================================
+// TOKEN COLLECTIONS SETUP
+// ==================================================
+
+// Create refresh_tokens collection (documents are keyed by the token's SHA-256)
+print('Creating refresh_tokens collection...');
+db.createCollection('refresh_tokens');
+
+// Index on family_id for revoking a whole login session
+print('Creating index on refresh_tokens family_id...');
+db.refresh_tokens.createIndex(
+  { "family_id": 1 }
+);
+
+// Index on user_id for ending every session of a user
+print('Creating index on refresh_tokens user_id...');
+db.refresh_tokens.createIndex(
+  { "user_id": 1 }
+);
+
+// TTL index - expired refresh tokens are removed automatically
+print('Creating TTL index on refresh_tokens expires_at...');
+db.refresh_tokens.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// Create revoked_tokens collection (revoked access token IDs and session IDs)
+print('Creating revoked_tokens collection...');
+db.createCollection('revoked_tokens');
+
+// TTL index - entries are dropped once the tokens they match have expired
+print('Creating TTL index on revoked_tokens expires_at...');
+db.revoked_tokens.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// ==================================================
+// PERSONAL ACCESS TOKENS COLLECTION SETUP
+// ==================================================
+
+// Create personal_access_tokens collection (only token hashes are stored)
+print('Creating personal_access_tokens collection...');
+db.createCollection('personal_access_tokens');
+
+// Unique index on token_hash for authenticating requests
+print('Creating unique index on personal_access_tokens token_hash...');
+db.personal_access_tokens.createIndex(
+  { "token_hash": 1 },
+  { unique: true }
+);
+
+// Index on user_id for listing a user's tokens
+print('Creating index on personal_access_tokens user_id...');
+db.personal_access_tokens.createIndex(
+  { "user_id": 1, "created_at": -1 }
+);
+
+// ==================================================
+// ORGANIZATIONS AND PROJECTS COLLECTIONS SETUP
+// ==================================================
+
+// Create organizations collection
+print('Creating organizations collection...');
+db.createCollection('organizations');
+
+// Create organization_members collection (_id is "<organization_id>:<user_id>")
+print('Creating organization_members collection...');
+db.createCollection('organization_members');
+
+// Index on organization_id for listing an organization's members
+print('Creating index on organization_members organization_id...');
+db.organization_members.createIndex(
+  { "organization_id": 1, "created_at": 1 }
+);
+
+// Index on user_id for listing a user's organizations
+print('Creating index on organization_members user_id...');
+db.organization_members.createIndex(
+  { "user_id": 1 }
+);
+
+// Create projects collection (a user's personal project has their user ID as _id)
+print('Creating projects collection...');
+db.createCollection('projects');
+
+// Index on organization_id for listing an organization's projects
+print('Creating index on projects organization_id...');
+db.projects.createIndex(
+  { "organization_id": 1, "name": 1 }
+);
+
+// Create project_members collection (_id is "<project_id>:<user_id>")
+print('Creating project_members collection...');
+db.createCollection('project_members');
+
+// Index on project_id for listing a project's members
+print('Creating index on project_members project_id...');
+db.project_members.createIndex(
+  { "project_id": 1, "created_at": 1 }
+);
+
+// Index on user_id for listing a user's projects
+print('Creating index on project_members user_id...');
+db.project_members.createIndex(
+  { "user_id": 1 }
+);
+
+// ==================================================
+// EMAIL TOKENS COLLECTION SETUP
+// ==================================================
+
+// Create email_tokens collection (email verification and password reset links)
+print('Creating email_tokens collection...');
+db.createCollection('email_tokens');
+
+// Index on user_id for invalidating a user's outstanding links
+print('Creating index on email_tokens user_id...');
+db.email_tokens.createIndex(
+  { "user_id": 1, "purpose": 1 }
+);
+
+// TTL index - expired links are removed automatically
+print('Creating TTL index on email_tokens expires_at...');
+db.email_tokens.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// ==================================================
+// OIDC LOGINS COLLECTION SETUP
+// ==================================================
+
+// Create oidc_logins collection (_id is the OAuth state of a provider login in progress)
+print('Creating oidc_logins collection...');
+db.createCollection('oidc_logins');
+
+// TTL index - abandoned logins are removed automatically
+print('Creating TTL index on oidc_logins expires_at...');
+db.oidc_logins.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// ==================================================
+// LOGIN FAILURES COLLECTION SETUP
+// ==================================================
+
+// Create login_failures collection (_id is "ip:...", "email:..." or "user:...")
+print('Creating login_failures collection...');
+db.createCollection('login_failures');
+
+// TTL index - failures are forgotten a while after the last one
+print('Creating TTL index on login_failures expires_at...');
+db.login_failures.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
+// ==================================================
+// AUDIT EVENTS COLLECTION SETUP
+// ==================================================
+
+// Create audit_events collection (append-only: the backend never updates or deletes events)
+print('Creating audit_events collection...');
+db.createCollection('audit_events');
+
+// Indexes for the admin query filters, newest first
+print('Creating indexes on audit_events...');
+db.audit_events.createIndex({ "time": -1 });
+db.audit_events.createIndex({ "actor_id": 1, "_id": -1 });
+db.audit_events.createIndex({ "actor_email": 1, "_id": -1 });
+db.audit_events.createIndex({ "action": 1, "_id": -1 });
+
+// ==================================================
+// SESSIONS COLLECTION SETUP
+// ==================================================
+
+// Create sessions collection (_id is the refresh token family ID, the "sid" claim)
+print('Creating sessions collection...');
+db.createCollection('sessions');
+
+// Index for listing a user's sessions
+print('Creating index on sessions user_id...');
+db.sessions.createIndex({ "user_id": 1, "created_at": -1 });
+
+// TTL index - sessions are removed once they can no longer be refreshed
+print('Creating TTL index on sessions expires_at...');
+db.sessions.createIndex(
+  { "expires_at": 1 },
+  { expireAfterSeconds: 0 }
+);
+
 // ==================================================
 // SAMPLE DATA - FOR TESTING ONLY
 // ==================================================
@@ -54,8 +420,10 @@ print('Inserting sample tester user...');
 db.users.insertOne({
   username: "testuser",
   email: "test@example.com",
-  password: "test123",  // Plain text password (no JWT/hashing for now)
+  // argon2id hash of "test123" (see backend/internal/utils/password.go)
+  password: "$argon2id$v=19$m=65536,t=3,p=2$5zvirYJKQx914/hjuBfpHA$JbqFcchJJ8bWPhbHmlBwTTMqjpb8ubnO16lM1uVhrs8",
   role: "tester",       // Default role for regular users
+  email_verified: true,
   created_at: new Date(),
   updated_at: new Date()
 });
@@ -65,8 +433,10 @@ print('Inserting sample admin user...');
 db.users.insertOne({
   username: "admin",
   email: "admin@testops.com",
-  password: "admin123",  // Plain text password (no JWT/hashing for now)
+  // argon2id hash of "admin123"
+  password: "$argon2id$v=19$m=65536,t=3,p=2$vMqJR3n9S8lQAf9YdagvTQ$m7oWHa1F0C/aQlE8vgamjqhWXBOm+DTGK+bVYQcMZaA",
   role: "admin",         // Admin role for administrative users
+  email_verified: true,
   created_at: new Date(),
   updated_at: new Date()
 });
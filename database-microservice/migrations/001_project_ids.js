@@ -0,0 +1,50 @@
+// ==================================================
+// MIGRATION 001 - PROJECT IDS
+// ==================================================
+// Tests, suites, runs, results, logs, schedules and workers used to be
+// scoped by user_id; they are now scoped by project_id. Existing documents
+// move to their creator's personal project, whose ID is the user ID. The
+// personal project itself is created on the user's next request.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/001_project_ids.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 001: project IDs ===');
+
+['tests', 'suites', 'suite_runs', 'test_runs', 'results', 'run_logs', 'schedules', 'workers'].forEach(function(collection) {
+  var result = db[collection].updateMany(
+    { project_id: { $exists: false }, user_id: { $exists: true } },
+    [{ $set: { project_id: "$user_id" } }]
+  );
+  print('  ' + collection + ': ' + result.modifiedCount + ' document(s) updated');
+});
+
+// Replace the user_id indexes with the project_id ones from init-mongo.js
+var replaced = {
+  tests: [{ user_id: 1, created_at: -1 }, { project_id: 1, created_at: -1 }],
+  test_runs: [{ test_id: 1, user_id: 1, queued_at: -1 }, { test_id: 1, project_id: 1, queued_at: -1 }],
+  results: [{ run_id: 1, user_id: 1, created_at: -1 }, { run_id: 1, project_id: 1, created_at: -1 }],
+  suites: [{ user_id: 1, created_at: -1 }, { project_id: 1, created_at: -1 }],
+  suite_runs: [{ suite_id: 1, user_id: 1, queued_at: -1 }, { suite_id: 1, project_id: 1, queued_at: -1 }],
+  schedules: [{ user_id: 1, created_at: -1 }, { project_id: 1, created_at: -1 }]
+};
+Object.keys(replaced).forEach(function(collection) {
+  try {
+    db[collection].dropIndex(replaced[collection][0]);
+  } catch (e) {
+    // Already dropped
+  }
+  db[collection].createIndex(replaced[collection][1]);
+});
+db.workers.createIndex({ project_id: 1, name: 1 });
+
+// Indexes of the new collections, as in init-mongo.js
+db.organization_members.createIndex({ organization_id: 1, created_at: 1 });
+db.organization_members.createIndex({ user_id: 1 });
+db.projects.createIndex({ organization_id: 1, name: 1 });
+db.project_members.createIndex({ project_id: 1, created_at: 1 });
+db.project_members.createIndex({ user_id: 1 });
+
+print('=== Migration 001 complete ===');
@@ -0,0 +1,26 @@
+// ==================================================
+// MIGRATION 002 - EMAIL VERIFIED
+// ==================================================
+// Users now have an email_verified flag. Accounts created before email
+// verification existed are treated as verified, so turning on
+// REQUIRE_EMAIL_VERIFICATION does not lock them out.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/002_email_verified.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 002: email verified ===');
+
+var result = db.users.updateMany(
+  { email_verified: { $exists: false } },
+  { $set: { email_verified: true } }
+);
+print('  users: ' + result.modifiedCount + ' document(s) updated');
+
+// Indexes, as in init-mongo.js
+db.refresh_tokens.createIndex({ user_id: 1 });
+db.email_tokens.createIndex({ user_id: 1, purpose: 1 });
+db.email_tokens.createIndex({ expires_at: 1 }, { expireAfterSeconds: 0 });
+
+print('=== Migration 002 complete ===');
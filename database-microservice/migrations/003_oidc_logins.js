@@ -0,0 +1,17 @@
+// ==================================================
+// MIGRATION 003 - OIDC LOGINS
+// ==================================================
+// Logins through OpenID Connect providers keep their state in oidc_logins
+// until the provider redirects back; abandoned ones expire.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/003_oidc_logins.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 003: OIDC logins ===');
+
+// Index, as in init-mongo.js
+db.oidc_logins.createIndex({ expires_at: 1 }, { expireAfterSeconds: 0 });
+
+print('=== Migration 003 complete ===');
@@ -0,0 +1,17 @@
+// ==================================================
+// MIGRATION 004 - LOGIN FAILURES
+// ==================================================
+// Failed logins are counted per client IP, email and user in login_failures
+// for brute-force protection; counters expire a while after the last failure.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/004_login_failures.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 004: login failures ===');
+
+// Index, as in init-mongo.js
+db.login_failures.createIndex({ expires_at: 1 }, { expireAfterSeconds: 0 });
+
+print('=== Migration 004 complete ===');
@@ -0,0 +1,20 @@
+// ==================================================
+// MIGRATION 005 - AUDIT EVENTS
+// ==================================================
+// Security-relevant and destructive actions are appended to audit_events;
+// admins query them by actor, action and time range.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/005_audit_events.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 005: audit events ===');
+
+// Indexes, as in init-mongo.js
+db.audit_events.createIndex({ time: -1 });
+db.audit_events.createIndex({ actor_id: 1, _id: -1 });
+db.audit_events.createIndex({ actor_email: 1, _id: -1 });
+db.audit_events.createIndex({ action: 1, _id: -1 });
+
+print('=== Migration 005 complete ===');
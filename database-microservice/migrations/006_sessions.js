@@ -0,0 +1,19 @@
+// ==================================================
+// MIGRATION 006 - SESSIONS
+// ==================================================
+// Every login session has a record in sessions with its login method, device
+// and where it was last seen. Sessions started before this migration get a
+// record on their next token refresh.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/006_sessions.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 006: sessions ===');
+
+// Indexes, as in init-mongo.js
+db.sessions.createIndex({ user_id: 1, created_at: -1 });
+db.sessions.createIndex({ expires_at: 1 }, { expireAfterSeconds: 0 });
+
+print('=== Migration 006 complete ===');
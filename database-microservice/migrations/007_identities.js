@@ -0,0 +1,22 @@
+// ==================================================
+// MIGRATION 007 - LINKED IDENTITIES
+// ==================================================
+// Users keep the login provider accounts linked to them in identities
+// (provider, subject, email, linked_at). Existing provider users need nothing
+// else: their account is linked on their next login, since their email is
+// verified.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/007_identities.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 007: linked identities ===');
+
+// Index, as in init-mongo.js
+db.users.createIndex(
+  { 'identities.provider': 1, 'identities.subject': 1 },
+  { unique: true, partialFilterExpression: { 'identities.subject': { $exists: true } } }
+);
+
+print('=== Migration 007 complete ===');
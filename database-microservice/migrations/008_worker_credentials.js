@@ -0,0 +1,25 @@
+// ==================================================
+// MIGRATION 008 - WORKER CREDENTIALS
+// ==================================================
+// Runners no longer call the backend with a user's token. An admin issues a
+// one-time registration token, which the runner exchanges for a credential
+// bound to its worker. Workers registered before this migration have no
+// credential: issue a registration token and register each runner again.
+//
+// Run once against an existing database:
+//   docker exec -i testops-mongo mongosh -u admin -p admin123 --authenticationDatabase admin testops < migrations/008_worker_credentials.js
+
+db = db.getSiblingDB('testops');
+
+print('=== Migration 008: worker credentials ===');
+
+// Indexes, as in init-mongo.js
+db.workers.createIndex(
+  { credential_hash: 1 },
+  { unique: true, partialFilterExpression: { credential_hash: { $exists: true } } }
+);
+db.worker_registration_tokens.createIndex({ token_hash: 1 }, { unique: true });
+db.worker_registration_tokens.createIndex({ project_id: 1, created_at: -1 });
+db.worker_registration_tokens.createIndex({ expires_at: 1 }, { expireAfterSeconds: 0 });
+
+print('=== Migration 008 complete ===');
@@ -15,6 +15,12 @@ class JobParser:
         """Parse job JSON string"""
         try:
             job = json.loads(job_data)
+            # Jobs from the backend queue are wrapped in an envelope:
+            # {"id": <job id>, "payload": {...}, "enqueued_at": ...}
+            if "payload" in job:
+                envelope = job
+                job = envelope["payload"]
+                job["job_id"] = envelope.get("id")
             return JobParser.validate(job)
         except json.JSONDecodeError as e:
             logger.error(f"Failed to parse job JSON: {e}")
@@ -23,7 +29,7 @@ class JobParser:
     @staticmethod
     def validate(job: Dict[str, Any]) -> Optional[Dict[str, Any]]:
         """Validate job structure"""
-        required_fields = ["test_id", "script"]
+        required_fields = ["run_id", "test_id", "script"]
         
         for field in required_fields:
             if field not in job:
@@ -50,6 +56,7 @@ class JobParser:
             "browser": job.get("browser", "chrome"),
             "headless": job.get("headless", False),
             "timeout": job.get("timeout", 300),
+            "run_id": job.get("run_id"),
             "test_id": job.get("test_id"),
             "user_id": job.get("user_id", "unknown")
         }
@@ -2,62 +2,117 @@
 """
 Result uploader to send test results back to the Go backend
 """
+import json
 import logging
 import requests
 import os
-from typing import Dict, Any, Optional
+from typing import Dict, Any, List, Optional
 
 logger = logging.getLogger(__name__)
 
 
 class ResultUploader:
-    def __init__(self, backend_url: str = "http://backend:8080"):
+    def __init__(self, backend_url: str = "http://backend:8080", worker_id: str = "", token: str = ""):
         self.backend_url = backend_url
-        self.upload_endpoint = f"{backend_url}/api/results"
+        self.worker_id = worker_id
+        # Worker credential (topw_...) from POST /api/workers/register
+        self.token = token
+    
+    def auth_headers(self) -> Dict[str, str]:
+        """Headers identifying this runner by its worker credential"""
+        if not self.token:
+            return {}
+        return {"Authorization": f"Bearer {self.token}"}
+    
+    def upload_endpoint(self, run_id: str) -> str:
+        """Result upload URL for a run"""
+        return f"{self.backend_url}/api/runs/{run_id}/results"
+    
+    def logs_endpoint(self, run_id: str) -> str:
+        """Log append URL for a run"""
+        return f"{self.backend_url}/api/runs/{run_id}/logs"
+    
+    def append_logs(self, run_id: str, lines: List[Dict[str, Any]]) -> bool:
+        """
+        Append log lines to a running run so they can be followed live
+        
+        Args:
+            run_id: Run ID
+            lines: Log lines, each {"message": ..., "level": ..., "timestamp": ...}
+        
+        Returns:
+            True if the lines were stored, False otherwise
+        """
+        headers = self.auth_headers()
+        
+        try:
+            response = requests.post(
+                self.logs_endpoint(run_id),
+                json={"lines": lines},
+                headers=headers,
+                timeout=30
+            )
+            if response.status_code == 201:
+                return True
+            logger.error(f"Failed to append logs: {response.status_code} - {response.text}")
+            return False
+        except Exception as e:
+            logger.error(f"Error appending logs: {e}")
+            return False
     
     def upload_result(
         self,
-        test_id: str,
+        run_id: str,
         status: str,
         video_path: Optional[str] = None,
         screenshot_path: Optional[str] = None,
         logs: str = "",
         duration: float = 0.0
     ) -> bool:
-        """Upload test result to backend"""
+        """Upload test result to backend
+        
+        The result JSON is sent as the first multipart part, followed by the
+        video and screenshot files, which the backend streams to storage.
+        """
         try:
             # Prepare result data
             result_data = {
-                "test_id": test_id,
                 "status": status,
                 "logs": logs,
                 "duration": duration
             }
             
-            files = {}
+            # The result part must come before any file
+            files = [("result", (None, json.dumps(result_data), "application/json"))]
+            handles = []
             
             # Add video file if exists
             if video_path and os.path.exists(video_path):
-                files['video'] = open(video_path, 'rb')
+                handles.append(open(video_path, 'rb'))
+                files.append(("video", (os.path.basename(video_path), handles[-1])))
             
             # Add screenshot if exists
             if screenshot_path and os.path.exists(screenshot_path):
-                files['screenshot'] = open(screenshot_path, 'rb')
+                handles.append(open(screenshot_path, 'rb'))
+                files.append(("screenshot", (os.path.basename(screenshot_path), handles[-1])))
             
-            # Send POST request to backend
-            response = requests.post(
-                self.upload_endpoint,
-                data=result_data,
-                files=files,
-                timeout=30
-            )
+            headers = self.auth_headers()
             
-            # Close file handles
-            for file in files.values():
-                file.close()
+            # Send POST request to backend
+            try:
+                response = requests.post(
+                    self.upload_endpoint(run_id),
+                    files=files,
+                    headers=headers,
+                    timeout=300
+                )
+            finally:
+                # Close file handles
+                for handle in handles:
+                    handle.close()
             
-            if response.status_code == 200:
-                logger.info(f"Successfully uploaded result for test {test_id}")
+            if response.status_code == 201:
+                logger.info(f"Successfully uploaded result for run {run_id}")
                 return True
             else:
                 logger.error(f"Failed to upload result: {response.status_code} - {response.text}")
@@ -69,13 +124,13 @@ class ResultUploader:
     
     def upload_failure(
         self,
-        test_id: str,
+        run_id: str,
         error_message: str,
         screenshot_path: Optional[str] = None
     ) -> bool:
         """Upload a failed test result"""
         return self.upload_result(
-            test_id=test_id,
+            run_id=run_id,
             status="failed",
             screenshot_path=screenshot_path,
             logs=error_message
@@ -83,15 +138,15 @@ class ResultUploader:
     
     def upload_success(
         self,
-        test_id: str,
+        run_id: str,
         video_path: Optional[str] = None,
         screenshot_path: Optional[str] = None,
         duration: float = 0.0
     ) -> bool:
         """Upload a successful test result"""
         return self.upload_result(
-            test_id=test_id,
-            status="success",
+            run_id=run_id,
+            status="passed",
             video_path=video_path,
             screenshot_path=screenshot_path,
             duration=duration